@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+// Log is the audit log of security relevant auth events.
+type Log interface {
+	Record(ctx context.Context, event entities.AuthEvent) error
+	Recent(ctx context.Context, userID string, limit int) ([]entities.AuthEvent, error)
+}
+
+// Request describes the HTTP request an event came from.
+type Request struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type requestKey struct{}
+
+// RequestKey is the context key of the Request. The server stores it with
+// c.Locals, which the request context returned by c.Context() exposes.
+var RequestKey = requestKey{}
+
+// WithRequest returns a context carrying the request details.
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, RequestKey, r)
+}
+
+// RequestFrom returns the request details stored in ctx, if any.
+func RequestFrom(ctx context.Context) Request {
+	if ctx == nil {
+		return Request{}
+	}
+	r, _ := ctx.Value(RequestKey).(Request)
+	return r
+}
+
+// withRequest fills the request details and outcome the caller left empty.
+func withRequest(ctx context.Context, event entities.AuthEvent) entities.AuthEvent {
+	r := RequestFrom(ctx)
+	if event.IPAddress == "" {
+		event.IPAddress = r.IPAddress
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = r.UserAgent
+	}
+	if event.RequestID == "" {
+		event.RequestID = r.RequestID
+	}
+	if event.Outcome == "" {
+		event.Outcome = entities.AuthEventOutcomeSuccess
+	}
+	return event
+}
+
+// Failed marks the event as failed with err, keeping details in front of the
+// error message. A nil err leaves the event unchanged.
+func Failed(event entities.AuthEvent, err error) entities.AuthEvent {
+	if err == nil {
+		return event
+	}
+	event.Outcome = entities.AuthEventOutcomeFailure
+	if event.Details == "" {
+		event.Details = err.Error()
+	} else {
+		event.Details += ": " + err.Error()
+	}
+	return event
+}
+
+type logHolder struct {
+	log Log
+}
+
+var defaultLog atomic.Pointer[logHolder]
+
+// SetDefault installs the log used by Record.
+func SetDefault(l Log) {
+	defaultLog.Store(&logHolder{log: l})
+}
+
+// Default returns the installed log, or nil before Setup ran.
+func Default() Log {
+	if h := defaultLog.Load(); h != nil {
+		return h.log
+	}
+	return nil
+}
+
+// Setup installs the MySQL audit log as the default.
+func Setup(db *gorm.DB) Log {
+	if db == nil {
+		SetDefault(nil)
+		return nil
+	}
+
+	l := NewMySQLLog(db)
+	SetDefault(l)
+	return l
+}
+
+// Record adds the event to the default log. Failures are only logged so a
+// broken audit store never blocks a sign-in; callers that must not proceed
+// without an audit entry use the Log directly.
+func Record(ctx context.Context, event entities.AuthEvent) {
+	l := Default()
+	if l == nil {
+		return
+	}
+	if err := l.Record(ctx, event); err != nil {
+		logger.Errorf("Failed to record %s event for user %s: %v", event.Action, event.UserID, err)
+	}
+}
@@ -0,0 +1,269 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	drivermysql "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+// chain builds n linked entries as they would be stored.
+func chain(n int) []models.AuditLog {
+	entries := make([]models.AuditLog, 0, n)
+	prevHash := GenesisHash
+	for i := 1; i <= n; i++ {
+		entry := models.AuditLog{
+			ID:           uint64(i),
+			EventID:      "event" + string(rune('0'+i)),
+			TargetUserID: "user123",
+			Action:       entities.AuthEventSignIn,
+			Outcome:      entities.AuthEventOutcomeSuccess,
+			ActorID:      "user123",
+			CreatedAt:    time.Date(2026, 1, 2, 3, 4, i, 123456000, time.UTC),
+			PrevHash:     prevHash,
+		}
+		entry.Hash = hashEntry(&entry)
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func entryRows(entries []models.AuditLog) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "event_id", "target_user_id", "action", "outcome", "actor_id", "details", "created_at", "prev_hash", "hash"})
+	for _, e := range entries {
+		rows.AddRow(e.ID, e.EventID, e.TargetUserID, e.Action, e.Outcome, e.ActorID, e.Details, e.CreatedAt, e.PrevHash, e.Hash)
+	}
+	return rows
+}
+
+func TestMySQLLog_Record(t *testing.T) {
+	request := Request{IPAddress: "10.0.0.1", UserAgent: "test-agent", RequestID: "req-1"}
+	event := entities.AuthEvent{
+		ID:        "event1",
+		UserID:    "user123",
+		Action:    entities.AuthEventPinLocked,
+		ActorID:   "user123",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC),
+	}
+
+	// hashFor is the hash Record must store for the event linked to prevHash
+	hashFor := func(prevHash string) string {
+		entry := toEntry(withRequest(WithRequest(context.Background(), request), event))
+		entry.PrevHash = prevHash
+		return hashEntry(entry)
+	}
+	last := chain(1)[0]
+
+	tests := []struct {
+		name        string
+		setupMock   func(sqlmock.Sqlmock)
+		expectError error
+	}{
+		{
+			name: "first entry links to the genesis hash",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT `hash` FROM `audit_logs` ORDER BY id DESC LIMIT \\?").
+					WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `audit_logs`").
+					WithArgs("event1", "user123", entities.AuthEventPinLocked, entities.AuthEventOutcomeSuccess, "user123", "",
+						"10.0.0.1", "test-agent", "req-1", "", sqlmock.AnyArg(), GenesisHash, hashFor(GenesisHash)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "retries when another writer extended the chain first",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT `hash` FROM `audit_logs`").
+					WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(GenesisHash))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `audit_logs`").
+					WillReturnError(&drivermysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+				mock.ExpectRollback()
+				mock.ExpectQuery("SELECT `hash` FROM `audit_logs`").
+					WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(last.Hash))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `audit_logs`").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), last.Hash, hashFor(last.Hash)).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "other database errors are not retried",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT `hash` FROM `audit_logs`").
+					WillReturnError(errors.New("connection refused"))
+			},
+			expectError: errors.New("failed to append audit log entry: connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+			tt.setupMock(mock)
+
+			ctx := WithRequest(context.Background(), request)
+			err := NewMySQLLog(gormDB).Record(ctx, event)
+
+			if tt.expectError != nil {
+				assert.EqualError(t, err, tt.expectError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// capturedArg matches any value and keeps it.
+type capturedArg struct {
+	value *driver.Value
+}
+
+func (a capturedArg) Match(v driver.Value) bool {
+	*a.value = v
+	return true
+}
+
+func TestMySQLLog_Record_LongRequestID(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	columns := []string{"event_id", "target_user_id", "action", "outcome", "actor_id", "actor_name",
+		"ip_address", "user_agent", "request_id", "details", "created_at", "prev_hash", "hash"}
+	inserted := make([]driver.Value, len(columns))
+	args := make([]driver.Value, len(columns))
+	for i := range inserted {
+		args[i] = capturedArg{value: &inserted[i]}
+	}
+
+	mock.ExpectQuery("SELECT `hash` FROM `audit_logs`").
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `audit_logs`").WithArgs(args...).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// the request ID comes from the client and is longer than its column
+	ctx := WithRequest(context.Background(), Request{IPAddress: "10.0.0.1", RequestID: strings.Repeat("r", 100)})
+	err := NewMySQLLog(gormDB).Record(ctx, entities.AuthEvent{
+		UserID:  "user123",
+		Action:  entities.AuthEventSignIn,
+		Outcome: entities.AuthEventOutcomeFailure,
+		ActorID: "user123",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("r", 64), inserted[8])
+
+	// the stored row verifies against its chain hash
+	mock.ExpectQuery("SELECT \\* FROM `audit_logs` ORDER BY `audit_logs`\\.`id` LIMIT \\?").
+		WillReturnRows(sqlmock.NewRows(append([]string{"id"}, columns...)).AddRow(append([]driver.Value{1}, inserted...)...))
+
+	result, err := Verify(gormDB, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Entries)
+	assert.Equal(t, inserted[12], result.Head)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerify(t *testing.T) {
+	tampered := chain(3)
+	tampered[1].Details = "edited"
+
+	removed := chain(3)
+	removed = append(removed[:1], removed[2])
+
+	tests := []struct {
+		name          string
+		entries       []models.AuditLog
+		expectEntries int64
+		expectBroken  uint64
+	}{
+		{name: "intact chain", entries: chain(3), expectEntries: 3},
+		{name: "edited entry", entries: tampered, expectEntries: 1, expectBroken: 2},
+		{name: "removed entry", entries: removed, expectEntries: 1, expectBroken: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectQuery("SELECT \\* FROM `audit_logs` ORDER BY `audit_logs`\\.`id` LIMIT \\?").
+				WillReturnRows(entryRows(tt.entries))
+
+			result, err := Verify(gormDB, 100)
+
+			assert.Equal(t, tt.expectEntries, result.Entries)
+			if tt.expectBroken == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.entries[len(tt.entries)-1].Hash, result.Head)
+			} else {
+				var chainErr *ChainError
+				assert.ErrorAs(t, err, &chainErr)
+				assert.Equal(t, tt.expectBroken, chainErr.ID)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	entries := chain(2)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mock.ExpectQuery("SELECT \\* FROM `audit_logs` WHERE created_at >= \\? AND created_at < \\? ORDER BY `audit_logs`\\.`id` LIMIT \\?").
+		WithArgs(from, to, 100).
+		WillReturnRows(entryRows(entries))
+
+	var out bytes.Buffer
+	count, err := Export(gormDB, from, to, 100, &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	scanner := bufio.NewScanner(&out)
+	for i := 0; scanner.Scan(); i++ {
+		var line ExportedEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		assert.Equal(t, entries[i].ID, line.Seq)
+		assert.Equal(t, entries[i].EventID, line.ID)
+		assert.Equal(t, entries[i].PrevHash, line.PrevHash)
+		assert.Equal(t, entries[i].Hash, line.Hash)
+	}
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"gorm.io/gorm"
+)
+
+// GenesisHash is the PrevHash of the first entry.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ChainError points at the first entry that does not continue the chain.
+type ChainError struct {
+	ID     uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log chain broken at entry %d: %s", e.ID, e.Reason)
+}
+
+// VerifyResult summarises an intact chain. Keep Head outside the database to
+// also detect entries removed from the end.
+type VerifyResult struct {
+	Entries int64
+	Head    string
+}
+
+// hashEntry returns the hex SHA-256 of the entry's columns and PrevHash.
+// The columns are encoded as a JSON array so no value can run into the next.
+func hashEntry(entry *models.AuditLog) string {
+	fields, _ := json.Marshal([]string{
+		entry.PrevHash,
+		entry.EventID,
+		entry.TargetUserID,
+		entry.Action,
+		entry.Outcome,
+		entry.ActorID,
+		entry.ActorName,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.Details,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkEntry returns a ChainError unless entry follows prevHash and its hash
+// matches its columns.
+func checkEntry(entry *models.AuditLog, prevHash string) error {
+	if entry.PrevHash != prevHash {
+		return &ChainError{ID: entry.ID, Reason: "previous hash does not match, an entry before it was changed or removed"}
+	}
+	if hashEntry(entry) != entry.Hash {
+		return &ChainError{ID: entry.ID, Reason: "hash does not match its contents"}
+	}
+	return nil
+}
+
+// Verify walks the whole log in insertion order, batchSize entries at a time,
+// and recomputes every hash. A broken chain is reported as a *ChainError.
+func Verify(db *gorm.DB, batchSize int) (*VerifyResult, error) {
+	result := &VerifyResult{Head: GenesisHash}
+	var chainErr error
+
+	var batch []models.AuditLog
+	err := db.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := checkEntry(&batch[i], result.Head); err != nil {
+				chainErr = err
+				return err
+			}
+			result.Head = batch[i].Hash
+			result.Entries++
+		}
+		return nil
+	}).Error
+	if chainErr != nil {
+		return result, chainErr
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	drivermysql "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxRecent bounds how many events Recent returns
+	MaxRecent = 100
+	// maxAppendAttempts bounds the retries when another writer extended the
+	// chain between reading the last hash and inserting
+	maxAppendAttempts = 5
+	// mysqlDuplicateEntry is the MySQL error number for a unique key violation
+	mysqlDuplicateEntry = 1062
+)
+
+var ErrChainContention = errors.New("audit log: too many concurrent writers")
+
+type mysqlLog struct {
+	db *gorm.DB
+}
+
+// NewMySQLLog appends events to the hash chained audit_logs table.
+func NewMySQLLog(db *gorm.DB) Log {
+	return &mysqlLog{db: db}
+}
+
+func (l *mysqlLog) Record(ctx context.Context, event entities.AuthEvent) error {
+	event = withRequest(ctx, event)
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		err := l.append(toEntry(event))
+		if err == nil {
+			return nil
+		}
+		if !isDuplicateEntry(err) {
+			return fmt.Errorf("failed to append audit log entry: %w", err)
+		}
+	}
+	return ErrChainContention
+}
+
+// append links the entry to the newest one. The unique prev_hash index makes
+// the insert fail when another writer linked to the same entry first.
+func (l *mysqlLog) append(entry *models.AuditLog) error {
+	var last models.AuditLog
+	err := l.db.Select("hash").Order("id DESC").Take(&last).Error
+	switch {
+	case err == nil:
+		entry.PrevHash = last.Hash
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		entry.PrevHash = GenesisHash
+	default:
+		return err
+	}
+
+	entry.Hash = hashEntry(entry)
+	return l.db.Create(entry).Error
+}
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *drivermysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
+
+// Recent returns up to limit events about the user, newest first.
+func (l *mysqlLog) Recent(ctx context.Context, userID string, limit int) ([]entities.AuthEvent, error) {
+	if limit <= 0 || limit > MaxRecent {
+		limit = MaxRecent
+	}
+
+	var entries []models.AuditLog
+	if err := l.db.Where("target_user_id = ?", userID).Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	events := make([]entities.AuthEvent, 0, len(entries))
+	for i := range entries {
+		events = append(events, toEvent(&entries[i]))
+	}
+	return events, nil
+}
+
+// ExportedEntry is one line of Export. Seq, PrevHash and Hash let the
+// recipient check the chain within the exported range.
+type ExportedEntry struct {
+	Seq uint64 `json:"seq"`
+	entities.AuthEvent
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// Export writes the entries created in [from, to) to w as JSON lines, in
+// insertion order, and returns how many it wrote.
+func Export(db *gorm.DB, from, to time.Time, batchSize int, w io.Writer) (int64, error) {
+	encoder := json.NewEncoder(w)
+	var count int64
+
+	var batch []models.AuditLog
+	err := db.Where("created_at >= ? AND created_at < ?", from, to).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				line := ExportedEntry{
+					Seq:       batch[i].ID,
+					AuthEvent: toEvent(&batch[i]),
+					PrevHash:  batch[i].PrevHash,
+					Hash:      batch[i].Hash,
+				}
+				if err := encoder.Encode(line); err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return count, fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return count, nil
+}
+
+// toEntry maps an event to a row. Strings are cut to their column sizes, a
+// strict MySQL would otherwise reject the whole entry, and CreatedAt to the
+// microseconds MySQL keeps, otherwise the stored row would hash differently.
+func toEntry(event entities.AuthEvent) *models.AuditLog {
+	return &models.AuditLog{
+		EventID:      truncate(event.ID, 36),
+		TargetUserID: truncate(event.UserID, 50),
+		Action:       truncate(event.Action, 50),
+		Outcome:      truncate(event.Outcome, 20),
+		ActorID:      truncate(event.ActorID, 50),
+		ActorName:    truncate(event.ActorName, 100),
+		IPAddress:    truncate(event.IPAddress, 45),
+		UserAgent:    truncate(event.UserAgent, 255),
+		RequestID:    truncate(event.RequestID, 64),
+		Details:      truncate(event.Details, 255),
+		CreatedAt:    event.CreatedAt.UTC().Truncate(time.Microsecond),
+	}
+}
+
+func toEvent(entry *models.AuditLog) entities.AuthEvent {
+	return entities.AuthEvent{
+		ID:        entry.EventID,
+		UserID:    entry.TargetUserID,
+		Action:    entry.Action,
+		Outcome:   entry.Outcome,
+		ActorID:   entry.ActorID,
+		ActorName: entry.ActorName,
+		IPAddress: entry.IPAddress,
+		UserAgent: entry.UserAgent,
+		RequestID: entry.RequestID,
+		Details:   entry.Details,
+		CreatedAt: entry.CreatedAt.UTC(),
+	}
+}
+
+// truncate cuts value to the size in characters of its varchar column.
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+	return string(runes[:size])
+}
@@ -0,0 +1,106 @@
+package encryption
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is used when Encryption.BatchSize is not set.
+const DefaultBatchSize = 500
+
+// Column names an encrypted column, its blind index column and the primary
+// key used to walk the table in batches. IndexName is empty for columns that
+// are never searched.
+type Column struct {
+	Table      string
+	PrimaryKey string
+	Name       string
+	IndexName  string
+}
+
+type columnRow struct {
+	Key   string
+	Value *string
+}
+
+// EncryptColumn re-encrypts every value of the column that is plaintext or
+// sealed with an older data key, batchSize rows per transaction, and fills
+// its blind index. It can be stopped and rerun at any point. It returns the
+// number of rows it changed.
+func EncryptColumn(db *gorm.DB, keyring *Keyring, column Column, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	updated := 0
+	lastKey := ""
+	for {
+		var rows []columnRow
+		err := db.Table(column.Table).
+			Select(fmt.Sprintf("%s AS `key`, %s AS `value`", column.PrimaryKey, column.Name)).
+			Where(column.PrimaryKey+" > ?", lastKey).
+			Order(column.PrimaryKey).
+			Limit(batchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return updated, fmt.Errorf("failed to read %s.%s: %w", column.Table, column.Name, err)
+		}
+		if len(rows) == 0 {
+			return updated, nil
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				changed, err := encryptRow(tx, keyring, column, row)
+				if err != nil {
+					return err
+				}
+				if changed {
+					updated++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return updated, fmt.Errorf("failed to encrypt %s.%s: %w", column.Table, column.Name, err)
+		}
+
+		lastKey = rows[len(rows)-1].Key
+		if len(rows) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+func encryptRow(tx *gorm.DB, keyring *Keyring, column Column, row columnRow) (bool, error) {
+	if row.Value == nil || *row.Value == "" {
+		return false, nil
+	}
+	if keyID, ok := KeyIDOf(*row.Value); ok && keyID == keyring.ActiveKeyID() {
+		return false, nil
+	}
+
+	plaintext, err := keyring.Decrypt(*row.Value)
+	if err != nil {
+		return false, fmt.Errorf("row %s: %w", row.Key, err)
+	}
+	ciphertext, err := keyring.Encrypt(plaintext)
+	if err != nil {
+		return false, err
+	}
+
+	updates := map[string]interface{}{column.Name: ciphertext}
+	if column.IndexName != "" {
+		updates[column.IndexName] = keyring.BlindIndex(plaintext)
+	}
+
+	// the old value guards against overwriting a concurrent update
+	result := tx.Table(column.Table).
+		Where(column.PrimaryKey+" = ? AND "+column.Name+" = ?", row.Key, *row.Value).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,139 @@
+package encryption
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type testCardDetail struct {
+	CardID string `gorm:"column:card_id;primaryKey"`
+	Number string `gorm:"column:number;serializer:encrypted"`
+}
+
+func (testCardDetail) TableName() string {
+	return "debit_card_details"
+}
+
+// sealedArg matches a value sealed by keyring for plaintext.
+type sealedArg struct {
+	keyring   *Keyring
+	plaintext string
+}
+
+func (a sealedArg) Match(v driver.Value) bool {
+	value, ok := v.(string)
+	if !ok || !IsEncrypted(value) {
+		return false
+	}
+	plaintext, err := a.keyring.Decrypt(value)
+	return err == nil && plaintext == a.plaintext
+}
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestSerializer(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	keyring := newTestKeyring(t)
+	SetDefault(keyring)
+	defer SetDefault(nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `debit_card_details`").
+		WithArgs("card1", sealedArg{keyring, "4000 1234 5678 9010"}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, gormDB.Create(&testCardDetail{CardID: "card1", Number: "4000 1234 5678 9010"}).Error)
+
+	ciphertext, err := keyring.Encrypt("4000 1234 5678 9010")
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_details`").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "number"}).
+			AddRow("card1", ciphertext).
+			AddRow("card2", "4000 0000 0000 0002").
+			AddRow("card3", nil))
+
+	var details []testCardDetail
+	assert.NoError(t, gormDB.Find(&details).Error)
+	assert.Equal(t, []testCardDetail{
+		{CardID: "card1", Number: "4000 1234 5678 9010"},
+		{CardID: "card2", Number: "4000 0000 0000 0002"},
+		{CardID: "card3", Number: ""},
+	}, details)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSerializer_WithoutKeyring(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	SetDefault(nil)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := gormDB.Create(&testCardDetail{CardID: "card1", Number: "4000 1234 5678 9010"}).Error
+	assert.ErrorIs(t, err, ErrNoKeyring)
+
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_details`").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "number"}).AddRow("card1", "enc:v1:dk1:AAAA"))
+	var detail testCardDetail
+	assert.ErrorIs(t, gormDB.Find(&detail).Error, ErrNoKeyring)
+}
+
+func TestEncryptColumn(t *testing.T) {
+	gormDB, mock := setupMockDB(t)
+	keyring := newTestKeyring(t)
+	column := Column{Table: "debit_card_details", PrimaryKey: "card_id", Name: "number", IndexName: "number_index"}
+
+	current, err := keyring.Encrypt("4000 0000 0000 0002")
+	require.NoError(t, err)
+	// a value left under the previous data key
+	oldKey := bytes.Repeat([]byte{9}, KeySize)
+	old, err := NewKeyring("dk0", map[string][]byte{"dk0": oldKey}, testIndexKey)
+	require.NoError(t, err)
+	stale, err := old.Encrypt("4000 0000 0000 0003")
+	require.NoError(t, err)
+	keyring.keys["dk0"] = oldKey
+
+	mock.ExpectQuery("SELECT card_id AS `key`, number AS `value` FROM `debit_card_details` WHERE card_id > \\? ORDER BY card_id LIMIT \\?").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("card1", "4000 0000 0000 0001").
+			AddRow("card2", current))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `debit_card_details` SET `number`=\\?,`number_index`=\\? WHERE card_id = \\? AND number = \\?").
+		WithArgs(sealedArg{keyring, "4000 0000 0000 0001"}, keyring.BlindIndex("4000000000000001"), "card1", "4000 0000 0000 0001").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery("SELECT card_id AS `key`, number AS `value` FROM `debit_card_details` WHERE card_id > \\? ORDER BY card_id LIMIT \\?").
+		WithArgs("card2", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("card3", stale))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `debit_card_details` SET `number`=\\?,`number_index`=\\? WHERE card_id = \\? AND number = \\?").
+		WithArgs(sealedArg{keyring, "4000 0000 0000 0003"}, keyring.BlindIndex("4000000000000003"), "card3", stale).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := EncryptColumn(gormDB, keyring, column, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
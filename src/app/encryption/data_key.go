@@ -0,0 +1,177 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataKey is a data key wrapped by the KMS. Only one key is active; the others
+// are kept to open values that have not been re-encrypted yet.
+type DataKey struct {
+	KeyID       string    `gorm:"column:key_id;primaryKey;size:36"`
+	MasterKeyID string    `gorm:"column:master_key_id;size:64;not null"`
+	WrappedKey  []byte    `gorm:"column:wrapped_key;type:varbinary(128);not null"`
+	Active      bool      `gorm:"column:active;not null;default:false;index"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null"`
+}
+
+func (DataKey) TableName() string {
+	return "data_keys"
+}
+
+// Setup reads the key file from the config, loads the active data key and
+// installs the keyring as the default.
+func Setup(db *gorm.DB, cfg *config.EncryptionConfig, environment string) (*Keyring, error) {
+	kms, indexKey, err := OpenKeyFile(cfg, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring, err := LoadKeyring(db, kms, indexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	SetDefault(keyring)
+	return keyring, nil
+}
+
+// OpenKeyFile builds the file-based KMS and blind index key from the config.
+// Outside the development environment a development key file is refused.
+func OpenKeyFile(cfg *config.EncryptionConfig, environment string) (KMS, []byte, error) {
+	if cfg == nil || cfg.KeyFile == "" {
+		return nil, nil, errors.New("encryption: Encryption.KeyFile is not configured")
+	}
+
+	keyFile, err := LoadKeyFile(cfg.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if environment != "development" && keyFile.IsDev() {
+		return nil, nil, fmt.Errorf("%w: %s in %s", ErrDevKeyFile, cfg.KeyFile, environment)
+	}
+
+	kms, err := NewFileKMS(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indexKey, err := keyFile.DecodeIndexKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("index key: %w", err)
+	}
+	return kms, indexKey, nil
+}
+
+// LoadKeyring unwraps the active data key, creating the first one on a fresh
+// database. Older keys are unwrapped on first use.
+func LoadKeyring(db *gorm.DB, kms KMS, indexKey []byte) (*Keyring, error) {
+	// the keyring is needed by the migrations, so it manages its own table
+	// the way the migrations table does
+	if err := db.AutoMigrate(&DataKey{}); err != nil {
+		return nil, fmt.Errorf("encryption: failed to create data_keys table: %w", err)
+	}
+
+	active, err := activeDataKey(db)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		active, err = RotateDataKey(db, kms)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := kms.Unwrap(active.MasterKeyID, active.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to unwrap data key %s: %w", active.KeyID, err)
+	}
+
+	keyring, err := NewKeyring(active.KeyID, map[string][]byte{active.KeyID: key}, indexKey)
+	if err != nil {
+		return nil, err
+	}
+	keyring.loader = func(keyID string) ([]byte, error) {
+		var dataKey DataKey
+		if err := db.Where("key_id = ?", keyID).First(&dataKey).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownDataKey, keyID)
+			}
+			return nil, err
+		}
+		return kms.Unwrap(dataKey.MasterKeyID, dataKey.WrappedKey)
+	}
+	return keyring, nil
+}
+
+func activeDataKey(db *gorm.DB) (*DataKey, error) {
+	var dataKey DataKey
+	if err := db.Where("active = ?", true).Order("created_at DESC").First(&dataKey).Error; err != nil {
+		return nil, err
+	}
+	return &dataKey, nil
+}
+
+// RotateDataKey generates a data key, wraps it with the active master key and
+// makes it the only active key.
+func RotateDataKey(db *gorm.DB, kms KMS) (*DataKey, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	wrapped, masterKeyID, err := kms.Wrap(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to wrap data key: %w", err)
+	}
+
+	dataKey := &DataKey{
+		KeyID:       uuid.New().String(),
+		MasterKeyID: masterKeyID,
+		WrappedKey:  wrapped,
+		Active:      true,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&DataKey{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(dataKey).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to store data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// RewrapDataKeys re-wraps every data key that is not wrapped with the active
+// master key, so an old master key can be removed from the key file.
+func RewrapDataKeys(db *gorm.DB, kms KMS) (int, error) {
+	var dataKeys []DataKey
+	if err := db.Where("master_key_id <> ?", kms.ActiveKeyID()).Find(&dataKeys).Error; err != nil {
+		return 0, err
+	}
+
+	for i, dataKey := range dataKeys {
+		key, err := kms.Unwrap(dataKey.MasterKeyID, dataKey.WrappedKey)
+		if err != nil {
+			return i, fmt.Errorf("encryption: failed to unwrap data key %s: %w", dataKey.KeyID, err)
+		}
+		wrapped, masterKeyID, err := kms.Wrap(key)
+		if err != nil {
+			return i, fmt.Errorf("encryption: failed to wrap data key %s: %w", dataKey.KeyID, err)
+		}
+		if err := db.Model(&DataKey{}).Where("key_id = ?", dataKey.KeyID).Updates(map[string]interface{}{
+			"master_key_id": masterKeyID,
+			"wrapped_key":   wrapped,
+		}).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(dataKeys), nil
+}
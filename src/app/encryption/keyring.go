@@ -0,0 +1,235 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// KeySize is the length of every master, data and index key (AES-256).
+const KeySize = 32
+
+// ciphertextPrefix marks encrypted column values, so rows that have not been
+// migrated yet are still read as plaintext.
+const ciphertextPrefix = "enc:v1:"
+
+var (
+	ErrNoKeyring           = errors.New("encryption: keyring is not set up")
+	ErrInvalidKey          = errors.New("encryption: keys must be 32 bytes")
+	ErrUnknownDataKey      = errors.New("encryption: unknown data key")
+	ErrMalformedCiphertext = errors.New("encryption: malformed ciphertext")
+)
+
+// Keyring holds the unwrapped data keys. New values are sealed with the active
+// key; values sealed with an older key are still opened, loading that key on
+// first use.
+type Keyring struct {
+	mu       sync.RWMutex
+	activeID string
+	keys     map[string][]byte
+	indexKey []byte
+	loader   func(keyID string) ([]byte, error)
+}
+
+// NewKeyring builds a keyring from plaintext data keys. activeID must be one of
+// keys.
+func NewKeyring(activeID string, keys map[string][]byte, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDataKey, activeID)
+	}
+	for _, key := range keys {
+		if len(key) != KeySize {
+			return nil, ErrInvalidKey
+		}
+	}
+	if len(indexKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	copied := make(map[string][]byte, len(keys))
+	for id, key := range keys {
+		copied[id] = key
+	}
+	return &Keyring{activeID: activeID, keys: copied, indexKey: indexKey}, nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Encrypt seals plaintext with the active data key as
+// "enc:v1:<keyID>:<base64(nonce|ciphertext)>".
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	key, err := k.dataKey(k.activeID)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(key, []byte(plaintext), []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+	return ciphertextPrefix + k.activeID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value written by Encrypt. Values without the ciphertext
+// prefix are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	keyID, payload, ok := splitCiphertext(value)
+	if !ok {
+		if IsEncrypted(value) {
+			return "", ErrMalformedCiphertext
+		}
+		return value, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrMalformedCiphertext
+	}
+
+	key, err := k.dataKey(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(key, sealed, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex is a keyed hash of the value's digits, stored next to the
+// ciphertext so rows can still be looked up by number. It does not depend on
+// the data key, so rotation leaves it unchanged.
+func (k *Keyring) BlindIndex(value string) string {
+	normalized := normalize(value)
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (k *Keyring) dataKey(keyID string) ([]byte, error) {
+	k.mu.RLock()
+	key, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if k.loader == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDataKey, keyID)
+	}
+
+	key, err := k.loader(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.keys[keyID] = key
+	k.mu.Unlock()
+	return key, nil
+}
+
+// IsEncrypted reports whether value was written by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+// KeyIDOf returns the data key a value was encrypted with.
+func KeyIDOf(value string) (string, bool) {
+	keyID, _, ok := splitCiphertext(value)
+	return keyID, ok
+}
+
+func splitCiphertext(value string) (keyID, payload string, ok bool) {
+	if !IsEncrypted(value) {
+		return "", "", false
+	}
+	keyID, payload, ok = strings.Cut(strings.TrimPrefix(value, ciphertextPrefix), ":")
+	if !ok || keyID == "" || payload == "" {
+		return "", "", false
+	}
+	return keyID, payload, true
+}
+
+// normalize drops separators so "4000 1234 5678 9010" and "4000123456789010"
+// share one blind index.
+func normalize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault installs the keyring used by the GORM serializer and BlindIndex.
+func SetDefault(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// Default returns the installed keyring, or nil before Setup has run.
+func Default() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// BlindIndex hashes value with the default keyring.
+func BlindIndex(value string) (string, error) {
+	k := Default()
+	if k == nil {
+		return "", ErrNoKeyring
+	}
+	return k.BlindIndex(value), nil
+}
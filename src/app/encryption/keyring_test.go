@@ -0,0 +1,174 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDataKey  = bytes.Repeat([]byte{1}, KeySize)
+	testIndexKey = bytes.Repeat([]byte{2}, KeySize)
+)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	keyring, err := NewKeyring("dk1", map[string][]byte{"dk1": testDataKey}, testIndexKey)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring(t)
+
+	ciphertext, err := keyring.Encrypt("4000 1234 5678 9010")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:v1:dk1:"))
+	assert.NotContains(t, ciphertext, "9010")
+	keyID, ok := KeyIDOf(ciphertext)
+	assert.True(t, ok)
+	assert.Equal(t, "dk1", keyID)
+
+	again, err := keyring.Encrypt("4000 1234 5678 9010")
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "every value gets a fresh nonce")
+
+	plaintext, err := keyring.Decrypt(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "4000 1234 5678 9010", plaintext)
+}
+
+func TestKeyring_Decrypt(t *testing.T) {
+	keyring := newTestKeyring(t)
+	ciphertext, err := keyring.Encrypt("123456789012")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		value     string
+		expected  string
+		expectErr error
+	}{
+		{name: "plaintext passes through", value: "123456789012", expected: "123456789012"},
+		{name: "tampered ciphertext", value: ciphertext[:len(ciphertext)-2] + "AA", expectErr: ErrMalformedCiphertext},
+		{name: "missing payload", value: "enc:v1:dk1:", expectErr: ErrMalformedCiphertext},
+		{name: "unknown data key", value: strings.Replace(ciphertext, "dk1", "dk9", 1), expectErr: ErrUnknownDataKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := keyring.Decrypt(tt.value)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, plaintext)
+		})
+	}
+}
+
+func TestKeyring_LoadsOlderKeys(t *testing.T) {
+	old, err := NewKeyring("dk0", map[string][]byte{"dk0": bytes.Repeat([]byte{9}, KeySize)}, testIndexKey)
+	require.NoError(t, err)
+	ciphertext, err := old.Encrypt("123456789012")
+	require.NoError(t, err)
+
+	keyring := newTestKeyring(t)
+	loads := 0
+	keyring.loader = func(keyID string) ([]byte, error) {
+		loads++
+		if keyID != "dk0" {
+			return nil, errors.New("not found")
+		}
+		return bytes.Repeat([]byte{9}, KeySize), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		plaintext, err := keyring.Decrypt(ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", plaintext)
+	}
+	assert.Equal(t, 1, loads, "loaded keys are cached")
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	keyring := newTestKeyring(t)
+
+	index := keyring.BlindIndex("4000 1234 5678 9010")
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, keyring.BlindIndex("4000-1234-5678-9010"))
+	assert.Equal(t, index, keyring.BlindIndex("4000123456789010"))
+	assert.NotEqual(t, index, keyring.BlindIndex("4000123456789011"))
+	assert.Empty(t, keyring.BlindIndex(""))
+
+	other, err := NewKeyring("dk1", map[string][]byte{"dk1": testDataKey}, bytes.Repeat([]byte{3}, KeySize))
+	require.NoError(t, err)
+	assert.NotEqual(t, index, other.BlindIndex("4000123456789010"))
+}
+
+func TestNewKeyring_Validation(t *testing.T) {
+	_, err := NewKeyring("dk1", map[string][]byte{"dk2": testDataKey}, testIndexKey)
+	assert.ErrorIs(t, err, ErrUnknownDataKey)
+
+	_, err = NewKeyring("dk1", map[string][]byte{"dk1": []byte("short")}, testIndexKey)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewKeyring("dk1", map[string][]byte{"dk1": testDataKey}, nil)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestFileKMS(t *testing.T) {
+	kms, err := NewFileKMS(&KeyFile{
+		ActiveMasterKey: "m2",
+		MasterKeys: map[string]string{
+			"m1": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=",
+			"m2": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=",
+		},
+	})
+	require.NoError(t, err)
+
+	wrapped, masterKeyID, err := kms.Wrap(testDataKey)
+	require.NoError(t, err)
+	assert.Equal(t, "m2", masterKeyID)
+	assert.NotContains(t, string(wrapped), string(testDataKey))
+
+	unwrapped, err := kms.Unwrap("m2", wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, testDataKey, unwrapped)
+
+	_, err = kms.Unwrap("m1", wrapped)
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	_, err = kms.Unwrap("m3", wrapped)
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+
+	_, err = NewFileKMS(&KeyFile{ActiveMasterKey: "m3", MasterKeys: map[string]string{}})
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+}
+
+func TestOpenKeyFile_DevKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encryption.dev.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"activeMasterKey": "dev-master-1",
+		"masterKeys": {"dev-master-1": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="},
+		"indexKey": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="
+	}`), 0o600))
+	cfg := &config.EncryptionConfig{KeyFile: path}
+
+	kms, indexKey, err := OpenKeyFile(cfg, "development")
+	require.NoError(t, err)
+	assert.Equal(t, "dev-master-1", kms.ActiveKeyID())
+	assert.Len(t, indexKey, KeySize)
+
+	// the development keys are public, so they must not protect real data
+	_, _, err = OpenKeyFile(cfg, "production")
+	assert.ErrorIs(t, err, ErrDevKeyFile)
+}
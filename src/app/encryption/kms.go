@@ -0,0 +1,122 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnknownMasterKey = errors.New("encryption: unknown master key")
+	ErrDevKeyFile       = errors.New("encryption: development key file used outside development")
+)
+
+// DevKeyPrefix marks master keys generated for local development, like the
+// ones in keys/encryption.dev.json.
+const DevKeyPrefix = "dev-"
+
+// KMS wraps data keys with a master key that never leaves it. FileKMS is the
+// local stand-in; a cloud KMS client can implement the same interface.
+type KMS interface {
+	// ActiveKeyID names the master key new data keys are wrapped with.
+	ActiveKeyID() string
+	Wrap(dataKey []byte) (wrapped []byte, masterKeyID string, err error)
+	Unwrap(masterKeyID string, wrapped []byte) ([]byte, error)
+}
+
+// KeyFile is the JSON key file read by FileKMS. Keys are base64 encoded.
+//
+//	{
+//	  "activeMasterKey": "master-2025-08",
+//	  "masterKeys": {"master-2025-08": "..."},
+//	  "indexKey": "..."
+//	}
+//
+// Old master keys must stay in the file until rotate_data_key has rewrapped
+// every data key with the active one.
+type KeyFile struct {
+	ActiveMasterKey string            `json:"activeMasterKey"`
+	MasterKeys      map[string]string `json:"masterKeys"`
+	IndexKey        string            `json:"indexKey"`
+}
+
+// LoadKeyFile reads and decodes the key file at path.
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to read key file: %w", err)
+	}
+
+	var keyFile KeyFile
+	if err := json.Unmarshal(data, &keyFile); err != nil {
+		return nil, fmt.Errorf("encryption: failed to parse key file: %w", err)
+	}
+	return &keyFile, nil
+}
+
+// IsDev reports whether the file is a development key file, whose keys are
+// public and must not protect real data.
+func (f *KeyFile) IsDev() bool {
+	return strings.HasPrefix(f.ActiveMasterKey, DevKeyPrefix)
+}
+
+// DecodeIndexKey returns the blind index key.
+func (f *KeyFile) DecodeIndexKey() ([]byte, error) {
+	return decodeKey(f.IndexKey)
+}
+
+// FileKMS wraps data keys with AES-256-GCM under master keys from a KeyFile.
+type FileKMS struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+func NewFileKMS(keyFile *KeyFile) (*FileKMS, error) {
+	keys := make(map[string][]byte, len(keyFile.MasterKeys))
+	for id, encoded := range keyFile.MasterKeys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %s: %w", id, err)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[keyFile.ActiveMasterKey]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, keyFile.ActiveMasterKey)
+	}
+
+	return &FileKMS{activeID: keyFile.ActiveMasterKey, keys: keys}, nil
+}
+
+func (k *FileKMS) ActiveKeyID() string {
+	return k.activeID
+}
+
+func (k *FileKMS) Wrap(dataKey []byte) ([]byte, string, error) {
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return nil, "", err
+	}
+	return wrapped, k.activeID, nil
+}
+
+func (k *FileKMS) Unwrap(masterKeyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[masterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, masterKeyID)
+	}
+	return open(key, wrapped, []byte(masterKeyID))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: invalid base64 key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
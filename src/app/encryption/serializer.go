@@ -0,0 +1,66 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer tag for encrypted string columns:
+//
+//	Number string `gorm:"column:number;serializer:encrypted"`
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer encrypts a string field on write and decrypts it on read with
+// the default keyring. Plaintext left by rows that are not migrated yet is
+// read as is.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return fmt.Errorf("encryption: cannot scan %T into %s", dbValue, field.Name)
+	}
+
+	if IsEncrypted(value) {
+		keyring := Default()
+		if keyring == nil {
+			return ErrNoKeyring
+		}
+		plaintext, err := keyring.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("encryption: failed to decrypt %s: %w", field.Name, err)
+		}
+		value = plaintext
+	}
+
+	return field.Set(ctx, dst, value)
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encryption: %s must be a string, got %T", field.Name, fieldValue)
+	}
+	if value == "" {
+		return "", nil
+	}
+
+	keyring := Default()
+	if keyring == nil {
+		return nil, ErrNoKeyring
+	}
+	return keyring.Encrypt(value)
+}
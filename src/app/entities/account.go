@@ -1,13 +1,18 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/validators"
+)
 
 type Account struct {
 	AccountID      string         `json:"accountID"`
-	Amount         float64        `json:"amount"`
+	Amount         money.Money    `json:"amount"`
 	Type           string         `json:"type"`
 	Currency       string         `json:"currency"`
-	AccountNumber  string         `json:"accountNumber"`
+	AccountNumber  string         `json:"accountNumber"` // masked to the last 4 digits
 	Issuer         string         `json:"issuer"`
 	AccountDetails AccountDetails `json:"accountDetails"`
 	AccountFlags   []AccountFlags `json:"accountFlags"`
@@ -20,8 +25,26 @@ type AccountDetails struct {
 }
 
 type AccountFlags struct {
+	FlagID    int       `json:"flagID"`
 	FlagType  string    `json:"flagType"`
 	FlagValue string    `json:"flagValue"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// RevealAccountRequest carries the PIN re-checked before an account number is
+// shown in full.
+type RevealAccountRequest struct {
+	Pin string `json:"pin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (r *RevealAccountRequest) Validate() error {
+	return validators.ValidateStruct(r)
+}
+
+// RevealedAccount is the only account payload with the full account number.
+type RevealedAccount struct {
+	AccountID     string `json:"accountID"`
+	AccountNumber string `json:"accountNumber"`
+	Issuer        string `json:"issuer"`
+}
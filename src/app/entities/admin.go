@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/validators"
+)
+
+// AdminActionParams identifies the operator behind an admin request and the
+// user it acts on.
+type AdminActionParams struct {
+	Operator Claims
+	UserID   string
+}
+
+type AdminEventsQuery struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (q *AdminEventsQuery) Validate() error {
+	return validators.ValidateStruct(q)
+}
+
+// AdminUser is the support desk view of a user. The PIN lock state is read
+// from Redis, which is ahead of the database copy.
+type AdminUser struct {
+	UserID            string     `json:"userID"`
+	Name              string     `json:"name"`
+	Roles             []string   `json:"roles"`
+	MustChangePin     bool       `json:"mustChangePin"`
+	FailedPinAttempts int        `json:"failedPinAttempts"`
+	PinLockedUntil    *time.Time `json:"pinLockedUntil,omitempty"`
+	ActiveSessions    int        `json:"activeSessions"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+// Auth event actions. Events an operator triggers through the admin API are
+// prefixed with "admin.".
+const (
+	AuthEventSignIn             = "sign_in"
+	AuthEventPinCheck           = "pin_check"
+	AuthEventPinLocked          = "pin_locked"
+	AuthEventPinChanged         = "pin_changed"
+	AuthEventPinReset           = "pin_reset"
+	AuthEventTokenRefreshed     = "token_refreshed"
+	AuthEventRefreshTokenReused = "refresh_token_reused"
+	AuthEventLogout             = "logout"
+	AuthEventSessionRevoked     = "session_revoked"
+	AuthEventTokensBanned       = "tokens_banned"
+
+	AuthEventAdminUserViewed        = "admin.user_viewed"
+	AuthEventAdminPinAttemptsViewed = "admin.pin_attempts_viewed"
+	AuthEventAdminPinUnlocked       = "admin.pin_unlocked"
+	AuthEventAdminSessionsViewed    = "admin.sessions_viewed"
+	AuthEventAdminSessionRevoked    = "admin.session_revoked"
+	AuthEventAdminPinResetForced    = "admin.pin_reset_forced"
+	AuthEventAdminEventsViewed      = "admin.events_viewed"
+	AuthEventAdminTokensBanned      = "admin.tokens_banned"
+)
+
+const (
+	AuthEventOutcomeSuccess = "success"
+	AuthEventOutcomeFailure = "failure"
+)
+
+// AuthEvent is one entry of the audit log. UserID is the user the event is
+// about, ActorID the one who caused it: the user themselves for sign-ins and
+// the operator for admin actions.
+type AuthEvent struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userID"`
+	Action    string    `json:"action"`
+	Outcome   string    `json:"outcome"`
+	ActorID   string    `json:"actorID"`
+	ActorName string    `json:"actorName,omitempty"`
+	IPAddress string    `json:"ipAddress,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	RequestID string    `json:"requestID,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -7,9 +7,24 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenPurposeChangePin marks a restricted access token that may only be used
+// to change the PIN. Regular tokens carry no purpose.
+const TokenPurposeChangePin = "change_pin"
+
 type PinVerifyParams struct {
-	Username string `json:"username"`
-	Pin      string `json:"pin" validate:"required,min=6,max=6,numeric"`
+	Username string        `json:"username"`
+	Pin      string        `json:"pin" validate:"required,min=6,max=6,numeric"`
+	Device   *DeviceParams `json:"device,omitempty"` // Optional, binds the session to the device
+}
+
+// DeviceParams registers the device a client signs in from. The public key is
+// the base64 encoded DER (SubjectPublicKeyInfo) key the device keeps in its
+// secure storage; it cannot change while the device is registered.
+type DeviceParams struct {
+	DeviceID  string `json:"deviceID" validate:"required,max=100"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Platform  string `json:"platform" validate:"required,oneof=ios android web"`
+	PublicKey string `json:"publicKey" validate:"required,base64,max=1024"`
 }
 
 func (p *PinVerifyParams) Validate() error {
@@ -21,24 +36,52 @@ type RefreshTokenRequest struct {
 }
 
 type TokenResponse struct {
-	Token        string    `json:"token"`
-	Expiry       time.Time `json:"expiry"`
-	RefreshToken string    `json:"refreshToken"`
-	UserID       string    `json:"userID"`
-	TokenVersion int64     `json:"tokenVersion"` // Track when token was issued (timestamp)
-	TokenID      string    `json:"tokenID"`      // Unique token identifier
-	IsBanned     *bool     `json:"isBanned,omitempty"`
+	Token          string     `json:"token"`
+	Expiry         time.Time  `json:"expiry"`
+	RefreshToken   string     `json:"refreshToken"`
+	UserID         string     `json:"userID"`
+	TokenVersion   int64      `json:"tokenVersion"`             // Track when token was issued (timestamp)
+	TokenID        string     `json:"tokenID"`                  // Unique token identifier
+	RefreshTokenID string     `json:"refreshTokenID,omitempty"` // Refresh token paired with the access token
+	RefreshExpiry  *time.Time `json:"refreshExpiry,omitempty"`
+	FamilyID       string     `json:"familyID,omitempty"` // Refresh token family the session belongs to
+	DeviceID       string     `json:"deviceID,omitempty"` // Registered device the session is bound to
+	Device         *Device    `json:"device,omitempty"`   // Set by ListUserTokens
+	IsBanned       *bool      `json:"isBanned,omitempty"`
+	IsActive       *bool      `json:"isActive,omitempty"`      // Neither token banned and the session not expired
+	MustChangePin  bool       `json:"mustChangePin,omitempty"` // Token only allows changing the PIN
+	TwoFactor      bool       `json:"twoFactor,omitempty"`     // Session passed the TOTP second factor
 }
 
 type Claims struct {
-	UserID       string `json:"userID"`
-	Username     string `json:"username"`
-	Type         string `json:"type" validate:"required,oneof=access refresh"`
-	TokenVersion int64  `json:"tokenVersion"`
-	TokenID      string `json:"tokenID"`
+	UserID         string   `json:"userID"`
+	Username       string   `json:"username"`
+	Type           string   `json:"type" validate:"required,oneof=access refresh"`
+	TokenVersion   int64    `json:"tokenVersion"`
+	TokenID        string   `json:"tokenID"`
+	RefreshTokenID string   `json:"refreshTokenID,omitempty"` // Set on access tokens only
+	FamilyID       string   `json:"familyID,omitempty"`       // Shared by all tokens issued from one sign-in
+	DeviceID       string   `json:"deviceID,omitempty"`       // Registered device of the session
+	Roles          []string `json:"roles,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"` // Union of the scopes of all roles
+	Purpose        string   `json:"purpose,omitempty"`
+	TwoFactor      bool     `json:"twoFactor,omitempty"`
+	AuthTime       int64    `json:"auth_time,omitempty"` // When the user last entered the PIN (Unix seconds)
 	jwt.RegisteredClaims
 }
 
+// TokenOptions returns the session properties to carry into new tokens.
+func (c Claims) TokenOptions() TokenOptions {
+	return TokenOptions{
+		TwoFactor: c.TwoFactor,
+		AuthTime:  c.AuthTime,
+		FamilyID:  c.FamilyID,
+		DeviceID:  c.DeviceID,
+		Roles:     c.Roles,
+		Scopes:    c.Scopes,
+	}
+}
+
 type BannedToken struct {
 	TokenID      string    `json:"tokenID"`
 	UserID       string    `json:"userID"`
@@ -62,6 +105,33 @@ type TokenValidationResult struct {
 	Claims       Claims `json:"claims,omitempty"`
 }
 
+// IntrospectParams is an RFC 7662 introspection request. Services may send it
+// as JSON or as a form.
+type IntrospectParams struct {
+	Token         string `json:"token" form:"token" validate:"required"`
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+}
+
+func (p *IntrospectParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+// TokenIntrospection is the RFC 7662 introspection response. An inactive
+// token only reports active and, when it was revoked, the reason.
+type TokenIntrospection struct {
+	Active           bool     `json:"active"`
+	Sub              string   `json:"sub,omitempty"`
+	Username         string   `json:"username,omitempty"`
+	TokenType        string   `json:"token_type,omitempty"`
+	Exp              int64    `json:"exp,omitempty"`
+	Iat              int64    `json:"iat,omitempty"`
+	Jti              string   `json:"jti,omitempty"`
+	Iss              string   `json:"iss,omitempty"`
+	Aud              []string `json:"aud,omitempty"`
+	AuthTime         int64    `json:"auth_time,omitempty"`
+	RevocationReason string   `json:"revocation_reason,omitempty"`
+}
+
 type PinAttemptData struct {
 	UserID         string     `json:"userID"`
 	FailedAttempts int        `json:"failedAttempts"`
@@ -78,9 +148,112 @@ func (b *BanTokensRequest) Validate() error {
 }
 
 type GenerateTokenParams struct {
-	UserID       string
-	Username     string
-	TokenVersion int64
-	TokenID      string
-	TokenType    string
+	UserID         string
+	Username       string
+	TokenVersion   int64
+	TokenID        string
+	TokenType      string
+	RefreshTokenID string
+	FamilyID       string
+	DeviceID       string
+	Roles          []string
+	Scopes         []string
+	Purpose        string
+	TwoFactor      bool
+	AuthTime       int64
+}
+
+// TokenOptions are the session properties copied into both tokens of a pair.
+type TokenOptions struct {
+	TwoFactor bool
+	AuthTime  int64
+	FamilyID  string // Empty starts a new refresh token family
+	DeviceID  string
+	Roles     []string
+	Scopes    []string
+}
+
+// Device is a registered device as shown to its user. The public key is not
+// returned.
+type Device struct {
+	DeviceID     string    `json:"deviceID"`
+	Name         string    `json:"name"`
+	Platform     string    `json:"platform"`
+	RegisteredAt time.Time `json:"registeredAt"`
+	LastSeenAt   time.Time `json:"lastSeenAt"`
+}
+
+type ChangePinParams struct {
+	CurrentPin string `json:"currentPin" validate:"required,min=6,max=6,numeric"`
+	NewPin     string `json:"newPin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (p *ChangePinParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+type PinResetStartParams struct {
+	Username string `json:"username" validate:"required"`
+}
+
+func (p *PinResetStartParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+type PinResetCompleteParams struct {
+	Username string `json:"username" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=6,numeric"`
+	NewPin   string `json:"newPin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (p *PinResetCompleteParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+// PinResetData is the pending reset stored in Redis. Only a hash of the
+// one-time code is kept; attempts are counted under their own key.
+type PinResetData struct {
+	UserID    string    `json:"userID"`
+	CodeHash  string    `json:"codeHash"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type ReauthParams struct {
+	Pin string `json:"pin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (p *ReauthParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+type TwoFactorEnrollParams struct {
+	Pin string `json:"pin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (p *TwoFactorEnrollParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+type TwoFactorEnrollment struct {
+	Secret        string   `json:"secret"`
+	OtpauthURI    string   `json:"otpauthURI"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorConfirmParams struct {
+	Code string `json:"code" validate:"required,min=6,max=6,numeric"`
+}
+
+func (p *TwoFactorConfirmParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+// TwoFactorVerifyParams takes either a TOTP code or a recovery code.
+type TwoFactorVerifyParams struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,min=6,max=6,numeric"`
+	RecoveryCode string `json:"recoveryCode" validate:"required_without=Code"`
+}
+
+func (p *TwoFactorVerifyParams) Validate() error {
+	return validators.ValidateStruct(p)
 }
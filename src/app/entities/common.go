@@ -5,16 +5,51 @@ import (
 	"github.com/Testzyler/banking-api/server/response"
 )
 
+const (
+	DefaultPage    = 1
+	DefaultPerPage = 20
+)
+
 type PaginationParams struct {
 	PerPage int    `json:"perPage" query:"perPage" validate:"required,min=1,max=100"`
 	Page    int    `json:"page" query:"page" validate:"required,min=1"`
 	Search  string `json:"search" query:"search" validate:"max=255"`
+	Sort    string `json:"sort" query:"sort" validate:"max=50"`
+	Order   string `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+
+	// Keyset mode, used instead of page/perPage when cursor or limit is set
+	Cursor string `json:"cursor" query:"cursor" validate:"max=1024"`
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SetDefaults fills in page and perPage when the client omits them
+func (p *PaginationParams) SetDefaults() {
+	if p.Page == 0 {
+		p.Page = DefaultPage
+	}
+	if p.PerPage == 0 {
+		p.PerPage = DefaultPerPage
+	}
+	if p.Order == "" {
+		p.Order = "asc"
+	}
+	if p.Cursor != "" && p.Limit == 0 {
+		p.Limit = DefaultPerPage
+	}
 }
 
 func (p *PaginationParams) Validate() error {
 	return validators.ValidateStruct(p)
 }
 
+func (p *PaginationParams) IsCursorMode() bool {
+	return p.Cursor != "" || p.Limit > 0
+}
+
+func (p *PaginationParams) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
 type PaginatedResponse struct {
 	response.SuccessResponse
 	Meta PaginationMeta `json:"meta"`
@@ -27,4 +62,24 @@ type PaginationMeta struct {
 	TotalPages  int  `json:"totalPages"`
 	HasNext     bool `json:"hasNext"`
 	HasPrevious bool `json:"hasPrevious"`
+
+	// Only set in cursor mode, page and total are not computed there
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+func NewPaginationMeta(page, perPage, total int) PaginationMeta {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+
+	return PaginationMeta{
+		Page:        page,
+		PerPage:     perPage,
+		Total:       total,
+		TotalPages:  totalPages,
+		HasNext:     page < totalPages,
+		HasPrevious: page > 1,
+	}
 }
@@ -1,11 +1,24 @@
 package entities
 
+import "github.com/Testzyler/banking-api/app/validators"
+
+const (
+	CardActionFreeze     = "freeze"
+	CardActionUnfreeze   = "unfreeze"
+	CardActionReportLost = "report-lost"
+	CardActionReplace    = "replace"
+	CardActionClose      = "close"
+	// CardActionReveal is only audited, it does not change the card status
+	CardActionReveal = "reveal"
+)
+
 type DebitCards struct {
 	CardID          string          `json:"cardID"`
-	CardNumber      string          `json:"cardNumber"`
+	CardNumber      string          `json:"cardNumber"` // masked to the last 4 digits outside the reveal endpoint
 	CardName        string          `json:"cardName"`
 	Issuer          string          `json:"issuer"`
 	Status          string          `json:"status"`
+	ReplacesCardID  string          `json:"replacesCardID,omitempty"`
 	DebitCardDesign DebitCardDesign `json:"cardDesign"`
 }
 
@@ -13,3 +26,23 @@ type DebitCardDesign struct {
 	Color       string `json:"color"`
 	BorderColor string `json:"borderColor"`
 }
+
+// CardActionRequest carries the PIN that every card status change and the
+// reveal endpoint re-check.
+type CardActionRequest struct {
+	Pin string `json:"pin" validate:"required,min=6,max=6,numeric"`
+}
+
+func (r *CardActionRequest) Validate() error {
+	return validators.ValidateStruct(r)
+}
+
+type CardActionParams struct {
+	UserID    string
+	Username  string
+	CardID    string
+	Action    string
+	Pin       string
+	IPAddress string
+	UserAgent string
+}
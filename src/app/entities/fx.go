@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+type FxRate struct {
+	Base   string    `json:"base"`
+	Quote  string    `json:"quote"`
+	Rate   string    `json:"rate"`
+	Source string    `json:"source"`
+	AsOf   time.Time `json:"asOf"`
+}
@@ -1,14 +1,17 @@
 package entities
 
+import "github.com/Testzyler/banking-api/app/money"
+
 type HomeParams struct {
 	UserID string `json:"userID" validate:"required,min=3,max=50"`
 }
 
 type HomeResponse struct {
 	User
-	DebitCards   []DebitCards  `json:"debitCards"`
-	Banners      []Banner      `json:"banners"`
-	Transactions []Transaction `json:"transactions"`
-	Accounts     []Account     `json:"accounts"`
-	TotalBalance float64       `json:"totalBalance"`
+	DebitCards        []DebitCards  `json:"debitCards"`
+	Banners           []Banner      `json:"banners"`
+	Transactions      []Transaction `json:"transactions"`
+	Accounts          []Account     `json:"accounts"`
+	TotalBalance      *money.Money  `json:"totalBalance"` // nil when a conversion rate is missing
+	TotalBalanceRates []FxRate      `json:"totalBalanceRates,omitempty"`
 }
@@ -0,0 +1,28 @@
+package entities
+
+import "slices"
+
+// Roles seeded by the migrations. Users get them through the user_roles table.
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+)
+
+// Scopes granted through roles. They are checked by RequireScope.
+const (
+	ScopeTokensBan      = "tokens:ban"      // Ban every token of any user
+	ScopeUsersRead      = "users:read"      // Look up users and their PIN attempts
+	ScopeUsersUnlock    = "users:unlock"    // Clear PIN lockouts
+	ScopeSessionsRead   = "sessions:read"   // List the sessions of any user
+	ScopeSessionsRevoke = "sessions:revoke" // Revoke sessions of any user
+	ScopePinReset       = "pin:reset"       // Force a user to change the PIN
+	ScopeAuditRead      = "audit:read"      // Read auth events
+)
+
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
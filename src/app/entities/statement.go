@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/validators"
+)
+
+const (
+	StatementFormatCSV = "csv"
+	StatementFormatOFX = "ofx"
+	StatementFormatPDF = "pdf"
+)
+
+type StatementParams struct {
+	From   string `json:"from" query:"from" validate:"required,datetime=2006-01-02"`
+	To     string `json:"to" query:"to" validate:"required,datetime=2006-01-02"`
+	Format string `json:"format" query:"format" validate:"omitempty,oneof=csv ofx pdf"`
+}
+
+func (p *StatementParams) SetDefaults() {
+	if p.Format == "" {
+		p.Format = StatementFormatCSV
+	}
+}
+
+func (p *StatementParams) Validate() error {
+	return validators.ValidateStruct(p)
+}
+
+// Period returns the statement start and the exclusive end, so the to date is
+// included in full. Dates are UTC days.
+func (p *StatementParams) Period() (time.Time, time.Time, error) {
+	from, err := time.Parse(time.DateOnly, p.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := time.Parse(time.DateOnly, p.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// Statement describes the account and period; entries are streamed
+// separately so the closing balance is taken from the last one written.
+type Statement struct {
+	AccountID      string
+	AccountNumber  string
+	AccountType    string
+	Issuer         string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance money.Amount
+	GeneratedAt    time.Time
+}
+
+// StatementEntry is one ledger movement. Amount is negative for debits.
+type StatementEntry struct {
+	EntryID     uint64
+	Date        time.Time
+	Type        string
+	Description string
+	Amount      money.Amount
+	Balance     money.Amount
+}
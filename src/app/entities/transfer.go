@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/validators"
+)
+
+type TransferRequest struct {
+	FromAccountID string       `json:"fromAccountID" validate:"required,max=50"`
+	ToAccountID   string       `json:"toAccountID" validate:"required,max=50"`
+	Amount        money.Amount `json:"amount" validate:"required,gt=0"`
+	Note          string       `json:"note" validate:"max=255"`
+}
+
+func (r *TransferRequest) Validate() error {
+	return validators.ValidateStruct(r)
+}
+
+type TransferResponse struct {
+	TransferID    string      `json:"transferID"`
+	FromAccountID string      `json:"fromAccountID"`
+	ToAccountID   string      `json:"toAccountID"`
+	Amount        money.Money `json:"amount"`
+	Note          string      `json:"note,omitempty"`
+	FromBalance   money.Money `json:"fromBalance"`
+	ToBalance     money.Money `json:"toBalance"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
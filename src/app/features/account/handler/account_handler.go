@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/account/service"
+	"github.com/Testzyler/banking-api/app/statement"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type accountHandler struct {
+	service service.AccountService
+}
+
+func NewAccountHandler(router fiber.Router, service service.AccountService) {
+	handler := &accountHandler{
+		service: service,
+	}
+
+	accounts := router.Group("/accounts")
+	accounts.Get("/:accountID/flags", middlewares.AuthMiddleware(), handler.GetAccountFlags)
+	accounts.Get("/:accountID/statement", middlewares.AuthMiddleware(), handler.GetStatement)
+	accounts.Post("/:accountID/reveal", middlewares.AuthMiddleware(), middlewares.RequireTwoFactor(), handler.RevealAccountNumber)
+}
+
+func (h *accountHandler) GetAccountFlags(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var params entities.PaginationParams
+	if err := c.QueryParser(&params); err != nil {
+		return exception.ErrInvalidPagination
+	}
+	params.SetDefaults()
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	flags, meta, err := h.service.GetAccountFlags(user.UserID, c.Params("accountID"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&entities.PaginatedResponse{
+		SuccessResponse: response.SuccessResponse{
+			Code:    response.Success,
+			Message: "Account flags retrieved successfully",
+			Data:    flags,
+		},
+		Meta: meta,
+	})
+}
+
+func (h *accountHandler) GetStatement(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var params entities.StatementParams
+	if err := c.QueryParser(&params); err != nil {
+		return exception.ErrInvalidStatementParams
+	}
+	params.SetDefaults()
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	stmt, err := h.service.GetStatement(user.UserID, c.Params("accountID"), params)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, statement.ContentType(params.Format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, statement.FileName(stmt, params.Format)))
+	c.Status(fiber.StatusOK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// headers are already sent, so a failure can only cut the file short
+		if err := h.service.WriteStatement(stmt, params.Format, w); err != nil {
+			logger.Errorf("Failed to stream statement for account %s: %v", stmt.AccountID, err)
+		}
+		if err := w.Flush(); err != nil {
+			logger.Warnf("Failed to flush statement for account %s: %v", stmt.AccountID, err)
+		}
+	})
+
+	return nil
+}
+
+func (h *accountHandler) RevealAccountNumber(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var req entities.RevealAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	account, err := h.service.RevealAccountNumber(c.Context(), user.UserID, user.Username, c.Params("accountID"), req.Pin)
+	if err != nil {
+		return err
+	}
+
+	// the full number must not end up in a shared cache
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Account number revealed successfully",
+		Data:    account,
+	})
+}
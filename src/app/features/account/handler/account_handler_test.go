@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockAccountService implements the account service interface for testing
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, entities.PaginationMeta, error) {
+	args := m.Called(userID, accountID, params)
+	if args.Error(2) != nil {
+		return nil, entities.PaginationMeta{}, args.Error(2)
+	}
+	return args.Get(0).([]entities.AccountFlags), args.Get(1).(entities.PaginationMeta), nil
+}
+
+func (m *MockAccountService) GetStatement(userID, accountID string, params entities.StatementParams) (entities.Statement, error) {
+	args := m.Called(userID, accountID, params)
+	return args.Get(0).(entities.Statement), args.Error(1)
+}
+
+func (m *MockAccountService) WriteStatement(stmt entities.Statement, format string, w io.Writer) error {
+	args := m.Called(stmt, format)
+	fmt.Fprint(w, args.String(0))
+	return args.Error(1)
+}
+
+func (m *MockAccountService) RevealAccountNumber(ctx context.Context, userID, username, accountID, pin string) (entities.RevealedAccount, error) {
+	args := m.Called(userID, username, accountID, pin)
+	return args.Get(0).(entities.RevealedAccount), args.Error(1)
+}
+
+func setupTestApp(handler *accountHandler) *fiber.App {
+	logger.Logger = zap.NewNop().Sugar()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler(),
+	})
+	app.Get("/accounts/:accountID/flags", func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return handler.GetAccountFlags(c)
+	})
+	app.Get("/accounts/:accountID/statement", func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return handler.GetStatement(c)
+	})
+	app.Post("/accounts/:accountID/reveal", func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return handler.RevealAccountNumber(c)
+	})
+	return app
+}
+
+func TestAccountHandler_GetAccountFlags(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockAccountService)
+		expectedStatus int
+	}{
+		{
+			name:  "cursor mode",
+			query: "?limit=50",
+			mockSetup: func(m *MockAccountService) {
+				params := entities.PaginationParams{Page: 1, PerPage: entities.DefaultPerPage, Order: "asc", Limit: 50}
+				m.On("GetAccountFlags", "user123", "acc1", params).
+					Return([]entities.AccountFlags{{FlagID: 1}}, entities.PaginationMeta{PerPage: 50}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:  "account not found",
+			query: "",
+			mockSetup: func(m *MockAccountService) {
+				m.On("GetAccountFlags", "user123", "acc1", mock.Anything).Return(nil, nil, exception.ErrAccountNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=tampered",
+			mockSetup: func(m *MockAccountService) {
+				m.On("GetAccountFlags", "user123", "acc1", mock.Anything).Return(nil, nil, exception.ErrInvalidCursor)
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "limit out of range",
+			query:          "?limit=0&cursor=x&perPage=1000",
+			mockSetup:      func(m *MockAccountService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAccountService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&accountHandler{service: mockService})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/accounts/acc1/flags"+tt.query, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_GetStatement(t *testing.T) {
+	stmt := entities.Statement{
+		AccountID: "acc1",
+		From:      time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		mockSetup           func(*MockAccountService)
+		expectedStatus      int
+		expectedContentType string
+		expectedFileName    string
+		expectedBody        string
+	}{
+		{
+			name:  "csv by default",
+			query: "?from=2025-07-01&to=2025-07-31",
+			mockSetup: func(m *MockAccountService) {
+				params := entities.StatementParams{From: "2025-07-01", To: "2025-07-31", Format: "csv"}
+				m.On("GetStatement", "user123", "acc1", params).Return(stmt, nil)
+				m.On("WriteStatement", stmt, "csv").Return("date,entry_id\n", nil)
+			},
+			expectedStatus:      fiber.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedFileName:    "statement-acc1-2025-07-01-2025-07-31.csv",
+			expectedBody:        "date,entry_id\n",
+		},
+		{
+			name:  "pdf",
+			query: "?from=2025-07-01&to=2025-07-31&format=pdf",
+			mockSetup: func(m *MockAccountService) {
+				m.On("GetStatement", "user123", "acc1", mock.Anything).Return(stmt, nil)
+				m.On("WriteStatement", stmt, "pdf").Return("%PDF-1.4", nil)
+			},
+			expectedStatus:      fiber.StatusOK,
+			expectedContentType: "application/pdf",
+			expectedFileName:    "statement-acc1-2025-07-01-2025-07-31.pdf",
+			expectedBody:        "%PDF-1.4",
+		},
+		{
+			name:           "unsupported format",
+			query:          "?from=2025-07-01&to=2025-07-31&format=xlsx",
+			mockSetup:      func(m *MockAccountService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "missing dates",
+			query:          "?format=ofx",
+			mockSetup:      func(m *MockAccountService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:  "account not found",
+			query: "?from=2025-07-01&to=2025-07-31",
+			mockSetup: func(m *MockAccountService) {
+				m.On("GetStatement", "user123", "acc1", mock.Anything).Return(entities.Statement{}, exception.ErrAccountNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAccountService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&accountHandler{service: mockService})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/accounts/acc1/statement"+tt.query, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == fiber.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.expectedContentType, resp.Header.Get(fiber.HeaderContentType))
+				assert.Equal(t, `attachment; filename="`+tt.expectedFileName+`"`, resp.Header.Get(fiber.HeaderContentDisposition))
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_RevealAccountNumber(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockAccountService)
+		expectedStatus int
+	}{
+		{
+			name: "reveal",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockAccountService) {
+				m.On("RevealAccountNumber", "user123", "testuser", "acc1", "123456").
+					Return(entities.RevealedAccount{AccountID: "acc1", AccountNumber: "123456789012"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "missing pin",
+			body:           `{}`,
+			mockSetup:      func(m *MockAccountService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "wrong pin",
+			body: `{"pin":"000000"}`,
+			mockSetup: func(m *MockAccountService) {
+				m.On("RevealAccountNumber", "user123", "testuser", "acc1", "000000").
+					Return(entities.RevealedAccount{}, exception.NewInvalidPinError(2))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAccountService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&accountHandler{service: mockService})
+
+			req := httptest.NewRequest("POST", "/accounts/acc1/reveal", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == fiber.StatusOK {
+				assert.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
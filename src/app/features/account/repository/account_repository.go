@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"gorm.io/gorm"
+)
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+type AccountRepository interface {
+	IsAccountOwner(userID, accountID string) (bool, error)
+	GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, int, error)
+	GetAccountFlagsByCursor(userID, accountID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.AccountFlags, bool, error)
+	GetAccount(userID, accountID string) (*models.Account, error)
+	GetBalanceBefore(accountID string, before time.Time) (money.Amount, error)
+	StreamStatementEntries(accountID string, from, to time.Time, fn func(entities.StatementEntry) error) error
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{
+		db: db,
+	}
+}
+
+func (r *accountRepository) IsAccountOwner(userID, accountID string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.Account{}).
+		Where("account_id = ? AND user_id = ?", accountID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *accountRepository) flagsQuery(userID, accountID string) *gorm.DB {
+	return r.db.Model(&models.AccountFlag{}).Where("account_id = ? AND user_id = ?", accountID, userID)
+}
+
+func (r *accountRepository) GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, int, error) {
+	query := r.flagsQuery(userID, accountID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "flag_id ASC"
+	if params.Order == "desc" {
+		order = "flag_id DESC"
+	}
+
+	var flags []models.AccountFlag
+	if err := query.Order(order).
+		Limit(params.PerPage).
+		Offset(params.Offset()).
+		Find(&flags).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return toAccountFlagEntities(flags), int(total), nil
+}
+
+func (r *accountRepository) GetAccountFlagsByCursor(userID, accountID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.AccountFlags, bool, error) {
+	query := pagination.ApplyKeyset(r.flagsQuery(userID, accountID), "flag_id", "flag_id", params.Order, cursor)
+
+	var flags []models.AccountFlag
+	if err := query.Limit(params.Limit + 1).Find(&flags).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(flags) > params.Limit
+	if hasMore {
+		flags = flags[:params.Limit]
+	}
+
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(flags)-1; i < j; i, j = i+1, j-1 {
+			flags[i], flags[j] = flags[j], flags[i]
+		}
+	}
+
+	return toAccountFlagEntities(flags), hasMore, nil
+}
+
+func toAccountFlagEntities(flags []models.AccountFlag) []entities.AccountFlags {
+	result := make([]entities.AccountFlags, 0, len(flags))
+	for _, f := range flags {
+		result = append(result, entities.AccountFlags{
+			FlagID:    f.FlagID,
+			FlagType:  f.FlagType,
+			FlagValue: f.FlagValue,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+		})
+	}
+	return result
+}
+
+func (r *accountRepository) GetAccount(userID, accountID string) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.Where("account_id = ? AND user_id = ?", accountID, userID).
+		First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetBalanceBefore returns the balance after the last ledger entry posted
+// before the given time, or zero when there is none.
+func (r *accountRepository) GetBalanceBefore(accountID string, before time.Time) (money.Amount, error) {
+	var entries []models.LedgerEntry
+	if err := r.db.Where("account_id = ? AND created_at < ?", accountID, before).
+		Order("created_at DESC, entry_id DESC").
+		Limit(1).
+		Find(&entries).Error; err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	return entries[0].BalanceAfter, nil
+}
+
+type statementRow struct {
+	EntryID       uint64
+	EntryType     string
+	Direction     string
+	Amount        money.Amount
+	BalanceAfter  money.Amount
+	CreatedAt     time.Time
+	FromAccountID *string
+	ToAccountID   *string
+	Note          *string
+}
+
+// StreamStatementEntries calls fn for each ledger entry in [from, to) in
+// posting order, reading rows one at a time instead of loading the period.
+func (r *accountRepository) StreamStatementEntries(accountID string, from, to time.Time, fn func(entities.StatementEntry) error) error {
+	rows, err := r.db.Model(&models.LedgerEntry{}).
+		Select("ledger_entries.entry_id, ledger_entries.entry_type, ledger_entries.direction, ledger_entries.amount, "+
+			"ledger_entries.balance_after, ledger_entries.created_at, "+
+			"transfers.from_account_id, transfers.to_account_id, transfers.note").
+		Joins("LEFT JOIN transfers ON transfers.transfer_id = ledger_entries.transfer_id").
+		Where("ledger_entries.account_id = ? AND ledger_entries.created_at >= ? AND ledger_entries.created_at < ?", accountID, from, to).
+		Order("ledger_entries.created_at ASC, ledger_entries.entry_id ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row statementRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(toStatementEntry(row)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func toStatementEntry(row statementRow) entities.StatementEntry {
+	entry := entities.StatementEntry{
+		EntryID: row.EntryID,
+		Date:    row.CreatedAt,
+		Type:    row.EntryType,
+		Amount:  row.Amount,
+		Balance: row.BalanceAfter,
+	}
+	if row.Direction == models.LedgerDirectionDebit {
+		entry.Amount = -row.Amount
+	}
+
+	switch {
+	case row.EntryType == models.LedgerEntryTypeOpening:
+		entry.Description = "Opening balance"
+	case row.Direction == models.LedgerDirectionDebit && row.ToAccountID != nil:
+		entry.Description = "Transfer to " + *row.ToAccountID
+	case row.Direction == models.LedgerDirectionCredit && row.FromAccountID != nil:
+		entry.Description = "Transfer from " + *row.FromAccountID
+	default:
+		entry.Description = row.EntryType
+	}
+	if row.Note != nil && *row.Note != "" {
+		entry.Description += " - " + *row.Note
+	}
+	return entry
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func TestAccountRepository_IsAccountOwner(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		queryErr    error
+		expectOwner bool
+		expectError bool
+	}{
+		{name: "owner", count: 1, expectOwner: true},
+		{name: "not owner", count: 0, expectOwner: false},
+		{name: "query error", queryErr: errors.New("connection lost"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			expect := mock.ExpectQuery("SELECT count\\(\\*\\) FROM `accounts` WHERE account_id = \\? AND user_id = \\?").
+				WithArgs("acc1", "user123")
+			if tt.queryErr != nil {
+				expect.WillReturnError(tt.queryErr)
+			} else {
+				expect.WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			}
+
+			isOwner, err := NewAccountRepository(gormDB).IsAccountOwner("user123", "acc1")
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectOwner, isOwner)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccountRepository_GetAccountFlags(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `account_flags` WHERE account_id = \\? AND user_id = \\?").
+		WithArgs("acc1", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(30))
+	mock.ExpectQuery("SELECT \\* FROM `account_flags` WHERE account_id = \\? AND user_id = \\? ORDER BY flag_id DESC LIMIT \\? OFFSET \\?").
+		WithArgs("acc1", "user123", 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"flag_id", "account_id", "user_id", "flag_type", "flag_value", "created_at", "updated_at"}).
+			AddRow(20, "acc1", "user123", "system", "Flag20", now, now))
+
+	params := entities.PaginationParams{Page: 2, PerPage: 10, Order: "desc"}
+	flags, total, err := NewAccountRepository(gormDB).GetAccountFlags("user123", "acc1", params)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30, total)
+	assert.Len(t, flags, 1)
+	assert.Equal(t, 20, flags[0].FlagID)
+	assert.Equal(t, "Flag20", flags[0].FlagValue)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_GetAccountFlagsByCursor(t *testing.T) {
+	tests := []struct {
+		name       string
+		cursor     *pagination.Cursor
+		mockSetup  func(sqlmock.Sqlmock)
+		expectIDs  []int
+		expectMore bool
+	}{
+		{
+			name: "first page",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `account_flags` WHERE account_id = \\? AND user_id = \\? ORDER BY flag_id ASC LIMIT \\?").
+					WithArgs("acc1", "user123", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"flag_id"}).AddRow(1).AddRow(2).AddRow(3))
+			},
+			expectIDs:  []int{1, 2},
+			expectMore: true,
+		},
+		{
+			name:   "seek after cursor",
+			cursor: &pagination.Cursor{Order: "asc", ID: "5000000"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `account_flags` WHERE \\(account_id = \\? AND user_id = \\?\\) AND flag_id > \\? ORDER BY flag_id ASC LIMIT \\?").
+					WithArgs("acc1", "user123", "5000000", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"flag_id"}).AddRow(5000001))
+			},
+			expectIDs:  []int{5000001},
+			expectMore: false,
+		},
+		{
+			name:   "seek before cursor",
+			cursor: &pagination.Cursor{Order: "asc", ID: "10", Backward: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `account_flags` WHERE \\(account_id = \\? AND user_id = \\?\\) AND flag_id < \\? ORDER BY flag_id DESC LIMIT \\?").
+					WithArgs("acc1", "user123", "10", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"flag_id"}).AddRow(9).AddRow(8))
+			},
+			expectIDs:  []int{8, 9},
+			expectMore: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+			tt.mockSetup(mock)
+
+			params := entities.PaginationParams{Limit: 2, Order: "asc"}
+			flags, hasMore, err := NewAccountRepository(gormDB).GetAccountFlagsByCursor("user123", "acc1", params, tt.cursor)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectMore, hasMore)
+			var ids []int
+			for _, f := range flags {
+				ids = append(ids, f.FlagID)
+			}
+			assert.Equal(t, tt.expectIDs, ids)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccountRepository_GetBalanceBefore(t *testing.T) {
+	before := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rows     *sqlmock.Rows
+		expected money.Amount
+	}{
+		{
+			name:     "last entry before the period",
+			rows:     sqlmock.NewRows([]string{"entry_id", "balance_after"}).AddRow(12, "1500.00"),
+			expected: 150000,
+		},
+		{
+			name:     "no entries yet",
+			rows:     sqlmock.NewRows([]string{"entry_id", "balance_after"}),
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectQuery("SELECT \\* FROM `ledger_entries` WHERE account_id = \\? AND created_at < \\? ORDER BY created_at DESC, entry_id DESC LIMIT \\?").
+				WithArgs("acc1", before, 1).
+				WillReturnRows(tt.rows)
+
+			balance, err := NewAccountRepository(gormDB).GetBalanceBefore("acc1", before)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, balance)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccountRepository_StreamStatementEntries(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	postedAt := time.Date(2025, 7, 3, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT ledger_entries\\.entry_id, .* FROM `ledger_entries` LEFT JOIN transfers ON transfers\\.transfer_id = ledger_entries\\.transfer_id "+
+		"WHERE ledger_entries\\.account_id = \\? AND ledger_entries\\.created_at >= \\? AND ledger_entries\\.created_at < \\? "+
+		"ORDER BY ledger_entries\\.created_at ASC, ledger_entries\\.entry_id ASC").
+		WithArgs("acc1", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "entry_type", "direction", "amount", "balance_after", "created_at", "from_account_id", "to_account_id", "note"}).
+			AddRow(1, "opening", "credit", "1500.00", "1500.00", postedAt, nil, nil, nil).
+			AddRow(2, "transfer", "debit", "25.50", "1474.50", postedAt, "acc1", "acc2", "rent").
+			AddRow(3, "transfer", "credit", "10.00", "1484.50", postedAt, "acc3", "acc1", ""))
+
+	var entries []entities.StatementEntry
+	err := NewAccountRepository(gormDB).StreamStatementEntries("acc1", from, to, func(entry entities.StatementEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.StatementEntry{
+		{EntryID: 1, Date: postedAt, Type: "opening", Description: "Opening balance", Amount: 150000, Balance: 150000},
+		{EntryID: 2, Date: postedAt, Type: "transfer", Description: "Transfer to acc2 - rent", Amount: -2550, Balance: 147450},
+		{EntryID: 3, Date: postedAt, Type: "transfer", Description: "Transfer from acc3", Amount: 1000, Balance: 148450},
+	}, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_StreamStatementEntries_StopsOnWriteError(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT ledger_entries\\.entry_id").
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "entry_type", "direction", "amount", "balance_after"}).
+			AddRow(1, "opening", "credit", "1.00", "1.00").
+			AddRow(2, "opening", "credit", "1.00", "2.00"))
+
+	calls := 0
+	err := NewAccountRepository(gormDB).StreamStatementEntries("acc1", time.Now(), time.Now(), func(entities.StatementEntry) error {
+		calls++
+		return errors.New("broken pipe")
+	})
+
+	assert.EqualError(t, err, "broken pipe")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/account/repository"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/app/statement"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"gorm.io/gorm"
+)
+
+// PinVerifier re-checks the signed-in user's PIN, the auth service
+// implements it.
+type PinVerifier interface {
+	ConfirmPin(ctx context.Context, userID, username, pin string) error
+}
+
+type accountService struct {
+	config      *config.Config
+	repo        repository.AccountRepository
+	pinVerifier PinVerifier
+}
+
+type AccountService interface {
+	GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, entities.PaginationMeta, error)
+	GetStatement(userID, accountID string, params entities.StatementParams) (entities.Statement, error)
+	WriteStatement(stmt entities.Statement, format string, w io.Writer) error
+	RevealAccountNumber(ctx context.Context, userID, username, accountID, pin string) (entities.RevealedAccount, error)
+}
+
+func NewAccountService(repo repository.AccountRepository, pinVerifier PinVerifier, config *config.Config) AccountService {
+	return &accountService{
+		config:      config,
+		repo:        repo,
+		pinVerifier: pinVerifier,
+	}
+}
+
+func (s *accountService) GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, entities.PaginationMeta, error) {
+	// flags are only ordered by flag_id
+	if params.Sort != "" && params.Sort != "flagID" {
+		return nil, entities.PaginationMeta{}, exception.ErrInvalidSortField
+	}
+
+	isOwner, err := s.repo.IsAccountOwner(userID, accountID)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, exception.NewDatabaseError(err)
+	}
+	if !isOwner {
+		return nil, entities.PaginationMeta{}, exception.ErrAccountNotFound
+	}
+
+	if params.IsCursorMode() {
+		return s.getAccountFlagsByCursor(userID, accountID, params)
+	}
+
+	flags, total, err := s.repo.GetAccountFlags(userID, accountID, params)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	return flags, entities.NewPaginationMeta(params.Page, params.PerPage, total), nil
+}
+
+func (s *accountService) getAccountFlagsByCursor(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, entities.PaginationMeta, error) {
+	secret := s.config.Pagination.CursorSecret
+
+	cursor, err := pagination.DecodeParams(params, secret)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, entities.PaginationMeta{}, exception.ErrInvalidCursor
+		}
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	flags, hasMore, err := s.repo.GetAccountFlagsByCursor(userID, accountID, params, cursor)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	var first, last *pagination.Cursor
+	if len(flags) > 0 {
+		first = &pagination.Cursor{Sort: params.Sort, Order: params.Order, ID: strconv.Itoa(flags[0].FlagID)}
+		last = &pagination.Cursor{Sort: params.Sort, Order: params.Order, ID: strconv.Itoa(flags[len(flags)-1].FlagID)}
+	}
+
+	meta, err := pagination.NewCursorMeta(params.Limit, cursor, hasMore, first, last, secret)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, exception.NewInternalError(err)
+	}
+
+	return flags, meta, nil
+}
+
+// GetStatement checks ownership and works out the opening balance. It runs
+// before anything is streamed so errors still produce a normal response.
+func (s *accountService) GetStatement(userID, accountID string, params entities.StatementParams) (entities.Statement, error) {
+	from, to, err := params.Period()
+	if err != nil {
+		return entities.Statement{}, exception.ErrInvalidStatementParams
+	}
+	if !from.Before(to) {
+		return entities.Statement{}, exception.ErrInvalidStatementPeriod
+	}
+
+	account, err := s.repo.GetAccount(userID, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Statement{}, exception.ErrAccountNotFound
+		}
+		return entities.Statement{}, exception.NewDatabaseError(err)
+	}
+
+	opening, err := s.repo.GetBalanceBefore(accountID, from)
+	if err != nil {
+		return entities.Statement{}, exception.NewDatabaseError(err)
+	}
+
+	return entities.Statement{
+		AccountID:      account.AccountID,
+		AccountNumber:  account.AccountNumber,
+		AccountType:    account.Type,
+		Issuer:         account.Issuer,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to.AddDate(0, 0, -1),
+		OpeningBalance: opening,
+		GeneratedAt:    time.Now().UTC(),
+	}, nil
+}
+
+// WriteStatement streams the statement entries to w in the given format.
+func (s *accountService) WriteStatement(stmt entities.Statement, format string, w io.Writer) error {
+	writer, err := statement.NewWriter(format, w, stmt)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.StreamStatementEntries(stmt.AccountID, stmt.From, stmt.To.AddDate(0, 0, 1), writer.WriteEntry); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// RevealAccountNumber returns the full account number after re-checking the PIN.
+func (s *accountService) RevealAccountNumber(ctx context.Context, userID, username, accountID, pin string) (entities.RevealedAccount, error) {
+	if err := s.pinVerifier.ConfirmPin(ctx, userID, username, pin); err != nil {
+		return entities.RevealedAccount{}, err
+	}
+
+	account, err := s.repo.GetAccount(userID, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.RevealedAccount{}, exception.ErrAccountNotFound
+		}
+		return entities.RevealedAccount{}, exception.NewDatabaseError(err)
+	}
+
+	logger.Infof("Account %s of user %s revealed", account.AccountID, userID)
+	return entities.RevealedAccount{
+		AccountID:     account.AccountID,
+		AccountNumber: account.AccountNumber,
+		Issuer:        account.Issuer,
+	}, nil
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock AccountRepository
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) IsAccountOwner(userID, accountID string) (bool, error) {
+	args := m.Called(userID, accountID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetAccountFlags(userID, accountID string, params entities.PaginationParams) ([]entities.AccountFlags, int, error) {
+	args := m.Called(userID, accountID, params)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]entities.AccountFlags), args.Int(1), args.Error(2)
+}
+
+func (m *MockAccountRepository) GetAccountFlagsByCursor(userID, accountID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.AccountFlags, bool, error) {
+	args := m.Called(userID, accountID, params, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]entities.AccountFlags), args.Bool(1), args.Error(2)
+}
+
+func (m *MockAccountRepository) GetAccount(userID, accountID string) (*models.Account, error) {
+	args := m.Called(userID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetBalanceBefore(accountID string, before time.Time) (money.Amount, error) {
+	args := m.Called(accountID, before)
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockAccountRepository) StreamStatementEntries(accountID string, from, to time.Time, fn func(entities.StatementEntry) error) error {
+	args := m.Called(accountID, from, to)
+	if entries, ok := args.Get(0).([]entities.StatementEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// Mock PinVerifier
+type MockPinVerifier struct {
+	mock.Mock
+}
+
+func (m *MockPinVerifier) ConfirmPin(ctx context.Context, userID, username, pin string) error {
+	args := m.Called(userID, username, pin)
+	return args.Error(0)
+}
+
+func createTestConfig() *config.Config {
+	return &config.Config{
+		Pagination: &config.PaginationConfig{CursorSecret: "test-cursor-secret"},
+	}
+}
+
+func TestAccountService_GetAccountFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      entities.PaginationParams
+		mockSetup   func(*MockAccountRepository)
+		expectError error
+		validate    func(*testing.T, []entities.AccountFlags, entities.PaginationMeta)
+	}{
+		{
+			name:   "offset mode",
+			params: entities.PaginationParams{Page: 1, PerPage: 10, Order: "asc"},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("IsAccountOwner", "user123", "acc1").Return(true, nil)
+				m.On("GetAccountFlags", "user123", "acc1", mock.Anything).
+					Return([]entities.AccountFlags{{FlagID: 1}}, 15, nil)
+			},
+			validate: func(t *testing.T, flags []entities.AccountFlags, meta entities.PaginationMeta) {
+				assert.Len(t, flags, 1)
+				assert.Equal(t, 2, meta.TotalPages)
+				assert.True(t, meta.HasNext)
+			},
+		},
+		{
+			name:   "cursor mode first page",
+			params: entities.PaginationParams{Limit: 2, Order: "asc"},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("IsAccountOwner", "user123", "acc1").Return(true, nil)
+				m.On("GetAccountFlagsByCursor", "user123", "acc1", mock.Anything, (*pagination.Cursor)(nil)).
+					Return([]entities.AccountFlags{{FlagID: 1}, {FlagID: 2}}, true, nil)
+			},
+			validate: func(t *testing.T, flags []entities.AccountFlags, meta entities.PaginationMeta) {
+				next, err := pagination.Decode(meta.NextCursor, "test-cursor-secret")
+				assert.NoError(t, err)
+				assert.Equal(t, "2", next.ID)
+				assert.Empty(t, meta.PrevCursor)
+			},
+		},
+		{
+			name:   "account owned by someone else",
+			params: entities.PaginationParams{Page: 1, PerPage: 10},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("IsAccountOwner", "user123", "acc1").Return(false, nil)
+			},
+			expectError: exception.ErrAccountNotFound,
+		},
+		{
+			name:        "unsupported sort field",
+			params:      entities.PaginationParams{Page: 1, PerPage: 10, Sort: "flagValue"},
+			mockSetup:   func(m *MockAccountRepository) {},
+			expectError: exception.ErrInvalidSortField,
+		},
+		{
+			name:   "invalid cursor",
+			params: entities.PaginationParams{Cursor: "nope", Limit: 5, Order: "asc"},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("IsAccountOwner", "user123", "acc1").Return(true, nil)
+			},
+			expectError: exception.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAccountRepository)
+			tt.mockSetup(mockRepo)
+
+			flags, meta, err := NewAccountService(mockRepo, new(MockPinVerifier), createTestConfig()).GetAccountFlags("user123", "acc1", tt.params)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+				tt.validate(t, flags, meta)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountService_GetAccountFlags_OwnershipCheckError(t *testing.T) {
+	mockRepo := new(MockAccountRepository)
+	mockRepo.On("IsAccountOwner", "user123", "acc1").Return(false, errors.New("connection lost"))
+
+	_, _, err := NewAccountService(mockRepo, new(MockPinVerifier), createTestConfig()).GetAccountFlags("user123", "acc1", entities.PaginationParams{Page: 1, PerPage: 10})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection lost")
+}
+
+func TestAccountService_GetStatement(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	account := &models.Account{AccountID: "acc1", UserID: "user123", Type: "saving-account", Currency: "THB", AccountNumber: "123456789012"}
+
+	tests := []struct {
+		name        string
+		params      entities.StatementParams
+		mockSetup   func(*MockAccountRepository)
+		expectError error
+	}{
+		{
+			name:   "success",
+			params: entities.StatementParams{From: "2025-07-01", To: "2025-07-31", Format: "csv"},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("GetAccount", "user123", "acc1").Return(account, nil)
+				m.On("GetBalanceBefore", "acc1", from).Return(money.Amount(150000), nil)
+			},
+		},
+		{
+			name:   "account owned by someone else",
+			params: entities.StatementParams{From: "2025-07-01", To: "2025-07-31", Format: "csv"},
+			mockSetup: func(m *MockAccountRepository) {
+				m.On("GetAccount", "user123", "acc1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrAccountNotFound,
+		},
+		{
+			name:        "from after to",
+			params:      entities.StatementParams{From: "2025-08-01", To: "2025-07-31", Format: "csv"},
+			mockSetup:   func(m *MockAccountRepository) {},
+			expectError: exception.ErrInvalidStatementPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAccountRepository)
+			tt.mockSetup(mockRepo)
+
+			stmt, err := NewAccountService(mockRepo, new(MockPinVerifier), createTestConfig()).GetStatement("user123", "acc1", tt.params)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "123456789012", stmt.AccountNumber)
+				assert.Equal(t, from, stmt.From)
+				assert.Equal(t, time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC), stmt.To)
+				assert.Equal(t, money.Amount(150000), stmt.OpeningBalance)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountService_WriteStatement(t *testing.T) {
+	stmt := entities.Statement{
+		AccountID:      "acc1",
+		Currency:       "THB",
+		From:           time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 150000,
+	}
+
+	mockRepo := new(MockAccountRepository)
+	mockRepo.On("StreamStatementEntries", "acc1", stmt.From, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)).
+		Return([]entities.StatementEntry{
+			{EntryID: 7, Date: time.Date(2025, 7, 3, 9, 0, 0, 0, time.UTC), Type: "transfer", Description: "Transfer to acc2", Amount: -2550, Balance: 147450},
+		}, nil)
+
+	var buf bytes.Buffer
+	err := NewAccountService(mockRepo, new(MockPinVerifier), createTestConfig()).WriteStatement(stmt, entities.StatementFormatCSV, &buf)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "2025-07-03,7,transfer,Transfer to acc2,-25.50,1474.50,THB")
+	assert.Contains(t, buf.String(), "2025-07-31,,,Closing balance,,1474.50,THB")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccountService_WriteStatement_StreamError(t *testing.T) {
+	mockRepo := new(MockAccountRepository)
+	mockRepo.On("StreamStatementEntries", "acc1", mock.Anything, mock.Anything).Return(nil, errors.New("connection lost"))
+
+	var buf bytes.Buffer
+	err := NewAccountService(mockRepo, new(MockPinVerifier), createTestConfig()).WriteStatement(entities.Statement{AccountID: "acc1"}, entities.StatementFormatOFX, &buf)
+
+	assert.EqualError(t, err, "connection lost")
+}
+
+func TestAccountService_RevealAccountNumber(t *testing.T) {
+	account := &models.Account{AccountID: "acc1", UserID: "user123", Issuer: "TestBank", AccountNumber: "123456789012"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAccountRepository, *MockPinVerifier)
+		expected    entities.RevealedAccount
+		expectError error
+	}{
+		{
+			name: "reveals the full number",
+			mockSetup: func(repo *MockAccountRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetAccount", "user123", "acc1").Return(account, nil)
+			},
+			expected: entities.RevealedAccount{AccountID: "acc1", AccountNumber: "123456789012", Issuer: "TestBank"},
+		},
+		{
+			name: "wrong pin",
+			mockSetup: func(repo *MockAccountRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(exception.NewInvalidPinError(2))
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+		{
+			name: "account of another user",
+			mockSetup: func(repo *MockAccountRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetAccount", "user123", "acc1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockAccountRepository)
+			pin := new(MockPinVerifier)
+			tt.mockSetup(repo, pin)
+
+			revealed, err := NewAccountService(repo, pin, createTestConfig()).
+				RevealAccountNumber(context.Background(), "user123", "testuser", "acc1", "123456")
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, revealed)
+			}
+			repo.AssertExpectations(t)
+			pin.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/admin/service"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type adminHandler struct {
+	service service.AdminService
+}
+
+// NewAdminHandler registers the support desk endpoints. The whole group needs
+// a two-factor session with the admin or support role, each route then checks
+// its own scope.
+func NewAdminHandler(router fiber.Router, service service.AdminService) {
+	handler := &adminHandler{
+		service: service,
+	}
+
+	admin := router.Group("/admin",
+		middlewares.AuthMiddleware(),
+		middlewares.RequireTwoFactor(),
+		middlewares.RequireRole(entities.RoleAdmin, entities.RoleSupport),
+	)
+	admin.Get("/users/:userID", middlewares.RequireScope(entities.ScopeUsersRead), handler.GetUser)
+	admin.Get("/users/:userID/pin-attempts", middlewares.RequireScope(entities.ScopeUsersRead), handler.GetPinAttempts)
+	admin.Post("/users/:userID/unlock-pin", middlewares.RequireScope(entities.ScopeUsersUnlock), handler.UnlockPin)
+	admin.Get("/users/:userID/sessions", middlewares.RequireScope(entities.ScopeSessionsRead), handler.ListSessions)
+	admin.Delete("/users/:userID/sessions/:tokenID", middlewares.RequireScope(entities.ScopeSessionsRevoke), handler.RevokeSession)
+	admin.Post("/users/:userID/force-pin-reset", middlewares.RequireScope(entities.ScopePinReset), handler.ForcePinReset)
+	admin.Get("/users/:userID/events", middlewares.RequireScope(entities.ScopeAuditRead), handler.ListEvents)
+}
+
+// actionParams identifies the operator and the target user of the request.
+func actionParams(c *fiber.Ctx) (entities.AdminActionParams, error) {
+	operator, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return entities.AdminActionParams{}, exception.ErrUnauthorized
+	}
+
+	return entities.AdminActionParams{
+		Operator: operator,
+		UserID:   c.Params("userID"),
+	}, nil
+}
+
+func (h *adminHandler) GetUser(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.service.GetUser(c.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "User retrieved successfully",
+		Data:    user,
+	})
+}
+
+func (h *adminHandler) GetPinAttempts(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := h.service.GetPinAttempts(c.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN attempts retrieved successfully",
+		Data:    attempts,
+	})
+}
+
+func (h *adminHandler) UnlockPin(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.UnlockPin(c.Context(), params); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN unlocked successfully",
+	})
+}
+
+func (h *adminHandler) ListSessions(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := h.service.ListSessions(c.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+func (h *adminHandler) RevokeSession(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.RevokeSession(c.Context(), params, c.Params("tokenID")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Session revoked successfully",
+	})
+}
+
+func (h *adminHandler) ForcePinReset(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.ForcePinReset(c.Context(), params); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN reset forced successfully",
+	})
+}
+
+func (h *adminHandler) ListEvents(c *fiber.Ctx) error {
+	params, err := actionParams(c)
+	if err != nil {
+		return err
+	}
+
+	var query entities.AdminEventsQuery
+	if err := c.QueryParser(&query); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	events, err := h.service.ListEvents(c.Context(), params, query.Limit)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Auth events retrieved successfully",
+		Data:    events,
+	})
+}
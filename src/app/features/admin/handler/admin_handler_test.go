@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockAdminService implements the admin service interface for testing
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) GetUser(ctx context.Context, params entities.AdminActionParams) (*entities.AdminUser, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AdminUser), args.Error(1)
+}
+
+func (m *MockAdminService) GetPinAttempts(ctx context.Context, params entities.AdminActionParams) (*entities.PinAttemptData, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PinAttemptData), args.Error(1)
+}
+
+func (m *MockAdminService) UnlockPin(ctx context.Context, params entities.AdminActionParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) ListSessions(ctx context.Context, params entities.AdminActionParams) ([]entities.TokenResponse, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockAdminService) RevokeSession(ctx context.Context, params entities.AdminActionParams, tokenID string) error {
+	args := m.Called(params, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) ForcePinReset(ctx context.Context, params entities.AdminActionParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) ListEvents(ctx context.Context, params entities.AdminActionParams, limit int) ([]entities.AuthEvent, error) {
+	args := m.Called(params, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.AuthEvent), args.Error(1)
+}
+
+var supportOperator = entities.Claims{
+	UserID:   "support1",
+	Username: "desk",
+	Roles:    []string{entities.RoleSupport},
+	Scopes:   []string{entities.ScopeUsersRead, entities.ScopeUsersUnlock, entities.ScopeSessionsRead, entities.ScopeAuditRead},
+}
+
+// setupTestApp registers the admin routes with their scope checks behind a
+// stub that signs the support operator in.
+func setupTestApp(handler *adminHandler) *fiber.App {
+	logger.Logger = zap.NewNop().Sugar()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler(),
+	})
+	setUser := func(c *fiber.Ctx) error {
+		c.Locals("user", supportOperator)
+		return c.Next()
+	}
+	app.Get("/admin/users/:userID", setUser, middlewares.RequireScope(entities.ScopeUsersRead), handler.GetUser)
+	app.Post("/admin/users/:userID/unlock-pin", setUser, middlewares.RequireScope(entities.ScopeUsersUnlock), handler.UnlockPin)
+	app.Delete("/admin/users/:userID/sessions/:tokenID", setUser, middlewares.RequireScope(entities.ScopeSessionsRevoke), handler.RevokeSession)
+	app.Post("/admin/users/:userID/force-pin-reset", setUser, middlewares.RequireScope(entities.ScopePinReset), handler.ForcePinReset)
+	app.Get("/admin/users/:userID/events", setUser, middlewares.RequireScope(entities.ScopeAuditRead), handler.ListEvents)
+	return app
+}
+
+// operatorFor matches the params the handler builds for the support operator.
+func operatorFor(userID string) interface{} {
+	return mock.MatchedBy(func(params entities.AdminActionParams) bool {
+		return params.UserID == userID && params.Operator.UserID == supportOperator.UserID
+	})
+}
+
+func TestAdminHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func(*MockAdminService)
+		expectedStatus int
+	}{
+		{
+			name:   "look up user",
+			method: http.MethodGet,
+			url:    "/admin/users/user123",
+			mockSetup: func(mockService *MockAdminService) {
+				mockService.On("GetUser", operatorFor("user123")).Return(&entities.AdminUser{UserID: "user123"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "unknown user",
+			method: http.MethodGet,
+			url:    "/admin/users/ghost",
+			mockSetup: func(mockService *MockAdminService) {
+				mockService.On("GetUser", operatorFor("ghost")).Return(nil, exception.ErrUserNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:   "unlock pin",
+			method: http.MethodPost,
+			url:    "/admin/users/user123/unlock-pin",
+			mockSetup: func(mockService *MockAdminService) {
+				mockService.On("UnlockPin", operatorFor("user123")).Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "support cannot revoke sessions",
+			method:         http.MethodDelete,
+			url:            "/admin/users/user123/sessions/t1",
+			mockSetup:      func(mockService *MockAdminService) {},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "support cannot force a pin reset",
+			method:         http.MethodPost,
+			url:            "/admin/users/user123/force-pin-reset",
+			mockSetup:      func(mockService *MockAdminService) {},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:   "list events",
+			method: http.MethodGet,
+			url:    "/admin/users/user123/events?limit=20",
+			mockSetup: func(mockService *MockAdminService) {
+				mockService.On("ListEvents", operatorFor("user123"), 20).Return([]entities.AuthEvent{}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "events limit out of range",
+			method:         http.MethodGet,
+			url:            "/admin/users/user123/events?limit=500",
+			mockSetup:      func(mockService *MockAdminService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdminService)
+			app := setupTestApp(&adminHandler{service: mockService})
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/Testzyler/banking-api/app/models"
+	"gorm.io/gorm"
+)
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+type AdminRepository interface {
+	GetUser(userID string) (*models.User, error)
+	SetMustChangePin(userID string) error
+}
+
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepository{
+		db: db,
+	}
+}
+
+func (r *adminRepository) GetUser(userID string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("UserPin").Where("user_id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetMustChangePin limits the user's next sign-in to a PIN change.
+func (r *adminRepository) SetMustChangePin(userID string) error {
+	result := r.db.Model(&models.UserPin{}).
+		Where("user_id = ?", userID).
+		Update("must_change_pin", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func TestAdminRepository_GetUser(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE user_id = \\? ORDER BY `users`\\.`user_id` LIMIT \\?").
+		WithArgs("user123", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("user123", "testuser"))
+	mock.ExpectQuery("SELECT \\* FROM `user_pins` WHERE `user_pins`\\.`user_id` = \\?").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "must_change_pin"}).AddRow("user123", true))
+
+	user, err := NewAdminRepository(gormDB).GetUser("user123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", user.Name)
+	assert.True(t, user.UserPin.MustChangePin)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminRepository_SetMustChangePin(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expectError  error
+	}{
+		{name: "flag set", rowsAffected: 1},
+		{name: "user has no PIN", rowsAffected: 0, expectError: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE `user_pins` SET `must_change_pin`=\\? WHERE user_id = \\?").
+				WithArgs(true, "user123").
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			mock.ExpectCommit()
+
+			err := NewAdminRepository(gormDB).SetMustChangePin("user123")
+
+			assert.Equal(t, tt.expectError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/audit"
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/admin/repository"
+	authRepository "github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"gorm.io/gorm"
+)
+
+type adminService struct {
+	repo     repository.AdminRepository
+	authRepo authRepository.AuthRepository
+	auditLog audit.Log
+}
+
+// AdminService lets operators inspect and repair the auth state of a user.
+// Every call is written to the audit log with the operator and its outcome.
+type AdminService interface {
+	GetUser(ctx context.Context, params entities.AdminActionParams) (*entities.AdminUser, error)
+	GetPinAttempts(ctx context.Context, params entities.AdminActionParams) (*entities.PinAttemptData, error)
+	UnlockPin(ctx context.Context, params entities.AdminActionParams) error
+	ListSessions(ctx context.Context, params entities.AdminActionParams) ([]entities.TokenResponse, error)
+	RevokeSession(ctx context.Context, params entities.AdminActionParams, tokenID string) error
+	ForcePinReset(ctx context.Context, params entities.AdminActionParams) error
+	ListEvents(ctx context.Context, params entities.AdminActionParams, limit int) ([]entities.AuthEvent, error)
+}
+
+func NewAdminService(repo repository.AdminRepository, authRepo authRepository.AuthRepository, auditLog audit.Log) AdminService {
+	return &adminService{
+		repo:     repo,
+		authRepo: authRepo,
+		auditLog: auditLog,
+	}
+}
+
+// audited loads the target user, runs action on it and records the outcome.
+// When the entry cannot be written the call fails even if action succeeded,
+// so a read never hands out data that left no trace.
+func (s *adminService) audited(ctx context.Context, params entities.AdminActionParams, name, details string, action func(user *models.User) error) error {
+	user, err := s.repo.GetUser(params.UserID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = exception.ErrUserNotFound
+	case err != nil:
+		err = exception.NewDatabaseError(err)
+	default:
+		err = action(user)
+	}
+
+	event := audit.Failed(entities.AuthEvent{
+		UserID:    params.UserID,
+		Action:    name,
+		ActorID:   params.Operator.UserID,
+		ActorName: params.Operator.Username,
+		Details:   details,
+	}, err)
+	if recordErr := s.auditLog.Record(ctx, event); recordErr != nil {
+		logger.Errorf("Failed to record %s by operator %s: %v", name, params.Operator.UserID, recordErr)
+		if err == nil {
+			return exception.ErrInternalServer
+		}
+	}
+	return err
+}
+
+func (s *adminService) GetUser(ctx context.Context, params entities.AdminActionParams) (*entities.AdminUser, error) {
+	var result *entities.AdminUser
+	err := s.audited(ctx, params, entities.AuthEventAdminUserViewed, "", func(user *models.User) error {
+		roles, err := s.authRepo.GetUserRoles(user.UserID)
+		if err != nil {
+			return exception.NewDatabaseError(err)
+		}
+		attempts := s.pinAttempts(ctx, user)
+		sessions, err := s.authRepo.ListUserTokens(ctx, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		result = &entities.AdminUser{
+			UserID:            user.UserID,
+			Name:              user.Name,
+			Roles:             make([]string, 0, len(roles)),
+			FailedPinAttempts: attempts.FailedAttempts,
+			PinLockedUntil:    attempts.PinLockedUntil,
+			ActiveSessions:    len(sessions),
+		}
+		for _, role := range roles {
+			result.Roles = append(result.Roles, role.Name)
+		}
+		if user.UserPin != nil {
+			result.MustChangePin = user.UserPin.MustChangePin
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *adminService) GetPinAttempts(ctx context.Context, params entities.AdminActionParams) (*entities.PinAttemptData, error) {
+	var attempts *entities.PinAttemptData
+	err := s.audited(ctx, params, entities.AuthEventAdminPinAttemptsViewed, "", func(user *models.User) error {
+		attempts = s.pinAttempts(ctx, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// pinAttempts reads the attempt counter from Redis and falls back to the
+// database copy like the PIN check does.
+func (s *adminService) pinAttempts(ctx context.Context, user *models.User) *entities.PinAttemptData {
+	data, err := s.authRepo.GetPinAttemptData(ctx, user.UserID)
+	if err == nil {
+		return data
+	}
+
+	logger.Warnf("Failed to get pin attempt data for user %s, using database: %v", user.UserID, err)
+	data = &entities.PinAttemptData{UserID: user.UserID}
+	if user.UserPin != nil {
+		data.FailedAttempts = user.UserPin.FailedPinAttempts
+		data.PinLockedUntil = user.UserPin.PinLockedUntil
+		data.LastAttemptAt = user.UserPin.LastPinAttemptAt
+	}
+	return data
+}
+
+func (s *adminService) UnlockPin(ctx context.Context, params entities.AdminActionParams) error {
+	return s.audited(ctx, params, entities.AuthEventAdminPinUnlocked, "", func(user *models.User) error {
+		if err := s.authRepo.ResetPinAttempts(ctx, user.UserID); err != nil {
+			return exception.NewInternalError(err)
+		}
+
+		logger.Infof("Operator %s (%s) unlocked the PIN of user %s", params.Operator.UserID, params.Operator.Username, user.UserID)
+		return nil
+	})
+}
+
+// ListSessions returns the user's sessions without the token strings, an
+// operator must never be able to act as the user.
+func (s *adminService) ListSessions(ctx context.Context, params entities.AdminActionParams) ([]entities.TokenResponse, error) {
+	var sessions []entities.TokenResponse
+	err := s.audited(ctx, params, entities.AuthEventAdminSessionsViewed, "", func(user *models.User) error {
+		var err error
+		sessions, err = s.authRepo.ListUserTokens(ctx, user.UserID)
+		if err != nil {
+			return err
+		}
+		for i := range sessions {
+			sessions[i].Token = ""
+			sessions[i].RefreshToken = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *adminService) RevokeSession(ctx context.Context, params entities.AdminActionParams, tokenID string) error {
+	return s.audited(ctx, params, entities.AuthEventAdminSessionRevoked, tokenID, func(user *models.User) error {
+		session, err := s.authRepo.GetUserSession(ctx, user.UserID, tokenID)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return exception.ErrSessionNotFound
+		}
+
+		reason := fmt.Sprintf("Revoked by operator %s", params.Operator.UserID)
+		if err := s.authRepo.BanTokens(ctx, user.UserID, reason, session.TokenID, session.RefreshTokenID); err != nil {
+			return err
+		}
+		if session.FamilyID != "" {
+			if err := s.authRepo.RevokeTokenFamily(ctx, user.UserID, session.FamilyID, reason); err != nil {
+				return err
+			}
+		}
+
+		logger.Infof("Operator %s (%s) revoked session %s of user %s", params.Operator.UserID, params.Operator.Username, tokenID, user.UserID)
+		return nil
+	})
+}
+
+// ForcePinReset revokes every session of the user and limits their next
+// sign-in to a PIN change.
+func (s *adminService) ForcePinReset(ctx context.Context, params entities.AdminActionParams) error {
+	return s.audited(ctx, params, entities.AuthEventAdminPinResetForced, "", func(user *models.User) error {
+		if err := s.repo.SetMustChangePin(user.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrUserNotFound
+			}
+			return exception.NewDatabaseError(err)
+		}
+		if err := s.authRepo.InvalidateUserWithPin(ctx, user.Name); err != nil {
+			logger.Errorf("Failed to invalidate cached PIN for user %s: %v", user.UserID, err)
+		}
+
+		reason := fmt.Sprintf("PIN reset forced by operator %s", params.Operator.UserID)
+		if err := s.authRepo.BanAllUserTokens(ctx, user.UserID, reason); err != nil {
+			return err
+		}
+
+		logger.Infof("Operator %s (%s) forced a PIN reset for user %s", params.Operator.UserID, params.Operator.Username, user.UserID)
+		return nil
+	})
+}
+
+func (s *adminService) ListEvents(ctx context.Context, params entities.AdminActionParams, limit int) ([]entities.AuthEvent, error) {
+	var events []entities.AuthEvent
+	err := s.audited(ctx, params, entities.AuthEventAdminEventsViewed, "", func(user *models.User) error {
+		var err error
+		events, err = s.auditLog.Recent(ctx, user.UserID, limit)
+		if err != nil {
+			return exception.NewInternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	authRepository "github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Mock AdminRepository
+type MockAdminRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepository) GetUser(userID string) (*models.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockAdminRepository) SetMustChangePin(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// MockAuthRepository only mocks the methods the admin service uses, calling
+// any other method panics on the nil embedded interface.
+type MockAuthRepository struct {
+	mock.Mock
+	authRepository.AuthRepository
+}
+
+func (m *MockAuthRepository) GetUserRoles(userID string) ([]models.Role, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetPinAttemptData(ctx context.Context, userID string) (*entities.PinAttemptData, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PinAttemptData), args.Error(1)
+}
+
+func (m *MockAuthRepository) ResetPinAttempts(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ListUserTokens(ctx context.Context, userID string) ([]entities.TokenResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetUserSession(ctx context.Context, userID, tokenID string) (*entities.TokenResponse, error) {
+	args := m.Called(ctx, userID, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthRepository) BanTokens(ctx context.Context, userID, reason string, tokenIDs ...string) error {
+	args := m.Called(ctx, userID, reason, tokenIDs)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RevokeTokenFamily(ctx context.Context, userID, familyID, reason string) error {
+	args := m.Called(ctx, userID, familyID, reason)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) BanAllUserTokens(ctx context.Context, userID, reason string) error {
+	args := m.Called(ctx, userID, reason)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) InvalidateUserWithPin(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+// Mock audit Log
+type MockAuditLog struct {
+	mock.Mock
+}
+
+func (m *MockAuditLog) Record(ctx context.Context, event entities.AuthEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditLog) Recent(ctx context.Context, userID string, limit int) ([]entities.AuthEvent, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.AuthEvent), args.Error(1)
+}
+
+var testParams = entities.AdminActionParams{
+	Operator: entities.Claims{UserID: "admin1", Username: "operator"},
+	UserID:   "user123",
+}
+
+func testUser() *models.User {
+	return &models.User{
+		UserID:  "user123",
+		Name:    "testuser",
+		UserPin: &models.UserPin{UserID: "user123", FailedPinAttempts: 1},
+	}
+}
+
+// recorded matches the audit event of a successful operator action.
+func recorded(action, details string) interface{} {
+	return mock.MatchedBy(func(event entities.AuthEvent) bool {
+		return event.UserID == "user123" &&
+			event.Action == action &&
+			event.Outcome == "" &&
+			event.ActorID == "admin1" &&
+			event.ActorName == "operator" &&
+			event.Details == details
+	})
+}
+
+// failed matches the audit event of a failed operator action.
+func failed(action string) interface{} {
+	return mock.MatchedBy(func(event entities.AuthEvent) bool {
+		return event.UserID == "user123" &&
+			event.Action == action &&
+			event.Outcome == entities.AuthEventOutcomeFailure &&
+			event.ActorID == "admin1"
+	})
+}
+
+func setupService() (*adminService, *MockAdminRepository, *MockAuthRepository, *MockAuditLog) {
+	logger.Logger = zap.NewNop().Sugar()
+	repo := new(MockAdminRepository)
+	authRepo := new(MockAuthRepository)
+	auditLog := new(MockAuditLog)
+	return NewAdminService(repo, authRepo, auditLog).(*adminService), repo, authRepo, auditLog
+}
+
+func TestAdminService_GetUser(t *testing.T) {
+	service, repo, authRepo, auditLog := setupService()
+	lockedUntil := time.Now().Add(time.Minute)
+
+	repo.On("GetUser", "user123").Return(testUser(), nil)
+	auditLog.On("Record", mock.Anything, recorded(entities.AuthEventAdminUserViewed, "")).Return(nil)
+	authRepo.On("GetUserRoles", "user123").Return([]models.Role{{Name: entities.RoleSupport}}, nil)
+	authRepo.On("GetPinAttemptData", mock.Anything, "user123").
+		Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 4, PinLockedUntil: &lockedUntil}, nil)
+	authRepo.On("ListUserTokens", mock.Anything, "user123").Return([]entities.TokenResponse{{TokenID: "t1"}}, nil)
+
+	user, err := service.GetUser(context.Background(), testParams)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entities.RoleSupport}, user.Roles)
+	assert.Equal(t, 4, user.FailedPinAttempts)
+	assert.Equal(t, &lockedUntil, user.PinLockedUntil)
+	assert.Equal(t, 1, user.ActiveSessions)
+	repo.AssertExpectations(t)
+	authRepo.AssertExpectations(t)
+	auditLog.AssertExpectations(t)
+}
+
+func TestAdminService_Audited(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupMocks  func(*MockAdminRepository, *MockAuthRepository, *MockAuditLog)
+		expectError error
+	}{
+		{
+			name: "unknown user recorded as failed",
+			setupMocks: func(repo *MockAdminRepository, authRepo *MockAuthRepository, auditLog *MockAuditLog) {
+				repo.On("GetUser", "user123").Return(nil, gorm.ErrRecordNotFound)
+				auditLog.On("Record", mock.Anything, failed(entities.AuthEventAdminPinUnlocked)).Return(nil)
+			},
+			expectError: exception.ErrUserNotFound,
+		},
+		{
+			name: "failed action recorded as failed",
+			setupMocks: func(repo *MockAdminRepository, authRepo *MockAuthRepository, auditLog *MockAuditLog) {
+				repo.On("GetUser", "user123").Return(testUser(), nil)
+				authRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(errors.New("connection refused"))
+				auditLog.On("Record", mock.Anything, failed(entities.AuthEventAdminPinUnlocked)).Return(nil)
+			},
+			expectError: exception.NewInternalError(errors.New("connection refused")),
+		},
+		{
+			name: "unrecorded action reported as error",
+			setupMocks: func(repo *MockAdminRepository, authRepo *MockAuthRepository, auditLog *MockAuditLog) {
+				repo.On("GetUser", "user123").Return(testUser(), nil)
+				authRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+				auditLog.On("Record", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+			},
+			expectError: exception.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, authRepo, auditLog := setupService()
+			tt.setupMocks(repo, authRepo, auditLog)
+
+			err := service.UnlockPin(context.Background(), testParams)
+
+			assert.Equal(t, tt.expectError, err)
+			repo.AssertExpectations(t)
+			authRepo.AssertExpectations(t)
+			auditLog.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminService_UnlockPin(t *testing.T) {
+	service, repo, authRepo, auditLog := setupService()
+
+	repo.On("GetUser", "user123").Return(testUser(), nil)
+	auditLog.On("Record", mock.Anything, recorded(entities.AuthEventAdminPinUnlocked, "")).Return(nil)
+	authRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+
+	err := service.UnlockPin(context.Background(), testParams)
+
+	assert.NoError(t, err)
+	authRepo.AssertExpectations(t)
+	auditLog.AssertExpectations(t)
+}
+
+func TestAdminService_ListSessions(t *testing.T) {
+	service, repo, authRepo, auditLog := setupService()
+
+	repo.On("GetUser", "user123").Return(testUser(), nil)
+	auditLog.On("Record", mock.Anything, recorded(entities.AuthEventAdminSessionsViewed, "")).Return(nil)
+	authRepo.On("ListUserTokens", mock.Anything, "user123").
+		Return([]entities.TokenResponse{{TokenID: "t1", Token: "access", RefreshToken: "refresh"}}, nil)
+
+	sessions, err := service.ListSessions(context.Background(), testParams)
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "t1", sessions[0].TokenID)
+	assert.Empty(t, sessions[0].Token)
+	assert.Empty(t, sessions[0].RefreshToken)
+}
+
+func TestAdminService_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupMocks  func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name: "session and its family revoked",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetUserSession", mock.Anything, "user123", "t1").
+					Return(&entities.TokenResponse{TokenID: "t1", RefreshTokenID: "r1", FamilyID: "f1"}, nil)
+				authRepo.On("BanTokens", mock.Anything, "user123", "Revoked by operator admin1", []string{"t1", "r1"}).Return(nil)
+				authRepo.On("RevokeTokenFamily", mock.Anything, "user123", "f1", "Revoked by operator admin1").Return(nil)
+			},
+		},
+		{
+			name: "unknown session",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetUserSession", mock.Anything, "user123", "t1").Return(nil, nil)
+			},
+			expectError: exception.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, authRepo, auditLog := setupService()
+			repo.On("GetUser", "user123").Return(testUser(), nil)
+			event := recorded(entities.AuthEventAdminSessionRevoked, "t1")
+			if tt.expectError != nil {
+				event = failed(entities.AuthEventAdminSessionRevoked)
+			}
+			auditLog.On("Record", mock.Anything, event).Return(nil)
+			tt.setupMocks(authRepo)
+
+			err := service.RevokeSession(context.Background(), testParams, "t1")
+
+			assert.Equal(t, tt.expectError, err)
+			authRepo.AssertExpectations(t)
+			auditLog.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminService_ForcePinReset(t *testing.T) {
+	service, repo, authRepo, auditLog := setupService()
+
+	repo.On("GetUser", "user123").Return(testUser(), nil)
+	auditLog.On("Record", mock.Anything, recorded(entities.AuthEventAdminPinResetForced, "")).Return(nil)
+	repo.On("SetMustChangePin", "user123").Return(nil)
+	authRepo.On("InvalidateUserWithPin", mock.Anything, "testuser").Return(nil)
+	authRepo.On("BanAllUserTokens", mock.Anything, "user123", "PIN reset forced by operator admin1").Return(nil)
+
+	err := service.ForcePinReset(context.Background(), testParams)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	authRepo.AssertExpectations(t)
+	auditLog.AssertExpectations(t)
+}
+
+func TestAdminService_ListEvents(t *testing.T) {
+	service, repo, _, auditLog := setupService()
+	events := []entities.AuthEvent{{ID: "event1", UserID: "user123", Action: entities.AuthEventSignIn}}
+
+	repo.On("GetUser", "user123").Return(testUser(), nil)
+	auditLog.On("Record", mock.Anything, recorded(entities.AuthEventAdminEventsViewed, "")).Return(nil)
+	auditLog.On("Recent", mock.Anything, "user123", 20).Return(events, nil)
+
+	result, err := service.ListEvents(context.Background(), testParams, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, events, result)
+	auditLog.AssertExpectations(t)
+}
@@ -10,19 +10,36 @@ import (
 )
 
 type authHandler struct {
-	service service.AuthService
+	service          service.AuthService
+	pinResetService  service.PinResetService
+	twoFactorService service.TwoFactorService
 }
 
-func NewAuthHandler(router fiber.Router, service service.AuthService) {
+func NewAuthHandler(router fiber.Router, service service.AuthService, pinResetService service.PinResetService, twoFactorService service.TwoFactorService) {
 	handler := &authHandler{
-		service: service,
+		service:          service,
+		pinResetService:  pinResetService,
+		twoFactorService: twoFactorService,
 	}
 
 	auth := router.Group("/auth")
-	auth.Post("/verify-pin", handler.VerifyPin)
-	auth.Post("/refresh", handler.RefreshToken)
+	auth.Post("/verify-pin", middlewares.RateLimitMiddleware("verify-pin"), handler.VerifyPin)
+	auth.Post("/refresh", middlewares.RateLimitMiddleware("refresh"), handler.RefreshToken)
 	auth.Get("/tokens", middlewares.AuthMiddleware(), handler.ListUserTokens)
-	auth.Post("/ban-tokens", middlewares.AuthMiddleware(), handler.BanAllUserTokens)
+	auth.Post("/ban-tokens", middlewares.AuthMiddleware(), middlewares.RequireTwoFactor(), middlewares.IdempotencyMiddleware(), handler.BanAllUserTokens)
+	auth.Post("/admin/ban-tokens", middlewares.AuthMiddleware(), middlewares.RequireTwoFactor(), middlewares.RequireScope(entities.ScopeTokensBan), middlewares.IdempotencyMiddleware(), handler.AdminBanUserTokens)
+	auth.Delete("/tokens/:tokenID", middlewares.AuthMiddleware(), handler.RevokeSession)
+	auth.Get("/devices", middlewares.AuthMiddleware(), handler.ListDevices)
+	auth.Delete("/devices/:deviceID", middlewares.AuthMiddleware(), handler.RevokeDevice)
+	auth.Post("/logout", middlewares.AuthMiddleware(middlewares.AllowTokenPurpose(entities.TokenPurposeChangePin)), handler.Logout)
+	auth.Post("/introspect", middlewares.ServiceAuthMiddleware(), handler.Introspect)
+	auth.Post("/reauth", middlewares.AuthMiddleware(), middlewares.RateLimitMiddleware("reauth"), handler.Reauthenticate)
+	auth.Post("/change-pin", middlewares.AuthMiddleware(middlewares.AllowTokenPurpose(entities.TokenPurposeChangePin)), handler.ChangePin)
+	auth.Post("/pin-reset/start", middlewares.RateLimitMiddleware("pin-reset"), handler.StartPinReset)
+	auth.Post("/pin-reset/complete", middlewares.RateLimitMiddleware("pin-reset"), handler.CompletePinReset)
+	auth.Post("/2fa/enroll", middlewares.AuthMiddleware(), middlewares.RateLimitMiddleware("two-factor"), handler.EnrollTwoFactor)
+	auth.Post("/2fa/confirm", middlewares.AuthMiddleware(), middlewares.RateLimitMiddleware("two-factor"), handler.ConfirmTwoFactor)
+	auth.Post("/2fa/verify", middlewares.AuthMiddleware(), middlewares.RateLimitMiddleware("two-factor"), handler.VerifyTwoFactor)
 }
 
 func (h *authHandler) ListUserTokens(c *fiber.Ctx) error {
@@ -48,6 +65,94 @@ func (h *authHandler) ListUserTokens(c *fiber.Ctx) error {
 	})
 }
 
+func (h *authHandler) RevokeSession(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	if err := h.service.RevokeSession(c.Context(), user.UserID, c.Params("tokenID")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Session revoked successfully",
+	})
+}
+
+func (h *authHandler) ListDevices(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	devices, err := h.service.ListDevices(c.Context(), user.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Devices retrieved successfully",
+		Data:    devices,
+	})
+}
+
+func (h *authHandler) RevokeDevice(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	if err := h.service.RevokeDevice(c.Context(), user.UserID, c.Params("deviceID")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Device revoked successfully",
+	})
+}
+
+func (h *authHandler) Logout(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	if err := h.service.Logout(c.Context(), user); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Logged out successfully",
+	})
+}
+
+// Introspect answers RFC 7662 token introspection requests from internal
+// services. The response is the bare introspection object, not wrapped in a
+// SuccessResponse.
+func (h *authHandler) Introspect(c *fiber.Ctx) error {
+	var params entities.IntrospectParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	introspection, err := h.service.IntrospectToken(c.Context(), params.Token)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(introspection)
+}
+
 func (h *authHandler) VerifyPin(c *fiber.Ctx) error {
 	var params entities.PinVerifyParams
 	if err := c.BodyParser(&params); err != nil {
@@ -70,6 +175,184 @@ func (h *authHandler) VerifyPin(c *fiber.Ctx) error {
 	})
 }
 
+func (h *authHandler) ChangePin(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var params entities.ChangePinParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	tokenResponse, err := h.service.ChangePin(c.Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN changed successfully",
+		Data:    tokenResponse,
+	})
+}
+
+func (h *authHandler) Reauthenticate(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var params entities.ReauthParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	tokenResponse, err := h.service.Reauthenticate(c.Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN re-verified successfully",
+		Data:    tokenResponse,
+	})
+}
+
+func (h *authHandler) StartPinReset(c *fiber.Ctx) error {
+	var params entities.PinResetStartParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.pinResetService.StartPinReset(c.Context(), params); err != nil {
+		return err
+	}
+
+	// Same response whether or not the user exists
+	return c.Status(fiber.StatusAccepted).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "If the user exists, a reset code has been sent",
+	})
+}
+
+func (h *authHandler) CompletePinReset(c *fiber.Ctx) error {
+	var params entities.PinResetCompleteParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.pinResetService.CompletePinReset(c.Context(), params); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "PIN reset successfully",
+	})
+}
+
+func (h *authHandler) EnrollTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var params entities.TwoFactorEnrollParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(c.Context(), user.UserID, user.Username, params)
+	if err != nil {
+		return err
+	}
+
+	// The secret and recovery codes are shown only once
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Two-factor enrollment started",
+		Data:    enrollment,
+	})
+}
+
+func (h *authHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var params entities.TwoFactorConfirmParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	tokenResponse, err := h.twoFactorService.Confirm(c.Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Two-factor authentication enabled",
+		Data:    tokenResponse,
+	})
+}
+
+func (h *authHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var params entities.TwoFactorVerifyParams
+	if err := c.BodyParser(&params); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	tokenResponse, err := h.twoFactorService.Verify(c.Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Two-factor code verified successfully",
+		Data:    tokenResponse,
+	})
+}
+
 func (h *authHandler) RefreshToken(c *fiber.Ctx) error {
 	var req entities.RefreshTokenRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -80,7 +363,7 @@ func (h *authHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	tokenResponse, err := h.service.RefreshToken(req.RefreshToken)
+	tokenResponse, err := h.service.RefreshToken(c.Context(), req.RefreshToken)
 	if err != nil {
 		if errorResponse, ok := err.(*response.ErrorResponse); ok {
 			return c.Status(errorResponse.HttpStatusCode).JSON(errorResponse)
@@ -100,24 +383,51 @@ func (h *authHandler) RefreshToken(c *fiber.Ctx) error {
 	})
 }
 
+// BanAllUserTokens bans every token of the caller. The body may repeat the
+// caller's own user ID; banning other users goes through AdminBanUserTokens.
 func (h *authHandler) BanAllUserTokens(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
+	var req entities.BanTokensRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return exception.ErrValidationFailed
+		}
+	}
+	if req.UserID != "" && req.UserID != user.UserID {
+		return exception.ErrInsufficientPermissions
+	}
+
+	if err := h.service.BanToken(c.Context(), user.UserID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Token banned successfully",
+	})
+}
+
+func (h *authHandler) AdminBanUserTokens(c *fiber.Ctx) error {
+	operator, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrUnauthorized
+	}
+
 	var req entities.BanTokensRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse{
-			Code:    response.ErrCodeBadRequest,
-			Message: "User ID is required",
-		})
+		return exception.ErrValidationFailed
 	}
 
-	if err := h.service.BanToken(c.Context(), req.UserID); err != nil {
-		if errorResponse, ok := err.(*response.ErrorResponse); ok {
-			return c.Status(errorResponse.HttpStatusCode).JSON(errorResponse)
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse{
-			Code:    response.ErrCodeInternalServer,
-			Message: "Failed to ban token",
-			Details: err.Error(),
-		})
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.service.AdminBanTokens(c.Context(), operator, req.UserID); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse{
@@ -5,19 +5,25 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
 	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
 	"github.com/Testzyler/banking-api/server/middlewares"
 	"github.com/Testzyler/banking-api/server/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // Mock AuthService
@@ -33,7 +39,7 @@ func (m *MockAuthService) VerifyPin(ctx context.Context, params entities.PinVeri
 	return args.Get(0).(*entities.TokenResponse), args.Error(1)
 }
 
-func (m *MockAuthService) RefreshToken(refreshToken string) (*entities.TokenResponse, error) {
+func (m *MockAuthService) RefreshToken(ctx context.Context, refreshToken string) (*entities.TokenResponse, error) {
 	args := m.Called(refreshToken)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -54,6 +60,105 @@ func (m *MockAuthService) BanToken(ctx context.Context, userID string) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthService) AdminBanTokens(ctx context.Context, operator entities.Claims, userID string) error {
+	args := m.Called(operator, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ConfirmPin(ctx context.Context, userID, username, pin string) error {
+	args := m.Called(ctx, userID, username, pin)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangePin(ctx context.Context, claims entities.Claims, params entities.ChangePinParams) (*entities.TokenResponse, error) {
+	args := m.Called(claims, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Reauthenticate(ctx context.Context, claims entities.Claims, params entities.ReauthParams) (*entities.TokenResponse, error) {
+	args := m.Called(claims, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, claims entities.Claims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, tokenID string) error {
+	args := m.Called(userID, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) IntrospectToken(ctx context.Context, token string) (*entities.TokenIntrospection, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenIntrospection), args.Error(1)
+}
+
+func (m *MockAuthService) ListDevices(ctx context.Context, userID string) ([]entities.Device, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Device), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeDevice(ctx context.Context, userID, deviceID string) error {
+	args := m.Called(userID, deviceID)
+	return args.Error(0)
+}
+
+type MockPinResetService struct {
+	mock.Mock
+}
+
+func (m *MockPinResetService) StartPinReset(ctx context.Context, params entities.PinResetStartParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockPinResetService) CompletePinReset(ctx context.Context, params entities.PinResetCompleteParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Enroll(ctx context.Context, userID, username string, params entities.TwoFactorEnrollParams) (*entities.TwoFactorEnrollment, error) {
+	args := m.Called(userID, username, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TwoFactorEnrollment), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(ctx context.Context, claims entities.Claims, params entities.TwoFactorConfirmParams) (*entities.TokenResponse, error) {
+	args := m.Called(claims, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Verify(ctx context.Context, claims entities.Claims, params entities.TwoFactorVerifyParams) (*entities.TokenResponse, error) {
+	args := m.Called(claims, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
 func setupTestApp() *fiber.App {
 	// Initialize logger for tests to prevent nil pointer panics
 	Logger := zap.NewNop().Sugar()
@@ -393,6 +498,8 @@ func TestAuthHandler_ListUserTokens(t *testing.T) {
 }
 
 func TestAuthHandler_BanAllUserTokens(t *testing.T) {
+	caller := entities.Claims{UserID: "user123", Username: "testuser", TwoFactor: true}
+
 	tests := []struct {
 		name           string
 		requestBody    interface{}
@@ -412,29 +519,34 @@ func TestAuthHandler_BanAllUserTokens(t *testing.T) {
 			expectSuccess:  true,
 		},
 		{
-			name: "empty userID",
+			name: "empty userID bans the caller",
 			requestBody: entities.BanTokensRequest{
 				UserID: "",
 			},
 			mockSetup: func(mockService *MockAuthService) {
-				mockService.On("BanToken", mock.Anything, "").Return(nil)
+				mockService.On("BanToken", mock.Anything, "user123").Return(nil)
 			},
 			expectedStatus: fiber.StatusOK,
 			expectSuccess:  true,
 		},
 		{
-			name: "service error - user not found",
+			name:        "no body bans the caller",
+			requestBody: nil,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("BanToken", mock.Anything, "user123").Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectSuccess:  true,
+		},
+		{
+			name: "another user is forbidden",
 			requestBody: entities.BanTokensRequest{
-				UserID: "nonexistent",
+				UserID: "user456",
 			},
 			mockSetup: func(mockService *MockAuthService) {
-				mockService.On("BanToken", mock.Anything, "nonexistent").Return(&response.ErrorResponse{
-					HttpStatusCode: fiber.StatusNotFound,
-					Code:           response.ErrCodeNotFound,
-					Message:        "User not found",
-				})
+				// Other users can only be banned through the admin endpoint
 			},
-			expectedStatus: fiber.StatusNotFound,
+			expectedStatus: fiber.StatusForbidden,
 			expectSuccess:  false,
 		},
 		{
@@ -454,7 +566,7 @@ func TestAuthHandler_BanAllUserTokens(t *testing.T) {
 			mockSetup: func(mockService *MockAuthService) {
 				// No mock calls expected for invalid JSON
 			},
-			expectedStatus: fiber.StatusBadRequest,
+			expectedStatus: fiber.StatusUnprocessableEntity,
 			expectSuccess:  false,
 		},
 	}
@@ -467,7 +579,10 @@ func TestAuthHandler_BanAllUserTokens(t *testing.T) {
 
 			// Create handler
 			handler := &authHandler{service: mockService}
-			app.Post("/auth/ban-tokens", handler.BanAllUserTokens)
+			app.Post("/auth/ban-tokens", func(c *fiber.Ctx) error {
+				c.Locals("user", caller)
+				return handler.BanAllUserTokens(c)
+			})
 
 			// Setup mock expectations
 			tt.mockSetup(mockService)
@@ -500,6 +615,584 @@ func TestAuthHandler_BanAllUserTokens(t *testing.T) {
 	}
 }
 
+// stubTotpEnrollments returns the same enrollment for every user.
+type stubTotpEnrollments struct {
+	totp *models.UserTotp
+	err  error
+}
+
+func (s stubTotpEnrollments) GetUserTotp(userID string) (*models.UserTotp, error) {
+	return s.totp, s.err
+}
+
+func TestAuthHandler_BanAllUserTokens_TwoFactor(t *testing.T) {
+	confirmedAt := time.Now()
+
+	tests := []struct {
+		name           string
+		caller         entities.Claims
+		enrollments    stubTotpEnrollments
+		expectBan      bool
+		expectedStatus int
+	}{
+		{
+			name:           "user without enrollment bans with a PIN session",
+			caller:         entities.Claims{UserID: "user123", Username: "testuser"},
+			enrollments:    stubTotpEnrollments{err: gorm.ErrRecordNotFound},
+			expectBan:      true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "pending enrollment does not require a second factor",
+			caller:         entities.Claims{UserID: "user123", Username: "testuser"},
+			enrollments:    stubTotpEnrollments{totp: &models.UserTotp{UserID: "user123"}},
+			expectBan:      true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "enrolled user needs a second factor session",
+			caller:         entities.Claims{UserID: "user123", Username: "testuser"},
+			enrollments:    stubTotpEnrollments{totp: &models.UserTotp{UserID: "user123", ConfirmedAt: &confirmedAt}},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "enrolled user with a second factor session",
+			caller:         entities.Claims{UserID: "user123", Username: "testuser", TwoFactor: true},
+			enrollments:    stubTotpEnrollments{totp: &models.UserTotp{UserID: "user123", ConfirmedAt: &confirmedAt}},
+			expectBan:      true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "enrollment lookup failure",
+			caller:         entities.Claims{UserID: "user123", Username: "testuser"},
+			enrollments:    stubTotpEnrollments{err: errors.New("database error")},
+			expectedStatus: fiber.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			if tt.expectBan {
+				mockService.On("BanToken", mock.Anything, "user123").Return(nil)
+			}
+
+			handler := &authHandler{service: mockService}
+			app.Post("/auth/ban-tokens",
+				func(c *fiber.Ctx) error {
+					c.Locals("user", tt.caller)
+					return c.Next()
+				},
+				middlewares.RequireTwoFactorFor(tt.enrollments),
+				handler.BanAllUserTokens,
+			)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/auth/ban-tokens", nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == fiber.StatusForbidden {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), fmt.Sprintf(`"code":%d`, response.ErrCodeTwoFactorRequired))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_AdminBanUserTokens(t *testing.T) {
+	operator := entities.Claims{UserID: "admin1", Username: "admin", Roles: []string{entities.RoleAdmin}, Scopes: []string{entities.ScopeTokensBan}}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name:        "bans another user",
+			requestBody: `{"userID":"user456"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("AdminBanTokens", operator, "user456").Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "missing userID",
+			requestBody:    `{}`,
+			mockSetup:      func(mockService *MockAuthService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			handler := &authHandler{service: mockService}
+			app.Post("/auth/admin/ban-tokens", func(c *fiber.Ctx) error {
+				c.Locals("user", operator)
+				return handler.AdminBanUserTokens(c)
+			})
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/ban-tokens", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_ChangePin(t *testing.T) {
+	caller := entities.Claims{UserID: "user123", Username: "testuser", DeviceID: "device-1"}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name:        "successful change",
+			requestBody: `{"currentPin":"135790","newPin":"724159"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				params := entities.ChangePinParams{CurrentPin: "135790", NewPin: "724159"}
+				mockService.On("ChangePin", caller, params).
+					Return(&entities.TokenResponse{Token: "new-access", UserID: "user123"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "new pin not six digits",
+			requestBody:    `{"currentPin":"135790","newPin":"12ab"}`,
+			mockSetup:      func(mockService *MockAuthService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:        "weak pin",
+			requestBody: `{"currentPin":"135790","newPin":"111111"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("ChangePin", caller, mock.Anything).
+					Return(nil, exception.NewWeakPinError("repeated digits"))
+			},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:        "wrong current pin",
+			requestBody: `{"currentPin":"000000","newPin":"724159"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("ChangePin", caller, mock.Anything).
+					Return(nil, exception.NewInvalidPinError(2))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			handler := &authHandler{service: mockService}
+			app.Post("/auth/change-pin", func(c *fiber.Ctx) error {
+				c.Locals("user", caller)
+				return handler.ChangePin(c)
+			})
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/change-pin", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_PinReset(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    string
+		mockSetup      func(*MockPinResetService)
+		expectedStatus int
+	}{
+		{
+			name:        "start",
+			path:        "/auth/pin-reset/start",
+			requestBody: `{"username":"testuser"}`,
+			mockSetup: func(mockService *MockPinResetService) {
+				mockService.On("StartPinReset", entities.PinResetStartParams{Username: "testuser"}).Return(nil)
+			},
+			expectedStatus: fiber.StatusAccepted,
+		},
+		{
+			name:           "start without username",
+			path:           "/auth/pin-reset/start",
+			requestBody:    `{}`,
+			mockSetup:      func(mockService *MockPinResetService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:        "complete",
+			path:        "/auth/pin-reset/complete",
+			requestBody: `{"username":"testuser","code":"482913","newPin":"724159"}`,
+			mockSetup: func(mockService *MockPinResetService) {
+				params := entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "724159"}
+				mockService.On("CompletePinReset", params).Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:        "complete with wrong code",
+			path:        "/auth/pin-reset/complete",
+			requestBody: `{"username":"testuser","code":"000000","newPin":"724159"}`,
+			mockSetup: func(mockService *MockPinResetService) {
+				mockService.On("CompletePinReset", mock.Anything).Return(exception.ErrInvalidResetCode)
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "complete with malformed code",
+			path:           "/auth/pin-reset/complete",
+			requestBody:    `{"username":"testuser","code":"12","newPin":"724159"}`,
+			mockSetup:      func(mockService *MockPinResetService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockPinResetService)
+			handler := &authHandler{pinResetService: mockService}
+			app.Post("/auth/pin-reset/start", handler.StartPinReset)
+			app.Post("/auth/pin-reset/complete", handler.CompletePinReset)
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_Reauthenticate(t *testing.T) {
+	session := entities.Claims{UserID: "user123", Username: "testuser", TwoFactor: true, AuthTime: 1725175440}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name:        "pin re-verified",
+			requestBody: `{"pin":"135790"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("Reauthenticate", session, entities.ReauthParams{Pin: "135790"}).
+					Return(&entities.TokenResponse{Token: "access", TokenID: "token-2"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:        "wrong pin",
+			requestBody: `{"pin":"000000"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("Reauthenticate", session, mock.Anything).Return(nil, exception.NewInvalidPinError(2))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "missing pin",
+			requestBody:    `{}`,
+			mockSetup:      func(mockService *MockAuthService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			handler := &authHandler{service: mockService}
+			app.Post("/auth/reauth", func(c *fiber.Ctx) error {
+				c.Locals("user", session)
+				return handler.Reauthenticate(c)
+			})
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/reauth", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_Sessions(t *testing.T) {
+	session := entities.Claims{UserID: "user123", Username: "testuser", TokenID: "token-1", RefreshTokenID: "refresh-1"}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		mockSetup      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name:   "logout",
+			method: http.MethodPost,
+			path:   "/auth/logout",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("Logout", session).Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "revoke session",
+			method: http.MethodDelete,
+			path:   "/auth/tokens/token-2",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("RevokeSession", "user123", "token-2").Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "revoke unknown session",
+			method: http.MethodDelete,
+			path:   "/auth/tokens/unknown",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("RevokeSession", "user123", "unknown").Return(exception.ErrSessionNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:   "list devices",
+			method: http.MethodGet,
+			path:   "/auth/devices",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("ListDevices", "user123").Return([]entities.Device{{DeviceID: "device-1", Name: "Pixel 8", Platform: "android"}}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "revoke device",
+			method: http.MethodDelete,
+			path:   "/auth/devices/device-1",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("RevokeDevice", "user123", "device-1").Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "revoke unknown device",
+			method: http.MethodDelete,
+			path:   "/auth/devices/unknown",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("RevokeDevice", "user123", "unknown").Return(exception.ErrDeviceNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			handler := &authHandler{service: mockService}
+			app.Get("/auth/devices", func(c *fiber.Ctx) error {
+				c.Locals("user", session)
+				return handler.ListDevices(c)
+			})
+			app.Delete("/auth/devices/:deviceID", func(c *fiber.Ctx) error {
+				c.Locals("user", session)
+				return handler.RevokeDevice(c)
+			})
+			app.Post("/auth/logout", func(c *fiber.Ctx) error {
+				c.Locals("user", session)
+				return handler.Logout(c)
+			})
+			app.Delete("/auth/tokens/:tokenID", func(c *fiber.Ctx) error {
+				c.Locals("user", session)
+				return handler.RevokeSession(c)
+			})
+			tt.mockSetup(mockService)
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_Introspect(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		mockSetup      func(*MockAuthService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "active token",
+			contentType: fiber.MIMEApplicationForm,
+			body:        "token=access-token&token_type_hint=access_token",
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("IntrospectToken", "access-token").Return(&entities.TokenIntrospection{Active: true, Sub: "user123", Jti: "token-1", TokenType: "Bearer"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   `{"active":true,"sub":"user123","token_type":"Bearer","jti":"token-1"}`,
+		},
+		{
+			name:        "revoked token",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"token":"access-token"}`,
+			mockSetup: func(mockService *MockAuthService) {
+				mockService.On("IntrospectToken", "access-token").Return(&entities.TokenIntrospection{RevocationReason: "token_revoked"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   `{"active":false,"revocation_reason":"token_revoked"}`,
+		},
+		{
+			name:           "missing token",
+			contentType:    fiber.MIMEApplicationForm,
+			body:           "token_type_hint=access_token",
+			mockSetup:      func(mockService *MockAuthService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockAuthService)
+			handler := &authHandler{service: mockService}
+			app.Post("/auth/introspect", handler.Introspect)
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/introspect", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+				assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_TwoFactor(t *testing.T) {
+	caller := entities.Claims{UserID: "user123", Username: "testuser"}
+
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    string
+		mockSetup      func(*MockTwoFactorService)
+		expectedStatus int
+	}{
+		{
+			name:        "enroll",
+			path:        "/auth/2fa/enroll",
+			requestBody: `{"pin":"135790"}`,
+			mockSetup: func(mockService *MockTwoFactorService) {
+				mockService.On("Enroll", "user123", "testuser", entities.TwoFactorEnrollParams{Pin: "135790"}).
+					Return(&entities.TwoFactorEnrollment{Secret: "SECRET", RecoveryCodes: []string{"abcde-fghij"}}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "enroll without pin",
+			path:           "/auth/2fa/enroll",
+			requestBody:    `{}`,
+			mockSetup:      func(mockService *MockTwoFactorService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:        "confirm",
+			path:        "/auth/2fa/confirm",
+			requestBody: `{"code":"287082"}`,
+			mockSetup: func(mockService *MockTwoFactorService) {
+				mockService.On("Confirm", caller, entities.TwoFactorConfirmParams{Code: "287082"}).
+					Return(&entities.TokenResponse{Token: "2fa_token", TwoFactor: true}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:        "verify with recovery code",
+			path:        "/auth/2fa/verify",
+			requestBody: `{"recoveryCode":"abcde-fghij"}`,
+			mockSetup: func(mockService *MockTwoFactorService) {
+				mockService.On("Verify", caller, entities.TwoFactorVerifyParams{RecoveryCode: "abcde-fghij"}).
+					Return(&entities.TokenResponse{Token: "2fa_token", TwoFactor: true}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:        "verify with wrong code",
+			path:        "/auth/2fa/verify",
+			requestBody: `{"code":"000000"}`,
+			mockSetup: func(mockService *MockTwoFactorService) {
+				mockService.On("Verify", caller, mock.Anything).Return(nil, exception.ErrInvalidTwoFactorCode)
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "verify without any code",
+			path:           "/auth/2fa/verify",
+			requestBody:    `{}`,
+			mockSetup:      func(mockService *MockTwoFactorService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := new(MockTwoFactorService)
+			handler := &authHandler{twoFactorService: mockService}
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("user", caller)
+				return c.Next()
+			})
+			app.Post("/auth/2fa/enroll", handler.EnrollTwoFactor)
+			app.Post("/auth/2fa/confirm", handler.ConfirmTwoFactor)
+			app.Post("/auth/2fa/verify", handler.VerifyTwoFactor)
+			tt.mockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAuthHandler_RefreshToken_AdvancedCases(t *testing.T) {
 	tests := []struct {
 		name           string
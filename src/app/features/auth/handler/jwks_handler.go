@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/Testzyler/banking-api/app/signing"
+	"github.com/gofiber/fiber/v2"
+)
+
+type jwksHandler struct {
+	keys *signing.KeySet
+}
+
+// NewJwksHandler publishes the public keys of the access token signing keys
+// so that other services can verify our tokens without a shared secret.
+func NewJwksHandler(router fiber.Router, keys *signing.KeySet) {
+	handler := &jwksHandler{keys: keys}
+	router.Get("/.well-known/jwks.json", handler.GetJwks)
+}
+
+func (h *jwksHandler) GetJwks(c *fiber.Ctx) error {
+	// Verifiers may cache the keys, so a new key is published before it signs
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.keys.JWKS())
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/signing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJwksHandler_GetJwks(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	key, err := signing.ParseKey("key-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	keys, err := signing.NewKeySet("key-1", []*signing.Key{key})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		keys         *signing.KeySet
+		expectedKids []string
+	}{
+		{name: "published keys", keys: keys, expectedKids: []string{"key-1"}},
+		{name: "hs256 only", keys: nil, expectedKids: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			NewJwksHandler(app, tt.keys)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "public, max-age=300", resp.Header.Get(fiber.HeaderCacheControl))
+
+			var jwks signing.JWKS
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+			kids := []string{}
+			for _, jwk := range jwks.Keys {
+				assert.NotEmpty(t, jwk.X)
+				kids = append(kids, jwk.Kid)
+			}
+			assert.Equal(t, tt.expectedKids, kids)
+		})
+	}
+}
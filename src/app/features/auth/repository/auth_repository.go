@@ -28,13 +28,39 @@ type AuthRepository interface {
 	ListUserTokens(ctx context.Context, userID string) ([]entities.TokenResponse, error)
 	StoreToken(ctx context.Context, userID string, tokenResponse *entities.TokenResponse) error
 	BanAllUserTokens(ctx context.Context, userID, reason string) error
+	BanTokens(ctx context.Context, userID, reason string, tokenIDs ...string) error
+	GetUserSession(ctx context.Context, userID, tokenID string) (*entities.TokenResponse, error)
+	UseRefreshToken(ctx context.Context, tokenID string, ttl time.Duration) (bool, error)
+	RevokeTokenFamily(ctx context.Context, userID, familyID, reason string) error
 	IsTokenBanned(ctx context.Context, tokenID string) (bool, error)
 	IsInBlacklist(ctx context.Context, userID string, tokenVersion int64) (bool, error)
 	ValidateTokenVersion(ctx context.Context, tokenVersion int64) (*entities.TokenValidationResult, error)
 	CleanupExpiredBans(ctx context.Context) error
+	InvalidateUserWithPin(ctx context.Context, username string) error
+	ClearPinAttempts(ctx context.Context, userID string) error
+	IncrementPinResetRequests(ctx context.Context, username string, window time.Duration) (int64, error)
+	IncrementPinResetAttempts(ctx context.Context, userID string, ttl time.Duration) (int64, error)
+	GetPinReset(ctx context.Context, userID string) (*entities.PinResetData, error)
+	SavePinReset(ctx context.Context, data *entities.PinResetData) error
+	DeletePinReset(ctx context.Context, userID string) error
+	IncrementTwoFactorAttempts(ctx context.Context, userID string, window time.Duration) (int64, error)
+	ResetTwoFactorAttempts(ctx context.Context, userID string) error
 
 	// database
 	GetUserWithPin(username string) (*models.User, error)
+	GetPinHistory(userID string, limit int) ([]models.UserPinHistory, error)
+	UpdatePin(userID, hashedPin, previousHashedPin string, historySize int) error
+	GetUserTotp(userID string) (*models.UserTotp, error)
+	SaveTotpEnrollment(totp *models.UserTotp, recoveryCodeHashes []string) error
+	ConfirmUserTotp(userID string, step int64) error
+	UpdateTotpLastUsedStep(userID string, step int64) (bool, error)
+	GetUnusedRecoveryCodes(userID string) ([]models.UserRecoveryCode, error)
+	UseRecoveryCode(id uint) (bool, error)
+	GetUserRoles(userID string) ([]models.Role, error)
+	GetUserDevice(userID, deviceID string) (*models.UserDevice, error)
+	SaveUserDevice(device *models.UserDevice) error
+	ListUserDevices(userID string) ([]models.UserDevice, error)
+	RevokeUserDevice(userID, deviceID string) error
 	UpdateUserPinFailedAttempts(userID string, failedAttempts int) error
 	UpdateUserPinLockedUntil(userID string, lockedUntil *time.Time) error
 	UpdateUserPinLastAttemptAt(userID string, lastAttemptAt *time.Time) error
@@ -69,6 +95,34 @@ func (r *authRepository) userTokensKey(userID string) string {
 	return fmt.Sprintf("user_tokens:%s", userID)
 }
 
+func (r *authRepository) tokenFamilyKey(familyID string) string {
+	return fmt.Sprintf("token_family:%s", familyID)
+}
+
+func (r *authRepository) usedRefreshTokenKey(tokenID string) string {
+	return fmt.Sprintf("used_refresh_token:%s", tokenID)
+}
+
+func (r *authRepository) pinResetKey(userID string) string {
+	return fmt.Sprintf("pin_reset:%s", userID)
+}
+
+func (r *authRepository) pinResetRequestsKey(username string) string {
+	return fmt.Sprintf("pin_reset_requests:%s", username)
+}
+
+func (r *authRepository) pinResetAttemptsKey(userID string) string {
+	return fmt.Sprintf("pin_reset_attempts:%s", userID)
+}
+
+func (r *authRepository) twoFactorAttemptsKey(userID string) string {
+	return fmt.Sprintf("two_factor_attempts:%s", userID)
+}
+
+func (r *authRepository) userWithPinKey(username string) string {
+	return fmt.Sprintf("user_with_pin:%s", username)
+}
+
 func (r *authRepository) invalidateUserCacheByID(userID string) {
 	if r.redisClient == nil {
 		return
@@ -81,7 +135,7 @@ func (r *authRepository) GetUserWithPin(username string) (*models.User, error) {
 	ctx := context.Background()
 
 	if r.redisClient != nil {
-		cacheKey := fmt.Sprintf("user_with_pin:%s", username)
+		cacheKey := r.userWithPinKey(username)
 		result, err := r.redisClient.Get(ctx, cacheKey).Result()
 		if err == nil {
 			var user models.User
@@ -105,7 +159,7 @@ func (r *authRepository) GetUserWithPin(username string) (*models.User, error) {
 	// Store in Redis cache for future requests (async)
 	if r.redisClient != nil {
 		go func() {
-			cacheKey := fmt.Sprintf("user_with_pin:%s", username)
+			cacheKey := r.userWithPinKey(username)
 			userJSON, err := json.Marshal(user)
 			if err == nil {
 				_ = r.redisClient.Set(ctx, cacheKey, string(userJSON), 30*time.Minute).Err()
@@ -116,6 +170,309 @@ func (r *authRepository) GetUserWithPin(username string) (*models.User, error) {
 	return &user, nil
 }
 
+// InvalidateUserWithPin drops the cached user so the next lookup reads the
+// current PIN hash from the database.
+func (r *authRepository) InvalidateUserWithPin(ctx context.Context, username string) error {
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, r.userWithPinKey(username)).Err()
+}
+
+// ClearPinAttempts removes the failed attempt counter and any lock.
+func (r *authRepository) ClearPinAttempts(ctx context.Context, userID string) error {
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, r.pinAttemptKey(userID)).Err()
+}
+
+// IncrementPinResetRequests counts reset codes requested for the username,
+// whether or not it exists, within a fixed window that starts with the first
+// request.
+func (r *authRepository) IncrementPinResetRequests(ctx context.Context, username string, window time.Duration) (int64, error) {
+	count, err := r.incrementWindowCounter(ctx, r.pinResetRequestsKey(username), window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pin reset requests: %w", err)
+	}
+	return count, nil
+}
+
+// IncrementPinResetAttempts counts the codes tried against the pending reset.
+// The counter is atomic so parallel guesses cannot share a count, and it lives
+// as long as the code; saving or deleting the reset starts it over.
+func (r *authRepository) IncrementPinResetAttempts(ctx context.Context, userID string, ttl time.Duration) (int64, error) {
+	count, err := r.incrementWindowCounter(ctx, r.pinResetAttemptsKey(userID), ttl)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pin reset attempts: %w", err)
+	}
+	return count, nil
+}
+
+// IncrementTwoFactorAttempts counts TOTP and recovery codes the user tried
+// within a fixed window that starts with the first attempt.
+func (r *authRepository) IncrementTwoFactorAttempts(ctx context.Context, userID string, window time.Duration) (int64, error) {
+	count, err := r.incrementWindowCounter(ctx, r.twoFactorAttemptsKey(userID), window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count two-factor attempts: %w", err)
+	}
+	return count, nil
+}
+
+func (r *authRepository) ResetTwoFactorAttempts(ctx context.Context, userID string) error {
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, r.twoFactorAttemptsKey(userID)).Err()
+}
+
+func (r *authRepository) incrementWindowCounter(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if r.redisClient == nil {
+		return 0, fmt.Errorf("Redis client is not initialized")
+	}
+
+	count, err := r.redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.redisClient.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// GetPinReset returns the pending reset, or nil when there is none or it
+// has expired.
+func (r *authRepository) GetPinReset(ctx context.Context, userID string) (*entities.PinResetData, error) {
+	if r.redisClient == nil {
+		return nil, fmt.Errorf("Redis client is not initialized")
+	}
+
+	result, err := r.redisClient.Get(ctx, r.pinResetKey(userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pin reset from Redis: %w", err)
+	}
+
+	var data entities.PinResetData
+	if err := json.Unmarshal([]byte(result), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pin reset: %w", err)
+	}
+	return &data, nil
+}
+
+// SavePinReset stores the reset until data.ExpiresAt, replacing any earlier
+// one along with the attempts made against it.
+func (r *authRepository) SavePinReset(ctx context.Context, data *entities.PinResetData) error {
+	if r.redisClient == nil {
+		return fmt.Errorf("Redis client is not initialized")
+	}
+
+	ttl := time.Until(data.ExpiresAt)
+	if ttl <= 0 {
+		return r.DeletePinReset(ctx, data.UserID)
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pin reset: %w", err)
+	}
+	_, err = r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, r.pinResetKey(data.UserID), string(jsonData), ttl)
+		pipe.Del(ctx, r.pinResetAttemptsKey(data.UserID))
+		return nil
+	})
+	return err
+}
+
+func (r *authRepository) DeletePinReset(ctx context.Context, userID string) error {
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, r.pinResetKey(userID), r.pinResetAttemptsKey(userID)).Err()
+}
+
+// GetPinHistory returns the most recent previous PIN hashes, newest first.
+func (r *authRepository) GetPinHistory(userID string, limit int) ([]models.UserPinHistory, error) {
+	var history []models.UserPinHistory
+	if limit <= 0 {
+		return history, nil
+	}
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&history).Error
+	return history, err
+}
+
+// UpdatePin replaces the PIN hash, clears any forced change, moves the
+// previous hash into the history and trims the history to historySize
+// entries in one transaction.
+func (r *authRepository) UpdatePin(userID, hashedPin, previousHashedPin string, historySize int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserPin{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]interface{}{
+				"hashed_pin":          hashedPin,
+				"failed_pin_attempts": 0,
+				"pin_locked_until":    nil,
+				"last_pin_attempt_at": nil,
+				"must_change_pin":     false,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if historySize <= 0 {
+			return tx.Where("user_id = ?", userID).Delete(&models.UserPinHistory{}).Error
+		}
+
+		if err := tx.Create(&models.UserPinHistory{UserID: userID, HashedPin: previousHashedPin}).Error; err != nil {
+			return err
+		}
+
+		var ids []uint
+		if err := tx.Model(&models.UserPinHistory{}).
+			Where("user_id = ?", userID).
+			Order("id DESC").
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) <= historySize {
+			return nil
+		}
+		return tx.Where("id IN ?", ids[historySize:]).Delete(&models.UserPinHistory{}).Error
+	})
+}
+
+func (r *authRepository) GetUserTotp(userID string) (*models.UserTotp, error) {
+	var totp models.UserTotp
+	if err := r.db.Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// SaveTotpEnrollment replaces the user's secret and recovery codes with a new
+// pending enrollment.
+func (r *authRepository) SaveTotpEnrollment(totp *models.UserTotp, recoveryCodeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", totp.UserID).Delete(&models.UserTotp{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", totp.UserID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(totp).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.UserRecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, models.UserRecoveryCode{UserID: totp.UserID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConfirmUserTotp activates a pending enrollment and records the step of the
+// code that confirmed it.
+func (r *authRepository) ConfirmUserTotp(userID string, step int64) error {
+	result := r.db.Model(&models.UserTotp{}).
+		Where("user_id = ? AND confirmed_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateTotpLastUsedStep records that the code for step was used. It returns
+// false when that step or a later one was already used, so every code works
+// only once.
+func (r *authRepository) UpdateTotpLastUsedStep(userID string, step int64) (bool, error) {
+	result := r.db.Model(&models.UserTotp{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *authRepository) GetUnusedRecoveryCodes(userID string) ([]models.UserRecoveryCode, error) {
+	var codes []models.UserRecoveryCode
+	err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	return codes, err
+}
+
+// UseRecoveryCode marks the code as used. It returns false when a concurrent
+// request used it first.
+func (r *authRepository) UseRecoveryCode(id uint) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetUserRoles returns the roles granted to the user with their scopes.
+func (r *authRepository) GetUserRoles(userID string) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Scopes").
+		Joins("JOIN user_roles ON user_roles.role_name = roles.name").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *authRepository) GetUserDevice(userID, deviceID string) (*models.UserDevice, error) {
+	var device models.UserDevice
+	if err := r.db.Where("user_id = ? AND device_id = ?", userID, deviceID).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// SaveUserDevice inserts a new device or updates a known one, including
+// clearing the revocation of a device that registers again.
+func (r *authRepository) SaveUserDevice(device *models.UserDevice) error {
+	return r.db.Save(device).Error
+}
+
+// ListUserDevices returns the devices of the user that are not revoked.
+func (r *authRepository) ListUserDevices(userID string) ([]models.UserDevice, error) {
+	var devices []models.UserDevice
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("last_seen_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+func (r *authRepository) RevokeUserDevice(userID, deviceID string) error {
+	return r.db.Model(&models.UserDevice{}).
+		Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userID, deviceID).
+		Update("revoked_at", time.Now()).Error
+}
+
 func (r *authRepository) GetPinAttemptData(ctx context.Context, userID string) (*entities.PinAttemptData, error) {
 	if r.redisClient == nil {
 		return &entities.PinAttemptData{UserID: userID, FailedAttempts: 0}, nil
@@ -320,6 +677,22 @@ func (r *authRepository) BanAllUserTokens(ctx context.Context, userID, reason st
 	return nil
 }
 
+// BanTokens bans the given tokens only, e.g. the two tokens of one session.
+// Empty IDs are skipped.
+func (r *authRepository) BanTokens(ctx context.Context, userID, reason string, tokenIDs ...string) error {
+	for _, tokenID := range tokenIDs {
+		if tokenID == "" {
+			continue
+		}
+		if err := r.banToken(ctx, userID, tokenID, reason); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Banned %d tokens for user %s: %s", len(tokenIDs), userID, reason)
+	return nil
+}
+
 func (r *authRepository) banToken(ctx context.Context, userID, tokenID, reason string) error {
 	if r.redisClient == nil {
 		return fmt.Errorf("Redis client is not initialized")
@@ -332,7 +705,6 @@ func (r *authRepository) banToken(ctx context.Context, userID, tokenID, reason s
 		Reason:       reason,
 		TokenVersion: time.Now().Unix(), // Use current timestamp as version
 	}
-	r.invalidateUserCacheByID(userID)
 	bannedKey := r.bannedTokenKey(tokenID)
 	bannedData, err := json.Marshal(bannedToken)
 	if err != nil {
@@ -457,7 +829,7 @@ func (r *authRepository) StoreToken(ctx context.Context, userID string, tokenRes
 
 	// Set expiry for the key based on token expiry
 	if !tokenResponse.Expiry.IsZero() {
-		ttl := time.Until(tokenResponse.Expiry)
+		ttl := time.Until(sessionExpiry(*tokenResponse))
 		if ttl > 0 {
 			if err := r.redisClient.Expire(ctx, key, ttl).Err(); err != nil {
 				logger.Warnf("Failed to set expiry for token key %s: %v", key, err)
@@ -465,9 +837,99 @@ func (r *authRepository) StoreToken(ctx context.Context, userID string, tokenRes
 		}
 	}
 
+	if tokenResponse.FamilyID != "" {
+		if err := r.addToTokenFamily(ctx, tokenResponse); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// addToTokenFamily records the tokens of a response under their family, so
+// that RevokeTokenFamily can ban every token issued since the sign-in.
+func (r *authRepository) addToTokenFamily(ctx context.Context, tokenResponse *entities.TokenResponse) error {
+	key := r.tokenFamilyKey(tokenResponse.FamilyID)
+	members := []interface{}{tokenResponse.TokenID}
+	if tokenResponse.RefreshTokenID != "" {
+		members = append(members, tokenResponse.RefreshTokenID)
+	}
+	if err := r.redisClient.SAdd(ctx, key, members...).Err(); err != nil {
+		return fmt.Errorf("failed to store token family in Redis: %w", err)
+	}
+
+	// Keep the family while one of its refresh tokens can still be used
+	if ttl := time.Until(sessionExpiry(*tokenResponse)); ttl > 0 {
+		if err := r.redisClient.Expire(ctx, key, ttl).Err(); err != nil {
+			logger.Warnf("Failed to set expiry for token family key %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// UseRefreshToken marks a refresh token as used. It returns false when the
+// token was used before, which means it was replayed. ttl should cover the
+// remaining lifetime of the token.
+func (r *authRepository) UseRefreshToken(ctx context.Context, tokenID string, ttl time.Duration) (bool, error) {
+	if r.redisClient == nil {
+		return false, fmt.Errorf("Redis client is not initialized")
+	}
+
+	first, err := r.redisClient.SetNX(ctx, r.usedRefreshTokenKey(tokenID), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+	return first, nil
+}
+
+// RevokeTokenFamily bans every access and refresh token issued from the same
+// sign-in.
+func (r *authRepository) RevokeTokenFamily(ctx context.Context, userID, familyID, reason string) error {
+	if r.redisClient == nil {
+		return fmt.Errorf("Redis client is not initialized")
+	}
+
+	tokenIDs, err := r.redisClient.SMembers(ctx, r.tokenFamilyKey(familyID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get token family from Redis: %w", err)
+	}
+
+	return r.BanTokens(ctx, userID, reason, tokenIDs...)
+}
+
+func (r *authRepository) isRefreshTokenUsed(ctx context.Context, tokenID string) (bool, error) {
+	count, err := r.redisClient.Exists(ctx, r.usedRefreshTokenKey(tokenID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetUserSession returns the stored session whose access token is tokenID, or
+// nil when the user has no such session.
+func (r *authRepository) GetUserSession(ctx context.Context, userID, tokenID string) (*entities.TokenResponse, error) {
+	if r.redisClient == nil {
+		return nil, fmt.Errorf("Redis client is not initialized")
+	}
+
+	tokens, err := r.redisClient.SMembers(ctx, r.userTokensKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user tokens from Redis: %w", err)
+	}
+
+	for _, tokenStr := range tokens {
+		var tokenResponse entities.TokenResponse
+		if err := json.Unmarshal([]byte(tokenStr), &tokenResponse); err != nil {
+			logger.Errorf("Failed to unmarshal token for user %s: %v", userID, err)
+			continue
+		}
+		if tokenResponse.TokenID == tokenID {
+			return &tokenResponse, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *authRepository) ListUserTokens(ctx context.Context, userID string) ([]entities.TokenResponse, error) {
 	if r.redisClient == nil {
 		return nil, fmt.Errorf("Redis client is not initialized")
@@ -504,13 +966,34 @@ func (r *authRepository) ListUserTokens(ctx context.Context, userID string) ([]e
 					isTokenBanned = false
 				}
 
+				isRefreshBanned := false
+				if tokenResponse.RefreshTokenID != "" {
+					isRefreshBanned, err = r.IsTokenBanned(ctx, tokenResponse.RefreshTokenID)
+					if err != nil {
+						logger.Errorf("Failed to check if token %s is banned: %v", tokenResponse.RefreshTokenID, err)
+						isRefreshBanned = false
+					}
+				}
+
 				isUserBanned, err := r.IsInBlacklist(ctx, userID, tokenResponse.TokenVersion)
 				if err != nil {
 					logger.Errorf("Failed to check user ban status for user %s: %v", userID, err)
 					isUserBanned = false
 				}
-				isBanned := isUserBanned || isTokenBanned
+				// A rotated session lives on in the entry of its newer tokens
+				isRotated := false
+				if tokenResponse.RefreshTokenID != "" {
+					isRotated, err = r.isRefreshTokenUsed(ctx, tokenResponse.RefreshTokenID)
+					if err != nil {
+						logger.Errorf("Failed to check if token %s was used: %v", tokenResponse.RefreshTokenID, err)
+						isRotated = false
+					}
+				}
+
+				isBanned := isUserBanned || isTokenBanned || isRefreshBanned
+				isActive := !isBanned && !isRotated && time.Now().Before(sessionExpiry(tokenResponse))
 				tokenResponse.IsBanned = &isBanned
+				tokenResponse.IsActive = &isActive
 				allTokenResponses = append(allTokenResponses, tokenResponse)
 			}
 		}
@@ -524,6 +1007,15 @@ func (r *authRepository) ListUserTokens(ctx context.Context, userID string) ([]e
 	return allTokenResponses, nil
 }
 
+// sessionExpiry is when the session ends: the refresh token expiry when it
+// has one, otherwise the access token expiry.
+func sessionExpiry(tokenResponse entities.TokenResponse) time.Time {
+	if tokenResponse.RefreshExpiry != nil && tokenResponse.RefreshExpiry.After(tokenResponse.Expiry) {
+		return *tokenResponse.RefreshExpiry
+	}
+	return tokenResponse.Expiry
+}
+
 // Legacy database-only methods for fallback
 func (r *authRepository) UpdateUserPinFailedAttempts(userID string, failedAttempts int) error {
 	return r.db.Model(&models.UserPin{}).Where("user_id = ?", userID).Update("failed_pin_attempts", failedAttempts).Error
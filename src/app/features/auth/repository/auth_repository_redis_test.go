@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -300,6 +301,21 @@ func TestAuthRepository_StoreToken_WithRedismock(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:   "tokens join their family",
+			userID: "user123",
+			token: &entities.TokenResponse{
+				TokenID:        "token123",
+				RefreshTokenID: "refresh123",
+				FamilyID:       "family123",
+				Token:          "jwt_token_here",
+			},
+			setupMock: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSAdd("user_tokens:user123", `.*`).SetVal(1)
+				mock.ExpectSAdd("token_family:family123", "token123", "refresh123").SetVal(2)
+			},
+			expectError: false,
+		},
 		{
 			name:   "token storage failure",
 			userID: "user123",
@@ -862,6 +878,63 @@ func TestAuthRepository_ListUserTokens_WithRedismock(t *testing.T) {
 	}
 }
 
+func TestAuthRepository_BanTokens_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	mock.Regexp().ExpectSet("banned_token:token-1", `.*`, 24*time.Hour).SetVal("OK")
+	mock.Regexp().ExpectSet("banned_token:refresh-1", `.*`, 24*time.Hour).SetVal("OK")
+
+	// the empty refresh token ID of an older session is skipped, and the
+	// other sessions stay listed
+	assert.NoError(t, repo.BanTokens(context.Background(), "user123", "Logged out", "token-1", "refresh-1", ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_GetUserSession_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	mock.ExpectSMembers("user_tokens:user123").SetVal([]string{
+		`{"tokenID":"token-1","refreshTokenID":"refresh-1"}`,
+		`{"tokenID":"token-2","refreshTokenID":"refresh-2"}`,
+	})
+	mock.ExpectSMembers("user_tokens:user123").SetVal([]string{`{"tokenID":"token-1"}`})
+
+	session, err := repo.GetUserSession(context.Background(), "user123", "token-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh-2", session.RefreshTokenID)
+
+	session, err = repo.GetUserSession(context.Background(), "user123", "token-2")
+	assert.NoError(t, err)
+	assert.Nil(t, session)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_TokenFamily_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+	ctx := context.Background()
+
+	mock.Regexp().ExpectSetNX("used_refresh_token:refresh-1", `.*`, time.Hour).SetVal(true)
+	mock.Regexp().ExpectSetNX("used_refresh_token:refresh-1", `.*`, time.Hour).SetVal(false)
+	mock.ExpectSMembers("token_family:family-1").SetVal([]string{"token-1", "refresh-1"})
+	mock.Regexp().ExpectSet("banned_token:token-1", `.*`, 24*time.Hour).SetVal("OK")
+	mock.Regexp().ExpectSet("banned_token:refresh-1", `.*`, 24*time.Hour).SetVal("OK")
+
+	first, err := repo.UseRefreshToken(ctx, "refresh-1", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, first)
+
+	// a second use is reported as a replay
+	first, err = repo.UseRefreshToken(ctx, "refresh-1", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, first)
+
+	assert.NoError(t, repo.RevokeTokenFamily(ctx, "user123", "family-1", "Refresh token reused"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAuthRepository_CleanupExpiredBans_WithRedismock(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1056,3 +1129,99 @@ func TestAuthRepository_SetPinLock_NilRedis(t *testing.T) {
 	// Verify SQL expectations were met
 	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
+
+func TestAuthRepository_IncrementPinResetRequests_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	// the window starts with the first request only
+	mock.ExpectIncr("pin_reset_requests:testuser").SetVal(1)
+	mock.ExpectExpire("pin_reset_requests:testuser", time.Hour).SetVal(true)
+	mock.ExpectIncr("pin_reset_requests:testuser").SetVal(2)
+
+	count, err := repo.IncrementPinResetRequests(context.Background(), "testuser", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = repo.IncrementPinResetRequests(context.Background(), "testuser", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_GetPinReset_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	stored := entities.PinResetData{UserID: "user123", CodeHash: "hash", ExpiresAt: time.Now().Add(time.Minute).UTC()}
+	storedJSON, _ := json.Marshal(stored)
+	mock.ExpectGet("pin_reset:user123").SetVal(string(storedJSON))
+	mock.ExpectGet("pin_reset:user456").RedisNil()
+	mock.ExpectDel("pin_reset:user123", "pin_reset_attempts:user123").SetVal(2)
+	mock.ExpectDel("pin_attempt:user123").SetVal(1)
+
+	reset, err := repo.GetPinReset(context.Background(), "user123")
+	assert.NoError(t, err)
+	assert.Equal(t, stored.CodeHash, reset.CodeHash)
+	assert.True(t, stored.ExpiresAt.Equal(reset.ExpiresAt))
+
+	reset, err = repo.GetPinReset(context.Background(), "user456")
+	assert.NoError(t, err)
+	assert.Nil(t, reset)
+
+	assert.NoError(t, repo.DeletePinReset(context.Background(), "user123"))
+	assert.NoError(t, repo.ClearPinAttempts(context.Background(), "user123"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_PinResetAttempts_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	// parallel guesses each get their own count
+	mock.ExpectIncr("pin_reset_attempts:user123").SetVal(1)
+	mock.ExpectExpire("pin_reset_attempts:user123", 5*time.Minute).SetVal(true)
+	mock.ExpectIncr("pin_reset_attempts:user123").SetVal(2)
+
+	count, err := repo.IncrementPinResetAttempts(context.Background(), "user123", 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = repo.IncrementPinResetAttempts(context.Background(), "user123", 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// a new code starts the count over
+	mock.ExpectTxPipeline()
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		// the stored JSON and TTL depend on the current time
+		if actual[0] != "set" || actual[1] != "pin_reset:user123" {
+			return fmt.Errorf("unexpected command %v", actual)
+		}
+		return nil
+	}).ExpectSet("pin_reset:user123", "", 10*time.Minute).SetVal("OK")
+	mock.ExpectDel("pin_reset_attempts:user123").SetVal(1)
+	mock.ExpectTxPipelineExec()
+
+	assert.NoError(t, repo.SavePinReset(context.Background(), &entities.PinResetData{
+		UserID: "user123", CodeHash: "hash", ExpiresAt: time.Now().Add(10 * time.Minute),
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_TwoFactorAttempts_WithRedismock(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := NewAuthRepository(nil, createTestRedisDB(client))
+
+	mock.ExpectIncr("two_factor_attempts:user123").SetVal(1)
+	mock.ExpectExpire("two_factor_attempts:user123", 15*time.Minute).SetVal(true)
+	mock.ExpectDel("two_factor_attempts:user123").SetVal(1)
+
+	count, err := repo.IncrementTwoFactorAttempts(context.Background(), "user123", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, repo.ResetTwoFactorAttempts(context.Background(), "user123"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -372,3 +372,187 @@ func TestAuthRepository_UpdateUserPinLastAttemptAt(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRepository_UpdatePin(t *testing.T) {
+	tests := []struct {
+		name          string
+		historySize   int
+		mockSetup     func(sqlmock.Sqlmock)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:        "stores previous pin and trims history",
+			historySize: 2,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_pins` SET `failed_pin_attempts`=\\?,`hashed_pin`=\\?,`last_pin_attempt_at`=\\?,`must_change_pin`=\\?,`pin_locked_until`=\\? WHERE user_id = \\?").
+					WithArgs(0, "new-hash", nil, false, nil, "user123").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `user_pin_history` \\(`user_id`,`hashed_pin`,`created_at`\\) VALUES \\(\\?,\\?,\\?\\)").
+					WithArgs("user123", "old-hash", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectQuery("SELECT `id` FROM `user_pin_history` WHERE user_id = \\? ORDER BY id DESC").
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(3).AddRow(1))
+				mock.ExpectExec("DELETE FROM `user_pin_history` WHERE id IN \\(\\?\\)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:        "history within limit",
+			historySize: 5,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_pins`").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `user_pin_history`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT `id` FROM `user_pin_history`").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:        "user has no pin",
+			historySize: 5,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_pins`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectError:   true,
+			errorContains: "record not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			gormDB, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      db,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{})
+			assert.NoError(t, err)
+
+			repo := NewAuthRepository(gormDB, nil)
+			tt.mockSetup(mock)
+
+			err = repo.UpdatePin("user123", "new-hash", "old-hash", tt.historySize)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthRepository_UpdateTotpLastUsedStep(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expected     bool
+	}{
+		{name: "new step", rowsAffected: 1, expected: true},
+		{name: "step already used", rowsAffected: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			gormDB, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      db,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{})
+			assert.NoError(t, err)
+
+			repo := NewAuthRepository(gormDB, nil)
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE `user_totp` SET `last_used_step`=\\?,`updated_at`=\\? WHERE user_id = \\? AND last_used_step < \\?").
+				WithArgs(int64(100), sqlmock.AnyArg(), "user123", int64(100)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			mock.ExpectCommit()
+
+			updated, err := repo.UpdateTotpLastUsedStep("user123", 100)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, updated)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthRepository_UserDevices(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	repo := NewAuthRepository(gormDB, nil)
+
+	mock.ExpectQuery("SELECT \\* FROM `user_devices` WHERE user_id = \\? AND revoked_at IS NULL ORDER BY last_seen_at DESC").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "device_id", "name", "platform", "public_key"}).
+			AddRow(1, "user123", "device-1", "Pixel 8", "android", "a2V5LTE="))
+
+	devices, err := repo.ListUserDevices("user123")
+
+	assert.NoError(t, err)
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "device-1", devices[0].DeviceID)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `user_devices` SET `revoked_at`=\\? WHERE user_id = \\? AND device_id = \\? AND revoked_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), "user123", "device-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RevokeUserDevice("user123", "device-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRepository_GetUserRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	repo := NewAuthRepository(gormDB, nil)
+	mock.ExpectQuery("SELECT `roles`.`name`,`roles`.`description`,`roles`.`created_at` FROM `roles` JOIN user_roles ON user_roles.role_name = roles.name WHERE user_roles.user_id = \\? ORDER BY roles.name").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "description"}).AddRow("support", "Support desk"))
+	mock.ExpectQuery("SELECT \\* FROM `role_scopes` WHERE `role_scopes`.`role_name` = \\?").
+		WithArgs("support").
+		WillReturnRows(sqlmock.NewRows([]string{"role_name", "scope"}).AddRow("support", "users:read"))
+
+	roles, err := repo.GetUserRoles("user123")
+
+	assert.NoError(t, err)
+	assert.Len(t, roles, 1)
+	assert.Equal(t, "support", roles[0].Name)
+	assert.Equal(t, "users:read", roles[0].Scopes[0].Scope)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,15 +2,23 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/Testzyler/banking-api/app/audit"
 	"github.com/Testzyler/banking-api/app/entities"
 	"github.com/Testzyler/banking-api/app/features/auth/repository"
 	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/notifier"
 	"github.com/Testzyler/banking-api/config"
 	"github.com/Testzyler/banking-api/logger"
 	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -19,17 +27,27 @@ type authService struct {
 	config     *config.Config
 	jwtService JwtService
 	repository repository.AuthRepository
+	notifier   notifier.Notifier
 }
 
 type AuthService interface {
 	VerifyPin(ctx context.Context, params entities.PinVerifyParams) (*entities.TokenResponse, error)
-	RefreshToken(refreshToken string) (*entities.TokenResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*entities.TokenResponse, error)
 	ListUserTokens(ctx context.Context, userID string) ([]entities.TokenResponse, error)
 	BanToken(ctx context.Context, userID string) error
+	AdminBanTokens(ctx context.Context, operator entities.Claims, userID string) error
+	ConfirmPin(ctx context.Context, userID, username, pin string) error
+	ChangePin(ctx context.Context, claims entities.Claims, params entities.ChangePinParams) (*entities.TokenResponse, error)
+	Reauthenticate(ctx context.Context, claims entities.Claims, params entities.ReauthParams) (*entities.TokenResponse, error)
+	Logout(ctx context.Context, claims entities.Claims) error
+	RevokeSession(ctx context.Context, userID, tokenID string) error
+	IntrospectToken(ctx context.Context, token string) (*entities.TokenIntrospection, error)
+	ListDevices(ctx context.Context, userID string) ([]entities.Device, error)
+	RevokeDevice(ctx context.Context, userID, deviceID string) error
 }
 
-func NewAuthService(repository repository.AuthRepository, jwtService JwtService, config *config.Config) AuthService {
-	return &authService{repository: repository, jwtService: jwtService, config: config}
+func NewAuthService(repository repository.AuthRepository, jwtService JwtService, notifier notifier.Notifier, config *config.Config) AuthService {
+	return &authService{repository: repository, jwtService: jwtService, notifier: notifier, config: config}
 }
 
 func (s *authService) ListUserTokens(ctx context.Context, userID string) ([]entities.TokenResponse, error) {
@@ -37,18 +55,194 @@ func (s *authService) ListUserTokens(ctx context.Context, userID string) ([]enti
 	if err != nil {
 		return nil, err
 	}
+	s.attachDevices(userID, tokens)
 	return tokens, nil
 }
 
 func (s *authService) VerifyPin(ctx context.Context, params entities.PinVerifyParams) (*entities.TokenResponse, error) {
-	user, err := s.repository.GetUserWithPin(params.Username)
+	user, err := s.checkPin(ctx, params.Username, params.Pin)
+	if err != nil {
+		if s.config.Auth.Pin.UniformFailure && isCredentialError(err) {
+			// do not tell unknown users from wrong or locked PINs
+			return nil, exception.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	deviceID, newDevice, err := s.registerDevice(user.UserID, params.Device)
+	if err != nil {
+		recordEvent(ctx, user.UserID, params.Username, entities.AuthEventSignIn, "", err)
+		return nil, err
+	}
+
+	var tokenResponse *entities.TokenResponse
+	if user.UserPin.MustChangePin {
+		// Users still on a migrated default PIN may only change it
+		tokenResponse, err = s.jwtService.GenerateRestrictedToken(user.UserID, params.Username, entities.TokenPurposeChangePin)
+		if err == nil {
+			tokenResponse.MustChangePin = true
+		}
+	} else {
+		var options entities.TokenOptions
+		options, err = s.newSessionOptions(user.UserID, deviceID)
+		if err == nil {
+			// Generate JWT tokens with token version (timestamp)
+			tokenResponse, err = s.jwtService.GenerateTokensWithOptions(user.UserID, params.Username, options)
+		}
+	}
+	if err != nil {
+		recordEvent(ctx, user.UserID, params.Username, entities.AuthEventSignIn, deviceID, err)
+		return nil, exception.NewInternalError(err)
+	}
+
+	// Store token in Redis for tracking
+	tokenResponse.UserID = user.UserID
+	if err := s.repository.StoreToken(ctx, user.UserID, tokenResponse); err != nil {
+		logger.Errorf("Failed to store token in Redis for user %s: %v", user.UserID, err)
+	}
+
+	if newDevice {
+		s.notifyNewDevice(ctx, user.UserID, params.Username, params.Device)
+	}
+	recordEvent(ctx, user.UserID, params.Username, entities.AuthEventSignIn, deviceID, nil)
+
+	return tokenResponse, nil
+}
+
+// ConfirmPin re-checks the PIN of an already signed-in user before a
+// sensitive change. Wrong PINs count towards the same lockout as VerifyPin.
+func (s *authService) ConfirmPin(ctx context.Context, userID, username, pin string) error {
+	user, err := s.checkPin(ctx, username, pin)
+	if err != nil {
+		return err
+	}
+	if user.UserID != userID {
+		return exception.ErrUnauthorized
+	}
+	return nil
+}
+
+// ChangePin replaces the user's PIN after re-checking the current one. Every
+// existing session is revoked and the caller receives a fresh token pair bound
+// to the same device, so only the device that made the change stays signed in.
+func (s *authService) ChangePin(ctx context.Context, claims entities.Claims, params entities.ChangePinParams) (*entities.TokenResponse, error) {
+	username := claims.Username
+	user, err := s.checkPin(ctx, username, params.CurrentPin)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID != claims.UserID {
+		return nil, exception.ErrUnauthorized
+	}
+
+	if err := replacePin(ctx, s.repository, s.config.Auth.Pin, user, username, params.NewPin, "PIN changed"); err != nil {
+		recordEvent(ctx, user.UserID, username, entities.AuthEventPinChanged, "", err)
+		return nil, err
+	}
+
+	options, err := s.newSessionOptions(user.UserID, claims.DeviceID)
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	tokenResponse, err := s.jwtService.GenerateTokensWithOptions(user.UserID, username, options)
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	tokenResponse.UserID = user.UserID
+	if err := s.repository.StoreToken(ctx, user.UserID, tokenResponse); err != nil {
+		logger.Errorf("Failed to store token in Redis for user %s: %v", user.UserID, err)
+	}
+
+	recordEvent(ctx, user.UserID, username, entities.AuthEventPinChanged, "", nil)
+	logger.Infof("User %s changed their PIN", user.UserID)
+	return tokenResponse, nil
+}
+
+// Reauthenticate re-checks the PIN of a signed-in user and returns a new
+// access token with a fresh auth time. The refresh token of the session is
+// kept and the access token it replaces is banned.
+func (s *authService) Reauthenticate(ctx context.Context, claims entities.Claims, params entities.ReauthParams) (*entities.TokenResponse, error) {
+	if err := s.ConfirmPin(ctx, claims.UserID, claims.Username, params.Pin); err != nil {
+		return nil, err
+	}
+
+	session := claims.TokenOptions()
+	session.AuthTime = time.Now().Unix()
+	tokenResponse, err := s.jwtService.GenerateAccessToken(claims.UserID, claims.Username, claims.RefreshTokenID, session)
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	tokenResponse.UserID = claims.UserID
+	if err := s.repository.StoreToken(ctx, claims.UserID, tokenResponse); err != nil {
+		logger.Errorf("Failed to store token in Redis for user %s: %v", claims.UserID, err)
+	}
+	if err := s.repository.BanTokens(ctx, claims.UserID, "Replaced by reauthentication", claims.TokenID); err != nil {
+		logger.Errorf("Failed to ban replaced token %s for user %s: %v", claims.TokenID, claims.UserID, err)
+	}
+
+	return tokenResponse, nil
+}
+
+// replacePin checks newPin against the weak PIN rules and the PIN history,
+// stores it and revokes every existing session of the user with banReason.
+func replacePin(ctx context.Context, repo repository.AuthRepository, pinConfig *config.PinConfig, user *models.User, username, newPin, banReason string) error {
+	if reason := weakPinReason(newPin, user.BirthDate); reason != "" {
+		return exception.NewWeakPinError(reason)
+	}
+
+	historySize := pinConfig.HistorySize
+	history, err := repo.GetPinHistory(user.UserID, historySize)
+	if err != nil {
+		return exception.NewDatabaseError(err)
+	}
+	if isPinCorrect(user.UserPin.HashedPin, newPin) {
+		return exception.ErrPinReused
+	}
+	for _, previous := range history {
+		if isPinCorrect(previous.HashedPin, newPin) {
+			return exception.ErrPinReused
+		}
+	}
+
+	hashedPin, err := bcrypt.GenerateFromPassword([]byte(newPin), bcrypt.DefaultCost)
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+	if err := repo.UpdatePin(user.UserID, string(hashedPin), user.UserPin.HashedPin, historySize); err != nil {
+		return exception.NewDatabaseError(err)
+	}
+
+	if err := repo.InvalidateUserWithPin(ctx, username); err != nil {
+		logger.Errorf("Failed to invalidate cached PIN for user %s: %v", user.UserID, err)
+	}
+	err = repo.BanAllUserTokens(ctx, user.UserID, banReason)
+	if err != nil {
+		logger.Errorf("Failed to revoke sessions after PIN update for user %s: %v", user.UserID, err)
+	}
+	recordEvent(ctx, user.UserID, username, entities.AuthEventTokensBanned, banReason, err)
+	return nil
+}
+
+// checkPin applies the lockout rules and compares the PIN, resetting the
+// attempt counter on success.
+func (s *authService) checkPin(ctx context.Context, username, pin string) (*models.User, error) {
+	user, err := s.repository.GetUserWithPin(username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if s.config.Auth.Pin.UniformFailure {
+				// run the same lockout, counter and audit work as a wrong PIN
+				// so the response time does not tell the username exists
+				s.comparePin(ctx, unknownUser(username), username, pin)
+			}
 			return nil, exception.ErrUserNotFound
 		}
 		return nil, err
 	}
 
+	return s.comparePin(ctx, user, username, pin)
+}
+
+func (s *authService) comparePin(ctx context.Context, user *models.User, username, pin string) (*models.User, error) {
 	now := time.Now()
 	// Check Redis cache
 	cacheData, err := s.repository.GetPinAttemptData(ctx, user.UserID)
@@ -63,10 +257,16 @@ func (s *authService) VerifyPin(ctx context.Context, params entities.PinVerifyPa
 	}
 
 	if isLocked, remainingTime := isPinLocked(cacheData, now); isLocked {
-		return nil, exception.NewPinLockedError(remainingTime.String())
+		if s.config.Auth.Pin.UniformFailure {
+			// the result is ignored, a locked PIN must not answer faster
+			isPinCorrect(user.UserPin.HashedPin, pin)
+		}
+		err := exception.NewPinLockedError(remainingTime.String())
+		recordEvent(ctx, user.UserID, username, entities.AuthEventPinCheck, "", err)
+		return nil, err
 	}
 
-	if !isPinCorrect(user.UserPin.HashedPin, params.Pin) {
+	if !isPinCorrect(user.UserPin.HashedPin, pin) {
 		return nil, s.handleFailedAttempt(ctx, user, now)
 	}
 
@@ -74,19 +274,7 @@ func (s *authService) VerifyPin(ctx context.Context, params entities.PinVerifyPa
 		logger.Errorf("Failed to reset cache attempts for user %s: %v", user.UserID, err)
 	}
 
-	// Generate JWT tokens with token version (timestamp)
-	tokenResponse, err := s.jwtService.GenerateTokens(user.UserID, params.Username)
-	if err != nil {
-		return nil, exception.NewInternalError(err)
-	}
-
-	// Store token in Redis for tracking
-	tokenResponse.UserID = user.UserID
-	if err := s.repository.StoreToken(ctx, user.UserID, tokenResponse); err != nil {
-		logger.Errorf("Failed to store token in Redis for user %s: %v", user.UserID, err)
-	}
-
-	return tokenResponse, nil
+	return user, nil
 }
 
 func isPinLocked(cacheData *entities.PinAttemptData, now time.Time) (bool, time.Duration) {
@@ -101,11 +289,48 @@ func isPinCorrect(hashedPin, inputPin string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPin), []byte(inputPin)) == nil
 }
 
+// dummyPinHash is compared against for unknown usernames. It uses the cost of
+// stored PINs so the compare takes as long as a real one, and hashes a random
+// value no PIN can match.
+var dummyPinHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte(rand.Text()), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("Failed to hash dummy PIN: %v", err)
+	}
+	return hash
+})
+
+// unknownUserPrefix starts the user ID that attempts on an unknown username
+// are counted and audited under.
+const unknownUserPrefix = "unknown-"
+
+// unknownUser stands in for a username that does not exist. Its ID is derived
+// from the username, so repeated guesses share one attempt counter.
+func unknownUser(username string) *models.User {
+	sum := sha256.Sum256([]byte(username))
+	return &models.User{
+		UserID:  unknownUserPrefix + hex.EncodeToString(sum[:16]),
+		Name:    username,
+		UserPin: &models.UserPin{HashedPin: string(dummyPinHash())},
+	}
+}
+
+// isCredentialError reports whether checkPin refused the username or PIN, as
+// opposed to failing on a repository error.
+func isCredentialError(err error) bool {
+	if errors.Is(err, exception.ErrUserNotFound) {
+		return true
+	}
+	var errResp *response.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Code == response.ErrCodeUnauthorized
+}
+
 func (s *authService) handleFailedAttempt(ctx context.Context, user *models.User, now time.Time) error {
 	cacheData, err := s.repository.IncrementFailedAttempts(ctx, user.UserID)
 	if err != nil {
 		return err
 	}
+	recordEvent(ctx, user.UserID, user.Name, entities.AuthEventPinCheck, fmt.Sprintf("failed attempt %d", cacheData.FailedAttempts), exception.ErrInvalidPin)
 
 	baseDuration := s.config.Auth.Pin.BaseDuration
 	lockThreshold := s.config.Auth.Pin.LockThreshold
@@ -123,9 +348,11 @@ func (s *authService) handleFailedAttempt(ctx context.Context, user *models.User
 		lockedUntil := now.Add(lockDuration)
 
 		// Set lock in Redis immediately, then async DB sync
-		if err := s.repository.SetPinLock(ctx, user.UserID, lockedUntil, cacheData.FailedAttempts, cacheData.LastAttemptAt); err != nil {
+		err := s.repository.SetPinLock(ctx, user.UserID, lockedUntil, cacheData.FailedAttempts, cacheData.LastAttemptAt)
+		if err != nil {
 			logger.Errorf("Failed to set pin lock in cache for user %s: %v", user.UserID, err)
 		}
+		recordEvent(ctx, user.UserID, user.Name, entities.AuthEventPinLocked, fmt.Sprintf("locked for %s", lockDuration), err)
 
 		return exception.NewPinLockedError(lockDuration.String())
 	}
@@ -134,15 +361,14 @@ func (s *authService) handleFailedAttempt(ctx context.Context, user *models.User
 	return exception.NewInvalidPinError(remainingAttempts)
 }
 
-func (s *authService) RefreshToken(refreshToken string) (*entities.TokenResponse, error) {
-	tokenResponse, err := s.jwtService.RefreshAccessToken(refreshToken)
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*entities.TokenResponse, error) {
+	tokenResponse, err := s.jwtService.RefreshAccessToken(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store the new token in Redis for tracking
 	if tokenResponse.UserID != "" {
-		ctx := context.Background()
 		if err := s.repository.StoreToken(ctx, tokenResponse.UserID, tokenResponse); err != nil {
 			logger.Errorf("Failed to store refreshed token in Redis for user %s: %v", tokenResponse.UserID, err)
 		}
@@ -153,10 +379,138 @@ func (s *authService) RefreshToken(refreshToken string) (*entities.TokenResponse
 
 func (s *authService) BanToken(ctx context.Context, userID string) error {
 	reason := "Manually banned by user request"
-	if err := s.repository.BanAllUserTokens(ctx, userID, reason); err != nil {
+	err := s.repository.BanAllUserTokens(ctx, userID, reason)
+	recordEvent(ctx, userID, "", entities.AuthEventTokensBanned, reason, err)
+	if err != nil {
 		return err
 	}
 
 	logger.Infof("User %s has been banned all tokens successfully", userID)
 	return nil
 }
+
+// AdminBanTokens bans every token of another user on behalf of an operator
+// holding the tokens:ban scope.
+func (s *authService) AdminBanTokens(ctx context.Context, operator entities.Claims, userID string) error {
+	reason := fmt.Sprintf("Banned by operator %s", operator.UserID)
+	err := s.repository.BanAllUserTokens(ctx, userID, reason)
+	audit.Record(ctx, audit.Failed(entities.AuthEvent{
+		UserID:    userID,
+		Action:    entities.AuthEventAdminTokensBanned,
+		ActorID:   operator.UserID,
+		ActorName: operator.Username,
+		Details:   reason,
+	}, err))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Operator %s (%s) banned all tokens of user %s", operator.UserID, operator.Username, userID)
+	return nil
+}
+
+// Logout ends the current session by banning its access token and the
+// refresh token issued with it, along with every token rotated from the same
+// sign-in.
+func (s *authService) Logout(ctx context.Context, claims entities.Claims) error {
+	err := s.endSession(ctx, claims.UserID, claims.FamilyID, "Logged out", claims.TokenID, claims.RefreshTokenID)
+	recordEvent(ctx, claims.UserID, claims.Username, entities.AuthEventLogout, claims.TokenID, err)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("User %s logged out of session %s", claims.UserID, claims.TokenID)
+	return nil
+}
+
+// RevokeSession ends one session of the user, chosen by the ID of its access
+// token as shown by ListUserTokens.
+func (s *authService) RevokeSession(ctx context.Context, userID, tokenID string) error {
+	session, err := s.repository.GetUserSession(ctx, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return exception.ErrSessionNotFound
+	}
+
+	err = s.endSession(ctx, userID, session.FamilyID, "Session revoked by user", session.TokenID, session.RefreshTokenID)
+	recordEvent(ctx, userID, "", entities.AuthEventSessionRevoked, tokenID, err)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("User %s revoked session %s", userID, tokenID)
+	return nil
+}
+
+// IntrospectToken reports whether an access token is still active, including
+// the ban state kept in Redis. A token is only active when every check
+// passed; if a ban check could not run the token is reported inactive.
+func (s *authService) IntrospectToken(ctx context.Context, token string) (*entities.TokenIntrospection, error) {
+	result, _ := s.jwtService.ValidateTokenWithBanCheck(token)
+	if result == nil || !result.Valid || result.Reason != "" {
+		return &entities.TokenIntrospection{Active: false, RevocationReason: revocationReason(result)}, nil
+	}
+
+	claims := result.Claims
+	introspection := &entities.TokenIntrospection{
+		Active:    true,
+		Sub:       claims.UserID,
+		Username:  claims.Username,
+		TokenType: "Bearer",
+		Jti:       claims.TokenID,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+		AuthTime:  claims.AuthTime,
+	}
+	if claims.ExpiresAt != nil {
+		introspection.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		introspection.Iat = claims.IssuedAt.Unix()
+	}
+	return introspection, nil
+}
+
+// revocationReason tells why a token that passed signature validation was
+// rejected. Tokens that are malformed, expired or could not be checked have
+// none.
+func revocationReason(result *entities.TokenValidationResult) string {
+	if result == nil || result.Valid {
+		return ""
+	}
+	switch result.Reason {
+	case "token is banned":
+		return "token_revoked"
+	case "token is blacklisted":
+		return "user_tokens_revoked"
+	case "", "invalid token", "Error: auth repository not initialized":
+		return ""
+	}
+	return "token_outdated"
+}
+
+// endSession bans the given tokens and, for sessions that have one, the rest
+// of their refresh token family.
+func (s *authService) endSession(ctx context.Context, userID, familyID, reason string, tokenIDs ...string) error {
+	if err := s.repository.BanTokens(ctx, userID, reason, tokenIDs...); err != nil {
+		return err
+	}
+	if familyID == "" {
+		return nil
+	}
+	return s.repository.RevokeTokenFamily(ctx, userID, familyID, reason)
+}
+
+// recordEvent adds an action the user took on their own account to the
+// audit log, as failed when err is set.
+func recordEvent(ctx context.Context, userID, username, action, details string, err error) {
+	audit.Record(ctx, audit.Failed(entities.AuthEvent{
+		UserID:    userID,
+		Action:    action,
+		ActorID:   userID,
+		ActorName: username,
+		Details:   details,
+	}, err))
+}
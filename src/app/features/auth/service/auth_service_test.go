@@ -6,9 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Testzyler/banking-api/app/audit"
 	"github.com/Testzyler/banking-api/app/entities"
 	"github.com/Testzyler/banking-api/app/models"
 	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
@@ -88,6 +91,29 @@ func (m *MockAuthRepository) BanAllUserTokens(ctx context.Context, userID, reaso
 	return args.Error(0)
 }
 
+func (m *MockAuthRepository) BanTokens(ctx context.Context, userID, reason string, tokenIDs ...string) error {
+	args := m.Called(ctx, userID, reason, tokenIDs)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseRefreshToken(ctx context.Context, tokenID string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, tokenID, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeTokenFamily(ctx context.Context, userID, familyID, reason string) error {
+	args := m.Called(ctx, userID, familyID, reason)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetUserSession(ctx context.Context, userID, tokenID string) (*entities.TokenResponse, error) {
+	args := m.Called(ctx, userID, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
 func (m *MockAuthRepository) IsTokenBanned(ctx context.Context, tokenID string) (bool, error) {
 	args := m.Called(ctx, tokenID)
 	return args.Bool(0), args.Error(1)
@@ -111,6 +137,141 @@ func (m *MockAuthRepository) CleanupExpiredBans(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthRepository) InvalidateUserWithPin(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ClearPinAttempts(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) IncrementPinResetRequests(ctx context.Context, username string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, username, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepository) IncrementPinResetAttempts(ctx context.Context, userID string, ttl time.Duration) (int64, error) {
+	args := m.Called(ctx, userID, ttl)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetPinReset(ctx context.Context, userID string) (*entities.PinResetData, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PinResetData), args.Error(1)
+}
+
+func (m *MockAuthRepository) SavePinReset(ctx context.Context, data *entities.PinResetData) error {
+	args := m.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) DeletePinReset(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetPinHistory(userID string, limit int) ([]models.UserPinHistory, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserPinHistory), args.Error(1)
+}
+
+func (m *MockAuthRepository) UpdatePin(userID, hashedPin, previousHashedPin string, historySize int) error {
+	args := m.Called(userID, hashedPin, previousHashedPin, historySize)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) IncrementTwoFactorAttempts(ctx context.Context, userID string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, userID, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepository) ResetTwoFactorAttempts(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetUserTotp(userID string) (*models.UserTotp, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserTotp), args.Error(1)
+}
+
+func (m *MockAuthRepository) SaveTotpEnrollment(totp *models.UserTotp, recoveryCodeHashes []string) error {
+	args := m.Called(totp, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConfirmUserTotp(userID string, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UpdateTotpLastUsedStep(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetUnusedRecoveryCodes(userID string) ([]models.UserRecoveryCode, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.UserRecoveryCode), args.Error(1)
+}
+
+func (m *MockAuthRepository) UseRecoveryCode(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetUserRoles(userID string) ([]models.Role, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetUserDevice(userID, deviceID string) (*models.UserDevice, error) {
+	args := m.Called(userID, deviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserDevice), args.Error(1)
+}
+
+func (m *MockAuthRepository) SaveUserDevice(device *models.UserDevice) error {
+	args := m.Called(device)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ListUserDevices(userID string) ([]models.UserDevice, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserDevice), args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeUserDevice(userID, deviceID string) error {
+	args := m.Called(userID, deviceID)
+	return args.Error(0)
+}
+
+// freshAuthTime matches token options for a PIN entered just now
+func freshAuthTime() interface{} {
+	return mock.MatchedBy(func(options entities.TokenOptions) bool {
+		return time.Since(time.Unix(options.AuthTime, 0)) < time.Minute
+	})
+}
+
 // Helper function to create test models.User
 func createTestUser(userID, username, hashedPin string, failedAttempts int, lockedUntil, lastAttempt *time.Time) *models.User {
 	return &models.User{
@@ -138,6 +299,30 @@ func (m *MockJwtService) GenerateTokens(userID, username string) (*entities.Toke
 	return args.Get(0).(*entities.TokenResponse), args.Error(1)
 }
 
+func (m *MockJwtService) GenerateTokensWithOptions(userID, username string, options entities.TokenOptions) (*entities.TokenResponse, error) {
+	args := m.Called(userID, username, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockJwtService) GenerateAccessToken(userID, username, refreshTokenID string, options entities.TokenOptions) (*entities.TokenResponse, error) {
+	args := m.Called(userID, username, refreshTokenID, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
+func (m *MockJwtService) GenerateRestrictedToken(userID, username, purpose string) (*entities.TokenResponse, error) {
+	args := m.Called(userID, username, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
 func (m *MockJwtService) ValidateAccessToken(tokenString string) (*entities.Claims, error) {
 	args := m.Called(tokenString)
 	if args.Get(0) == nil {
@@ -154,7 +339,7 @@ func (m *MockJwtService) ValidateRefreshToken(tokenString string) (*entities.Cla
 	return args.Get(0).(*entities.Claims), args.Error(1)
 }
 
-func (m *MockJwtService) RefreshAccessToken(refreshTokenString string) (*entities.TokenResponse, error) {
+func (m *MockJwtService) RefreshAccessToken(ctx context.Context, refreshTokenString string) (*entities.TokenResponse, error) {
 	args := m.Called(refreshTokenString)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -206,12 +391,35 @@ func TestAuthService_VerifyPin(t *testing.T) {
 					RefreshToken: "refresh_token",
 					Expiry:       time.Now().Add(time.Hour),
 				}
-				mockJwt.On("GenerateTokens", "user123", "testuser").Return(tokenResponse, nil)
+				mockRepo.On("GetUserRoles", "user123").Return([]models.Role{}, nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", freshAuthTime()).Return(tokenResponse, nil)
 				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
 			},
 			expectError: false,
 			expectToken: true,
 		},
+		{
+			name: "default pin only gets a change pin token",
+			params: entities.PinVerifyParams{
+				Username: "testuser",
+				Pin:      "123456",
+			},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				user := createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil)
+				user.UserPin.MustChangePin = true
+				mockRepo.On("GetUserWithPin", "testuser").Return(user, nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+
+				mockJwt.On("GenerateRestrictedToken", "user123", "testuser", entities.TokenPurposeChangePin).
+					Return(&entities.TokenResponse{Token: "restricted_token", Expiry: time.Now().Add(5 * time.Minute)}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.MatchedBy(func(tokenResponse *entities.TokenResponse) bool {
+					return tokenResponse.MustChangePin && tokenResponse.RefreshToken == ""
+				})).Return(nil)
+			},
+			expectError: false,
+			expectToken: true,
+		},
 		{
 			name: "user not found",
 			params: entities.PinVerifyParams{
@@ -285,7 +493,7 @@ func TestAuthService_VerifyPin(t *testing.T) {
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo, mockJwt)
@@ -317,6 +525,156 @@ func TestAuthService_VerifyPin(t *testing.T) {
 	}
 }
 
+func TestAuthService_VerifyPin_UniformFailure(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	lockedUntil := time.Now().Add(30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		params      entities.PinVerifyParams
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name:   "unknown user",
+			params: entities.PinVerifyParams{Username: "nonexistent", Pin: "123456"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				unknownID := unknownUser("nonexistent").UserID
+				mockRepo.On("GetUserWithPin", "nonexistent").Return(nil, gorm.ErrRecordNotFound)
+				mockRepo.On("GetPinAttemptData", mock.Anything, unknownID).Return(&entities.PinAttemptData{UserID: unknownID}, nil)
+				mockRepo.On("IncrementFailedAttempts", mock.Anything, unknownID).Return(&entities.PinAttemptData{UserID: unknownID, FailedAttempts: 1}, nil)
+			},
+			expectError: exception.ErrInvalidCredentials,
+		},
+		{
+			name:   "wrong pin hides the remaining attempts",
+			params: entities.PinVerifyParams{Username: "testuser", Pin: "654321"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				user := createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(user, nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("IncrementFailedAttempts", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 1}, nil)
+			},
+			expectError: exception.ErrInvalidCredentials,
+		},
+		{
+			name:   "locked pin",
+			params: entities.PinVerifyParams{Username: "testuser", Pin: "123456"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				user := createTestUser("user123", "testuser", string(hashedPin), 5, &lockedUntil, nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(user, nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 5, PinLockedUntil: &lockedUntil}, nil)
+			},
+			expectError: exception.ErrInvalidCredentials,
+		},
+		{
+			name:   "database errors are not masked",
+			params: entities.PinVerifyParams{Username: "testuser", Pin: "123456"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(nil, errors.New("database connection error"))
+			},
+			expectError: errors.New("database connection error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			tt.mockSetup(mockRepo)
+
+			cfg := &config.Config{
+				Auth: &config.AuthConfig{
+					Pin: &config.PinConfig{
+						BaseDuration:    10 * time.Second,
+						LockThreshold:   3,
+						MaxLockDuration: 300 * time.Second,
+						UniformFailure:  true,
+					},
+				},
+			}
+
+			tokenResponse, err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), cfg).
+				VerifyPin(context.Background(), tt.params)
+
+			assert.Nil(t, tokenResponse)
+			assert.EqualError(t, err, tt.expectError.Error())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// Mock audit Log
+type MockAuditLog struct {
+	mock.Mock
+}
+
+func (m *MockAuditLog) Record(ctx context.Context, event entities.AuthEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditLog) Recent(ctx context.Context, userID string, limit int) ([]entities.AuthEvent, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.AuthEvent), args.Error(1)
+}
+
+func TestAuthService_VerifyPin_UnknownUserRunsSamePath(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	cfg := &config.Config{
+		Auth: &config.AuthConfig{
+			Pin: &config.PinConfig{
+				BaseDuration:    10 * time.Second,
+				LockThreshold:   3,
+				MaxLockDuration: 300 * time.Second,
+				UniformFailure:  true,
+			},
+		},
+	}
+
+	// failedSignIn returns the repository methods and audit actions a wrong
+	// PIN for username ran, with the attempts counted under userID
+	failedSignIn := func(username, userID string, user *models.User, lookupErr error) ([]string, []string) {
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("GetUserWithPin", username).Return(user, lookupErr)
+		mockRepo.On("GetPinAttemptData", mock.Anything, userID).Return(&entities.PinAttemptData{UserID: userID}, nil)
+		mockRepo.On("IncrementFailedAttempts", mock.Anything, userID).Return(&entities.PinAttemptData{UserID: userID, FailedAttempts: 1}, nil)
+		auditLog := new(MockAuditLog)
+		auditLog.On("Record", mock.Anything, mock.MatchedBy(func(event entities.AuthEvent) bool {
+			return event.UserID == userID
+		})).Return(nil)
+		audit.SetDefault(auditLog)
+		defer audit.SetDefault(nil)
+
+		_, err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), cfg).
+			VerifyPin(context.Background(), entities.PinVerifyParams{Username: username, Pin: "654321"})
+
+		assert.Equal(t, exception.ErrInvalidCredentials, err)
+		mockRepo.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+
+		var methods, actions []string
+		for _, call := range mockRepo.Calls {
+			methods = append(methods, call.Method)
+		}
+		for _, call := range auditLog.Calls {
+			actions = append(actions, call.Arguments.Get(1).(entities.AuthEvent).Action)
+		}
+		return methods, actions
+	}
+
+	knownMethods, knownActions := failedSignIn("testuser", "user123",
+		createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+	unknownMethods, unknownActions := failedSignIn("nonexistent", unknownUser("nonexistent").UserID,
+		nil, gorm.ErrRecordNotFound)
+
+	assert.Equal(t, knownMethods, unknownMethods)
+	assert.Equal(t, knownActions, unknownActions)
+	assert.Equal(t, []string{entities.AuthEventPinCheck}, unknownActions)
+}
+
 func TestAuthService_RefreshToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -396,13 +754,13 @@ func TestAuthService_RefreshToken(t *testing.T) {
 			mockJwt := new(MockJwtService)
 
 			config := &config.Config{}
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo, mockJwt)
 
 			// Act
-			tokenResponse, err := service.RefreshToken(tt.refreshToken)
+			tokenResponse, err := service.RefreshToken(context.Background(), tt.refreshToken)
 
 			// Assert
 			if tt.expectError {
@@ -623,7 +981,7 @@ func TestAuthService_VerifyPin_FailedAttempts_And_Locking(t *testing.T) {
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo, mockJwt)
@@ -713,7 +1071,8 @@ func TestAuthService_VerifyPin_AlreadyLocked(t *testing.T) {
 					RefreshToken: "refresh_token",
 					Expiry:       time.Now().Add(time.Hour),
 				}
-				mockJwt.On("GenerateTokens", "user123", "testuser").Return(tokenResponse, nil)
+				mockRepo.On("GetUserRoles", "user123").Return([]models.Role{}, nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", freshAuthTime()).Return(tokenResponse, nil)
 
 				// Mock token storage
 				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
@@ -737,7 +1096,7 @@ func TestAuthService_VerifyPin_AlreadyLocked(t *testing.T) {
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo, mockJwt)
@@ -866,7 +1225,7 @@ func TestAuthService_VerifyPin_RepositoryErrors_During_FailedAttempts(t *testing
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo, mockJwt)
@@ -957,7 +1316,7 @@ func TestAuthService_BanToken(t *testing.T) {
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo)
@@ -982,6 +1341,262 @@ func TestAuthService_BanToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_ConfirmPin(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+
+	tests := []struct {
+		name        string
+		userID      string
+		pin         string
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name:   "correct pin",
+			userID: "user123",
+			pin:    "123456",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+			},
+		},
+		{
+			name:   "wrong pin counts as failed attempt",
+			userID: "user123",
+			pin:    "654321",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("IncrementFailedAttempts", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 1}, nil)
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+		{
+			name:   "token belongs to another user",
+			userID: "other",
+			pin:    "123456",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+			},
+			expectError: exception.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			tt.mockSetup(mockRepo)
+
+			config := &config.Config{
+				Auth: &config.AuthConfig{
+					Pin: &config.PinConfig{
+						BaseDuration:    10 * time.Second,
+						LockThreshold:   3,
+						MaxLockDuration: 300 * time.Second,
+					},
+				},
+			}
+
+			err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), config).ConfirmPin(context.Background(), tt.userID, "testuser", tt.pin)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_ChangePin(t *testing.T) {
+	currentHash, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+	previousHash, _ := bcrypt.GenerateFromPassword([]byte("246813"), bcrypt.MinCost)
+	birthDate := time.Date(1990, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	newUser := func() *models.User {
+		user := createTestUser("user123", "testuser", string(currentHash), 0, nil, nil)
+		user.BirthDate = &birthDate
+		return user
+	}
+	expectCurrentPinOK := func(mockRepo *MockAuthRepository) {
+		mockRepo.On("GetUserWithPin", "testuser").Return(newUser(), nil)
+		mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+		mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+	}
+
+	tests := []struct {
+		name        string
+		params      entities.ChangePinParams
+		mockSetup   func(*MockAuthRepository, *MockJwtService)
+		expectError error
+	}{
+		{
+			name:   "pin changed and other sessions revoked",
+			params: entities.ChangePinParams{CurrentPin: "135790", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				expectCurrentPinOK(mockRepo)
+				mockRepo.On("GetPinHistory", "user123", 5).Return([]models.UserPinHistory{{HashedPin: string(previousHash)}}, nil)
+				mockRepo.On("UpdatePin", "user123", mock.MatchedBy(func(hash string) bool {
+					return bcrypt.CompareHashAndPassword([]byte(hash), []byte("724159")) == nil
+				}), string(currentHash), 5).Return(nil)
+				mockRepo.On("InvalidateUserWithPin", mock.Anything, "testuser").Return(nil)
+				mockRepo.On("BanAllUserTokens", mock.Anything, "user123", "PIN changed").Return(nil)
+				mockRepo.On("GetUserRoles", "user123").Return([]models.Role{}, nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", mock.MatchedBy(func(options entities.TokenOptions) bool {
+					return options.DeviceID == "device-1" && time.Since(time.Unix(options.AuthTime, 0)) < time.Minute
+				})).Return(&entities.TokenResponse{Token: "new-access", TokenID: "token-2"}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
+			},
+		},
+		{
+			name:   "wrong current pin",
+			params: entities.ChangePinParams{CurrentPin: "000000", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(newUser(), nil)
+				mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+				mockRepo.On("IncrementFailedAttempts", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 1}, nil)
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+		{
+			name:   "sequential pin",
+			params: entities.ChangePinParams{CurrentPin: "135790", NewPin: "456789"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				expectCurrentPinOK(mockRepo)
+			},
+			expectError: exception.NewWeakPinError("sequential digits"),
+		},
+		{
+			name:   "birthday pin",
+			params: entities.ChangePinParams{CurrentPin: "135790", NewPin: "140390"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				expectCurrentPinOK(mockRepo)
+			},
+			expectError: exception.NewWeakPinError("matches your date of birth"),
+		},
+		{
+			name:   "same as current pin",
+			params: entities.ChangePinParams{CurrentPin: "135790", NewPin: "135790"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				expectCurrentPinOK(mockRepo)
+				mockRepo.On("GetPinHistory", "user123", 5).Return([]models.UserPinHistory{}, nil)
+			},
+			expectError: exception.ErrPinReused,
+		},
+		{
+			name:   "recently used pin",
+			params: entities.ChangePinParams{CurrentPin: "135790", NewPin: "246813"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				expectCurrentPinOK(mockRepo)
+				mockRepo.On("GetPinHistory", "user123", 5).Return([]models.UserPinHistory{{HashedPin: string(previousHash)}}, nil)
+			},
+			expectError: exception.ErrPinReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockJwt := new(MockJwtService)
+			tt.mockSetup(mockRepo, mockJwt)
+
+			config := &config.Config{
+				Auth: &config.AuthConfig{
+					Pin: &config.PinConfig{
+						BaseDuration:    10 * time.Second,
+						LockThreshold:   3,
+						MaxLockDuration: 300 * time.Second,
+						HistorySize:     5,
+					},
+				},
+			}
+
+			tokenResponse, err := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config).
+				ChangePin(context.Background(), entities.Claims{UserID: "user123", Username: "testuser", DeviceID: "device-1"}, tt.params)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+				assert.Nil(t, tokenResponse)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "new-access", tokenResponse.Token)
+				assert.Equal(t, "user123", tokenResponse.UserID)
+			}
+			mockRepo.AssertExpectations(t)
+			mockJwt.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_Reauthenticate(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+	session := entities.Claims{
+		UserID:         "user123",
+		Username:       "testuser",
+		TokenID:        "token-1",
+		RefreshTokenID: "refresh-1",
+		TwoFactor:      true,
+		AuthTime:       time.Now().Add(-time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		name        string
+		pin         string
+		mockSetup   func(*MockAuthRepository, *MockJwtService)
+		expectError error
+	}{
+		{
+			name: "fresh access token keeps the session",
+			pin:  "135790",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+				mockJwt.On("GenerateAccessToken", "user123", "testuser", "refresh-1", mock.MatchedBy(func(options entities.TokenOptions) bool {
+					return options.TwoFactor && time.Since(time.Unix(options.AuthTime, 0)) < time.Minute
+				})).Return(&entities.TokenResponse{Token: "access", TokenID: "token-2", RefreshTokenID: "refresh-1"}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
+				// the replaced access token is banned, the refresh token is kept
+				mockRepo.On("BanTokens", mock.Anything, "user123", "Replaced by reauthentication", []string{"token-1"}).Return(nil)
+			},
+		},
+		{
+			name: "wrong pin",
+			pin:  "000000",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("IncrementFailedAttempts", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123", FailedAttempts: 1}, nil)
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockJwt := new(MockJwtService)
+			mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+			mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+			tt.mockSetup(mockRepo, mockJwt)
+
+			cfg := &config.Config{Auth: &config.AuthConfig{Pin: &config.PinConfig{LockThreshold: 3}}}
+			tokenResponse, err := NewAuthService(mockRepo, mockJwt, new(MockNotifier), cfg).
+				Reauthenticate(context.Background(), session, entities.ReauthParams{Pin: tt.pin})
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+				assert.Nil(t, tokenResponse)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "user123", tokenResponse.UserID)
+			}
+			mockRepo.AssertExpectations(t)
+			mockJwt.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAuthService_ListTokens(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1047,7 +1662,7 @@ func TestAuthService_ListTokens(t *testing.T) {
 				},
 			}
 
-			service := NewAuthService(mockRepo, mockJwt, config)
+			service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), config)
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo)
@@ -1074,3 +1689,150 @@ func TestAuthService_ListTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_Logout(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("BanTokens", mock.Anything, "user123", "Logged out", []string{"token-1", "refresh-1"}).Return(nil)
+	mockRepo.On("RevokeTokenFamily", mock.Anything, "user123", "family-1", "Logged out").Return(nil)
+
+	err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), &config.Config{}).Logout(context.Background(), entities.Claims{
+		UserID:         "user123",
+		TokenID:        "token-1",
+		RefreshTokenID: "refresh-1",
+		FamilyID:       "family-1",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name: "bans both tokens of the session",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserSession", mock.Anything, "user123", "token-1").
+					Return(&entities.TokenResponse{TokenID: "token-1", RefreshTokenID: "refresh-1"}, nil)
+				mockRepo.On("BanTokens", mock.Anything, "user123", "Session revoked by user", []string{"token-1", "refresh-1"}).Return(nil)
+			},
+		},
+		{
+			name: "rotated session revokes its family",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserSession", mock.Anything, "user123", "token-1").
+					Return(&entities.TokenResponse{TokenID: "token-1", RefreshTokenID: "refresh-1", FamilyID: "family-1"}, nil)
+				mockRepo.On("BanTokens", mock.Anything, "user123", "Session revoked by user", []string{"token-1", "refresh-1"}).Return(nil)
+				mockRepo.On("RevokeTokenFamily", mock.Anything, "user123", "family-1", "Session revoked by user").Return(nil)
+			},
+		},
+		{
+			name: "session of another user",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserSession", mock.Anything, "user123", "token-1").Return(nil, nil)
+			},
+			expectError: exception.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			tt.mockSetup(mockRepo)
+
+			err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), &config.Config{}).
+				RevokeSession(context.Background(), "user123", "token-1")
+
+			assert.Equal(t, tt.expectError, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_IntrospectToken(t *testing.T) {
+	expiresAt := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+	issuedAt := expiresAt.Add(-15 * time.Minute)
+	claims := entities.Claims{
+		UserID:   "user123",
+		Username: "testuser",
+		Type:     "access",
+		TokenID:  "token-1",
+		AuthTime: issuedAt.Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "banking-api",
+			Audience:  jwt.ClaimStrings{"banking-api"},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		result   *entities.TokenValidationResult
+		err      error
+		expected *entities.TokenIntrospection
+	}{
+		{
+			name:   "active token",
+			result: &entities.TokenValidationResult{Valid: true, Claims: claims},
+			expected: &entities.TokenIntrospection{
+				Active:    true,
+				Sub:       "user123",
+				Username:  "testuser",
+				TokenType: "Bearer",
+				Exp:       expiresAt.Unix(),
+				Iat:       issuedAt.Unix(),
+				Jti:       "token-1",
+				Iss:       "banking-api",
+				Aud:       []string{"banking-api"},
+				AuthTime:  issuedAt.Unix(),
+			},
+		},
+		{
+			name:     "banned token",
+			result:   &entities.TokenValidationResult{Valid: false, Reason: "token is banned"},
+			err:      exception.NewTokenBannedError(),
+			expected: &entities.TokenIntrospection{RevocationReason: "token_revoked"},
+		},
+		{
+			name:     "all user tokens banned",
+			result:   &entities.TokenValidationResult{Valid: false, Reason: "token is blacklisted"},
+			err:      exception.NewTokenBannedError(),
+			expected: &entities.TokenIntrospection{RevocationReason: "user_tokens_revoked"},
+		},
+		{
+			name:     "outdated token",
+			result:   &entities.TokenValidationResult{Valid: false, Reason: "Token is too old"},
+			err:      exception.NewTokenOutdatedError("Token is too old"),
+			expected: &entities.TokenIntrospection{RevocationReason: "token_outdated"},
+		},
+		{
+			name:     "invalid token",
+			result:   &entities.TokenValidationResult{Valid: false, Reason: "invalid token"},
+			err:      exception.ErrTokenExpired,
+			expected: &entities.TokenIntrospection{},
+		},
+		{
+			name:     "ban check failed",
+			result:   &entities.TokenValidationResult{Valid: true, Reason: "ban check failed"},
+			expected: &entities.TokenIntrospection{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJwt := new(MockJwtService)
+			mockJwt.On("ValidateTokenWithBanCheck", "access-token").Return(tt.result, tt.err)
+
+			introspection, err := NewAuthService(new(MockAuthRepository), mockJwt, new(MockNotifier), &config.Config{}).
+				IntrospectToken(context.Background(), "access-token")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, introspection)
+			mockJwt.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/notifier"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"gorm.io/gorm"
+)
+
+// registerDevice records the device a user signs in from and returns its ID.
+// isNew is true for a device the user has not signed in from before or
+// revoked since. A registered device must keep its public key.
+func (s *authService) registerDevice(userID string, params *entities.DeviceParams) (deviceID string, isNew bool, err error) {
+	if params == nil {
+		return "", false, nil
+	}
+
+	device, err := s.repository.GetUserDevice(userID, params.DeviceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, err
+	}
+
+	switch {
+	case device == nil:
+		device = &models.UserDevice{UserID: userID, DeviceID: params.DeviceID}
+		isNew = true
+	case device.RevokedAt != nil:
+		isNew = true
+	case device.PublicKey != params.PublicKey:
+		logger.Warnf("Rejected sign-in of user %s from device %s with a different public key", userID, params.DeviceID)
+		return "", false, exception.ErrDeviceKeyMismatch
+	}
+
+	device.Name = params.Name
+	device.Platform = params.Platform
+	device.PublicKey = params.PublicKey
+	device.LastSeenAt = time.Now()
+	device.RevokedAt = nil
+	if err := s.repository.SaveUserDevice(device); err != nil {
+		return "", false, err
+	}
+	return device.DeviceID, isNew, nil
+}
+
+// notifyNewDevice tells the user about a sign-in from a new device. A failed
+// delivery does not fail the sign-in.
+func (s *authService) notifyNewDevice(ctx context.Context, userID, username string, params *entities.DeviceParams) {
+	logger.Infof("User %s signed in from new device %s (%s)", userID, params.DeviceID, params.Platform)
+	if s.notifier == nil {
+		return
+	}
+
+	message := notifier.Message{
+		UserID:   userID,
+		Username: username,
+		Subject:  "New device signed in",
+		Body: fmt.Sprintf("Your account was signed in on a new device: %s (%s) at %s. If this was not you, revoke the device and change your PIN.",
+			params.Name, params.Platform, time.Now().Format(time.RFC1123)),
+	}
+	if err := s.notifier.Send(ctx, message); err != nil {
+		logger.Errorf("Failed to send new device notification to user %s: %v", userID, err)
+	}
+}
+
+// ListDevices returns the registered devices of the user that are not
+// revoked.
+func (s *authService) ListDevices(ctx context.Context, userID string) ([]entities.Device, error) {
+	devices, err := s.repository.ListUserDevices(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]entities.Device, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, toDevice(device))
+	}
+	return result, nil
+}
+
+// RevokeDevice ends every session bound to the device and revokes it, so its
+// next sign-in counts as a new device.
+func (s *authService) RevokeDevice(ctx context.Context, userID, deviceID string) error {
+	device, err := s.repository.GetUserDevice(userID, deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exception.ErrDeviceNotFound
+		}
+		return err
+	}
+	if device.RevokedAt != nil {
+		return exception.ErrDeviceNotFound
+	}
+
+	sessions, err := s.repository.ListUserTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.DeviceID != deviceID {
+			continue
+		}
+		if err := s.endSession(ctx, userID, session.FamilyID, "Device revoked by user", session.TokenID, session.RefreshTokenID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repository.RevokeUserDevice(userID, deviceID); err != nil {
+		return err
+	}
+
+	logger.Infof("User %s revoked device %s", userID, deviceID)
+	return nil
+}
+
+// attachDevices sets the device of every session bound to one. Sessions keep
+// their device ID when the device cannot be loaded.
+func (s *authService) attachDevices(userID string, tokens []entities.TokenResponse) {
+	bound := false
+	for _, token := range tokens {
+		if token.DeviceID != "" {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		return
+	}
+
+	devices, err := s.repository.ListUserDevices(userID)
+	if err != nil {
+		logger.Errorf("Failed to load devices of user %s: %v", userID, err)
+		return
+	}
+
+	byID := make(map[string]entities.Device, len(devices))
+	for _, device := range devices {
+		byID[device.DeviceID] = toDevice(device)
+	}
+	for i := range tokens {
+		if device, ok := byID[tokens[i].DeviceID]; ok {
+			tokens[i].Device = &device
+		}
+	}
+}
+
+func toDevice(device models.UserDevice) entities.Device {
+	return entities.Device{
+		DeviceID:     device.DeviceID,
+		Name:         device.Name,
+		Platform:     device.Platform,
+		RegisteredAt: device.CreatedAt,
+		LastSeenAt:   device.LastSeenAt,
+	}
+}
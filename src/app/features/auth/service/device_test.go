@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/notifier"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestAuthService_VerifyPin_Device(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	params := entities.PinVerifyParams{
+		Username: "testuser",
+		Pin:      "123456",
+		Device:   &entities.DeviceParams{DeviceID: "device-1", Name: "Pixel 8", Platform: "android", PublicKey: "a2V5LTE="},
+	}
+	revokedAt := time.Now().Add(-time.Hour)
+	boundToDevice := mock.MatchedBy(func(options entities.TokenOptions) bool {
+		return options.DeviceID == "device-1" && options.AuthTime > 0
+	})
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthRepository, *MockJwtService, *MockNotifier)
+		expectError error
+	}{
+		{
+			name: "new device is registered and notified",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService, mockNotifier *MockNotifier) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").Return(nil, gorm.ErrRecordNotFound)
+				mockRepo.On("SaveUserDevice", mock.MatchedBy(func(device *models.UserDevice) bool {
+					return device.UserID == "user123" && device.PublicKey == "a2V5LTE=" && !device.LastSeenAt.IsZero()
+				})).Return(nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", boundToDevice).
+					Return(&entities.TokenResponse{Token: "access_token", DeviceID: "device-1"}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
+				mockNotifier.On("Send", mock.MatchedBy(func(msg notifier.Message) bool {
+					return msg.UserID == "user123" && msg.Subject == "New device signed in"
+				})).Return(nil)
+			},
+		},
+		{
+			name: "known device is not notified",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService, mockNotifier *MockNotifier) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").
+					Return(&models.UserDevice{ID: 1, UserID: "user123", DeviceID: "device-1", PublicKey: "a2V5LTE="}, nil)
+				mockRepo.On("SaveUserDevice", mock.AnythingOfType("*models.UserDevice")).Return(nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", boundToDevice).
+					Return(&entities.TokenResponse{Token: "access_token", DeviceID: "device-1"}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
+			},
+		},
+		{
+			name: "revoked device registers again",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService, mockNotifier *MockNotifier) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").
+					Return(&models.UserDevice{ID: 1, UserID: "user123", DeviceID: "device-1", PublicKey: "b2xkLWtleQ==", RevokedAt: &revokedAt}, nil)
+				mockRepo.On("SaveUserDevice", mock.MatchedBy(func(device *models.UserDevice) bool {
+					return device.RevokedAt == nil && device.PublicKey == "a2V5LTE="
+				})).Return(nil)
+				mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", boundToDevice).
+					Return(&entities.TokenResponse{Token: "access_token", DeviceID: "device-1"}, nil)
+				mockRepo.On("StoreToken", mock.Anything, "user123", mock.AnythingOfType("*entities.TokenResponse")).Return(nil)
+				mockNotifier.On("Send", mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "registered device with another key",
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService, mockNotifier *MockNotifier) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").
+					Return(&models.UserDevice{ID: 1, UserID: "user123", DeviceID: "device-1", PublicKey: "b2xkLWtleQ=="}, nil)
+			},
+			expectError: exception.ErrDeviceKeyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockJwt := new(MockJwtService)
+			mockNotifier := new(MockNotifier)
+			mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+			mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+			mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+			mockRepo.On("GetUserRoles", "user123").Return([]models.Role{}, nil).Maybe()
+			tt.mockSetup(mockRepo, mockJwt, mockNotifier)
+
+			tokenResponse, err := NewAuthService(mockRepo, mockJwt, mockNotifier, &config.Config{}).VerifyPin(context.Background(), params)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+				assert.Nil(t, tokenResponse)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "device-1", tokenResponse.DeviceID)
+			}
+			mockRepo.AssertExpectations(t)
+			mockJwt.AssertExpectations(t)
+			mockNotifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_RevokeDevice(t *testing.T) {
+	revokedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name: "ends the sessions of the device",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").Return(&models.UserDevice{UserID: "user123", DeviceID: "device-1"}, nil)
+				mockRepo.On("ListUserTokens", mock.Anything, "user123").Return([]entities.TokenResponse{
+					{TokenID: "token-1", RefreshTokenID: "refresh-1", FamilyID: "family-1", DeviceID: "device-1"},
+					{TokenID: "token-2", RefreshTokenID: "refresh-2", FamilyID: "family-2", DeviceID: "device-2"},
+					{TokenID: "token-3", RefreshTokenID: "refresh-3", FamilyID: "family-3"},
+				}, nil)
+				mockRepo.On("BanTokens", mock.Anything, "user123", "Device revoked by user", []string{"token-1", "refresh-1"}).Return(nil)
+				mockRepo.On("RevokeTokenFamily", mock.Anything, "user123", "family-1", "Device revoked by user").Return(nil)
+				mockRepo.On("RevokeUserDevice", "user123", "device-1").Return(nil)
+			},
+		},
+		{
+			name: "unknown device",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrDeviceNotFound,
+		},
+		{
+			name: "already revoked",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserDevice", "user123", "device-1").
+					Return(&models.UserDevice{UserID: "user123", DeviceID: "device-1", RevokedAt: &revokedAt}, nil)
+			},
+			expectError: exception.ErrDeviceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			tt.mockSetup(mockRepo)
+
+			err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), &config.Config{}).
+				RevokeDevice(context.Background(), "user123", "device-1")
+
+			assert.Equal(t, tt.expectError, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_RevokeDevice_AfterChangePin(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+	caller := entities.Claims{UserID: "user123", Username: "testuser", DeviceID: "device-1"}
+
+	mockRepo := new(MockAuthRepository)
+	mockJwt := new(MockJwtService)
+	mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+	mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+	mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+	mockRepo.On("GetPinHistory", "user123", 5).Return([]models.UserPinHistory{}, nil)
+	mockRepo.On("UpdatePin", "user123", mock.AnythingOfType("string"), string(hashedPin), 5).Return(nil)
+	mockRepo.On("InvalidateUserWithPin", mock.Anything, "testuser").Return(nil)
+	mockRepo.On("BanAllUserTokens", mock.Anything, "user123", "PIN changed").Return(nil)
+	mockRepo.On("GetUserRoles", "user123").Return([]models.Role{}, nil)
+	issued := &entities.TokenResponse{TokenID: "token-2", RefreshTokenID: "refresh-2", FamilyID: "family-2"}
+	mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", mock.AnythingOfType("entities.TokenOptions")).
+		Run(func(args mock.Arguments) {
+			// the JWT service binds the session to the device of its options
+			issued.DeviceID = args.Get(2).(entities.TokenOptions).DeviceID
+		}).
+		Return(issued, nil)
+	var stored []entities.TokenResponse
+	mockRepo.On("StoreToken", mock.Anything, "user123", mock.MatchedBy(func(token *entities.TokenResponse) bool {
+		stored = append(stored, *token)
+		return true
+	})).Return(nil)
+
+	service := NewAuthService(mockRepo, mockJwt, new(MockNotifier), &config.Config{
+		Auth: &config.AuthConfig{Pin: &config.PinConfig{LockThreshold: 3, HistorySize: 5}},
+	})
+	_, err := service.ChangePin(context.Background(), caller, entities.ChangePinParams{CurrentPin: "135790", NewPin: "724159"})
+	assert.NoError(t, err)
+
+	// the session issued by the PIN change ends with its device
+	mockRepo.On("GetUserDevice", "user123", "device-1").Return(&models.UserDevice{UserID: "user123", DeviceID: "device-1"}, nil)
+	mockRepo.On("ListUserTokens", mock.Anything, "user123").Return(stored, nil)
+	mockRepo.On("BanTokens", mock.Anything, "user123", "Device revoked by user", []string{"token-2", "refresh-2"}).Return(nil)
+	mockRepo.On("RevokeTokenFamily", mock.Anything, "user123", "family-2", "Device revoked by user").Return(nil)
+	mockRepo.On("RevokeUserDevice", "user123", "device-1").Return(nil)
+
+	err = service.RevokeDevice(context.Background(), "user123", "device-1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockJwt.AssertExpectations(t)
+}
+
+func TestAuthService_ListUserTokens_Devices(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("ListUserTokens", mock.Anything, "user123").Return([]entities.TokenResponse{
+		{TokenID: "token-1", DeviceID: "device-1"},
+		{TokenID: "token-2"},
+		{TokenID: "token-3", DeviceID: "revoked-device"},
+	}, nil)
+	mockRepo.On("ListUserDevices", "user123").Return([]models.UserDevice{
+		{UserID: "user123", DeviceID: "device-1", Name: "Pixel 8", Platform: "android"},
+	}, nil)
+
+	tokens, err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), &config.Config{}).
+		ListUserTokens(context.Background(), "user123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.Device{DeviceID: "device-1", Name: "Pixel 8", Platform: "android"}, tokens[0].Device)
+	assert.Nil(t, tokens[1].Device)
+	assert.Nil(t, tokens[2].Device)
+	mockRepo.AssertExpectations(t)
+}
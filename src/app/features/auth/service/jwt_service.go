@@ -7,6 +7,7 @@ import (
 
 	"github.com/Testzyler/banking-api/app/entities"
 	"github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/signing"
 	"github.com/Testzyler/banking-api/config"
 	"github.com/Testzyler/banking-api/logger"
 	"github.com/Testzyler/banking-api/server/exception"
@@ -14,48 +15,142 @@ import (
 	"github.com/google/uuid"
 )
 
+const defaultRestrictedTokenExpiry = 5 * time.Minute
+
 type jwtService struct {
 	config   *config.Config
 	authRepo repository.AuthRepository
+	keys     *signing.KeySet // nil signs access tokens with the HS256 secret
 }
 
 type JwtService interface {
 	GenerateTokens(userID, username string) (*entities.TokenResponse, error)
+	GenerateTokensWithOptions(userID, username string, options entities.TokenOptions) (*entities.TokenResponse, error)
+	GenerateAccessToken(userID, username, refreshTokenID string, options entities.TokenOptions) (*entities.TokenResponse, error)
+	GenerateRestrictedToken(userID, username, purpose string) (*entities.TokenResponse, error)
 	ValidateAccessToken(tokenString string) (*entities.Claims, error)
 	ValidateRefreshToken(tokenString string) (*entities.Claims, error)
-	RefreshAccessToken(refreshTokenString string) (*entities.TokenResponse, error)
+	RefreshAccessToken(ctx context.Context, refreshTokenString string) (*entities.TokenResponse, error)
 	ValidateTokenWithBanCheck(tokenString string) (*entities.TokenValidationResult, error)
 }
 
 func NewJwtService(config *config.Config, authRepo repository.AuthRepository) JwtService {
-	return &jwtService{config: config, authRepo: authRepo}
+	return &jwtService{config: config, authRepo: authRepo, keys: signing.Default()}
 }
 
 func (s *jwtService) GenerateTokens(userID, username string) (*entities.TokenResponse, error) {
+	return s.GenerateTokensWithOptions(userID, username, entities.TokenOptions{})
+}
+
+// GenerateTokensWithOptions issues a token pair carrying the given session
+// properties, e.g. that the user passed the second factor.
+func (s *jwtService) GenerateTokensWithOptions(userID, username string, options entities.TokenOptions) (*entities.TokenResponse, error) {
 	// Generate unique token ID and use current timestamp as token version
 	tokenID := uuid.New().String()
+	refreshTokenID := uuid.New().String()
 	tokenVersion := time.Now().Unix()
+	if options.FamilyID == "" {
+		options.FamilyID = uuid.New().String()
+	}
 	accessTokenParam := entities.GenerateTokenParams{
-		UserID:       userID,
-		Username:     username,
-		TokenVersion: tokenVersion,
-		TokenID:      tokenID,
-		TokenType:    "access",
+		UserID:         userID,
+		Username:       username,
+		TokenVersion:   tokenVersion,
+		TokenID:        tokenID,
+		TokenType:      "access",
+		RefreshTokenID: refreshTokenID,
+		FamilyID:       options.FamilyID,
+		DeviceID:       options.DeviceID,
+		Roles:          options.Roles,
+		Scopes:         options.Scopes,
+		TwoFactor:      options.TwoFactor,
+		AuthTime:       options.AuthTime,
 	}
 	accessToken, accessExpiry, err := s.generateToken(accessTokenParam)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshTokenID := uuid.New().String()
 	refreshTokenParam := entities.GenerateTokenParams{
 		UserID:       userID,
 		Username:     username,
 		TokenVersion: tokenVersion,
 		TokenID:      refreshTokenID,
 		TokenType:    "refresh",
+		FamilyID:     options.FamilyID,
+		DeviceID:     options.DeviceID,
+		TwoFactor:    options.TwoFactor,
+		AuthTime:     options.AuthTime,
+	}
+	refreshToken, refreshExpiry, err := s.generateToken(refreshTokenParam)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.TokenResponse{
+		Token:          accessToken,
+		Expiry:         accessExpiry,
+		RefreshToken:   refreshToken,
+		TokenVersion:   tokenVersion,
+		TokenID:        tokenID,
+		RefreshTokenID: refreshTokenID,
+		RefreshExpiry:  &refreshExpiry,
+		FamilyID:       options.FamilyID,
+		DeviceID:       options.DeviceID,
+		TwoFactor:      options.TwoFactor,
+	}, nil
+}
+
+// GenerateAccessToken issues an access token paired with the existing
+// refresh token, for updating properties of the current session such as the
+// auth time.
+func (s *jwtService) GenerateAccessToken(userID, username, refreshTokenID string, options entities.TokenOptions) (*entities.TokenResponse, error) {
+	tokenID := uuid.New().String()
+	tokenVersion := time.Now().Unix()
+	accessToken, accessExpiry, err := s.generateToken(entities.GenerateTokenParams{
+		UserID:         userID,
+		Username:       username,
+		TokenVersion:   tokenVersion,
+		TokenID:        tokenID,
+		TokenType:      "access",
+		RefreshTokenID: refreshTokenID,
+		FamilyID:       options.FamilyID,
+		DeviceID:       options.DeviceID,
+		Roles:          options.Roles,
+		Scopes:         options.Scopes,
+		TwoFactor:      options.TwoFactor,
+		AuthTime:       options.AuthTime,
+	})
+	if err != nil {
+		return nil, err
 	}
-	refreshToken, _, err := s.generateToken(refreshTokenParam)
+
+	return &entities.TokenResponse{
+		Token:          accessToken,
+		Expiry:         accessExpiry,
+		TokenVersion:   tokenVersion,
+		TokenID:        tokenID,
+		RefreshTokenID: refreshTokenID,
+		FamilyID:       options.FamilyID,
+		DeviceID:       options.DeviceID,
+		TwoFactor:      options.TwoFactor,
+	}, nil
+}
+
+// GenerateRestrictedToken issues a short-lived access token limited to one
+// purpose. It comes without a refresh token, so the user has to sign in again
+// once it expires.
+func (s *jwtService) GenerateRestrictedToken(userID, username, purpose string) (*entities.TokenResponse, error) {
+	tokenID := uuid.New().String()
+	tokenVersion := time.Now().Unix()
+	accessToken, accessExpiry, err := s.generateToken(entities.GenerateTokenParams{
+		UserID:       userID,
+		Username:     username,
+		TokenVersion: tokenVersion,
+		TokenID:      tokenID,
+		TokenType:    "access",
+		Purpose:      purpose,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +158,6 @@ func (s *jwtService) GenerateTokens(userID, username string) (*entities.TokenRes
 	return &entities.TokenResponse{
 		Token:        accessToken,
 		Expiry:       accessExpiry,
-		RefreshToken: refreshToken,
 		TokenVersion: tokenVersion,
 		TokenID:      tokenID,
 	}, nil
@@ -84,15 +178,30 @@ func (s *jwtService) generateToken(param entities.GenerateTokenParams) (string,
 		return "", time.Time{}, errors.New("invalid token type")
 	}
 
+	if param.Purpose != "" {
+		expiry = s.config.Auth.Jwt.RestrictedTokenExpiry
+		if expiry <= 0 {
+			expiry = defaultRestrictedTokenExpiry
+		}
+	}
+
 	now := time.Now()
 	expiryTime := now.Add(expiry)
 
 	claims := &entities.Claims{
-		UserID:       param.UserID,
-		Username:     param.Username,
-		Type:         param.TokenType,
-		TokenVersion: param.TokenVersion,
-		TokenID:      param.TokenID,
+		UserID:         param.UserID,
+		Username:       param.Username,
+		Type:           param.TokenType,
+		TokenVersion:   param.TokenVersion,
+		TokenID:        param.TokenID,
+		RefreshTokenID: param.RefreshTokenID,
+		FamilyID:       param.FamilyID,
+		DeviceID:       param.DeviceID,
+		Roles:          param.Roles,
+		Scopes:         param.Scopes,
+		Purpose:        param.Purpose,
+		TwoFactor:      param.TwoFactor,
+		AuthTime:       param.AuthTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        param.TokenID,
 			Subject:   param.UserID,
@@ -103,8 +212,17 @@ func (s *jwtService) generateToken(param entities.GenerateTokenParams) (string,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	var tokenString string
+	var err error
+	if param.TokenType == "access" && s.keys != nil {
+		// Other services verify access tokens with the published public keys
+		key := s.keys.Active()
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		tokenString, err = token.SignedString(key.PrivateKey)
+	} else {
+		tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	}
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -113,24 +231,52 @@ func (s *jwtService) generateToken(param entities.GenerateTokenParams) (string,
 }
 
 func (s *jwtService) ValidateAccessToken(tokenString string) (*entities.Claims, error) {
-	return s.validateToken(tokenString, s.config.Auth.Jwt.AccessTokenSecret, "access")
+	return s.validateToken(tokenString, s.accessTokenKey, "access")
 }
 
 func (s *jwtService) ValidateRefreshToken(tokenString string) (*entities.Claims, error) {
-	return s.validateToken(tokenString, s.config.Auth.Jwt.RefreshTokenSecret, "refresh")
+	return s.validateToken(tokenString, hmacKey(s.config.Auth.Jwt.RefreshTokenSecret), "refresh")
 }
 
-func (s *jwtService) validateToken(tokenString, secret, expectedType string) (*entities.Claims, error) {
-	if tokenString == "" {
-		return nil, errors.New("token is empty")
+// accessTokenKey picks the verification key by the kid header. Tokens
+// without one are signed with the HS256 secret, which is only accepted while
+// no signing keys are configured; otherwise anyone holding the shared secret
+// could still forge access tokens.
+func (s *jwtService) accessTokenKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if s.keys == nil {
+		if kid != "" || s.config.Auth.Jwt.AccessTokenSecret == "" {
+			return nil, signing.ErrUnknownKey
+		}
+		return hmacKey(s.config.Auth.Jwt.AccessTokenSecret)(token)
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &entities.Claims{}, func(token *jwt.Token) (interface{}, error) {
+	key, ok := s.keys.Key(kid)
+	if !ok {
+		return nil, signing.ErrUnknownKey
+	}
+	// The algorithm comes from our key, never from the token
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.PublicKey, nil
+}
+
+func hmacKey(secret string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return []byte(secret), nil
-	})
+	}
+}
+
+func (s *jwtService) validateToken(tokenString string, keyFunc jwt.Keyfunc, expectedType string) (*entities.Claims, error) {
+	if tokenString == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &entities.Claims{}, keyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -149,18 +295,24 @@ func (s *jwtService) validateToken(tokenString, secret, expectedType string) (*e
 	return nil, jwt.ErrTokenInvalidClaims
 }
 
-func (s *jwtService) RefreshAccessToken(refreshTokenString string) (*entities.TokenResponse, error) {
+// RefreshAccessToken issues a new token pair for a valid refresh token.
+// Every refresh of a well-formed token ends up in the audit log.
+func (s *jwtService) RefreshAccessToken(ctx context.Context, refreshTokenString string) (*entities.TokenResponse, error) {
 	claims, err := s.ValidateRefreshToken(refreshTokenString)
 	if err != nil {
 		return nil, err
 	}
 
+	tokenResponse, err := s.refresh(ctx, claims)
+	recordEvent(ctx, claims.UserID, claims.Username, entities.AuthEventTokenRefreshed, claims.FamilyID, err)
+	return tokenResponse, err
+}
+
+func (s *jwtService) refresh(ctx context.Context, claims *entities.Claims) (*entities.TokenResponse, error) {
 	if s.authRepo == nil {
 		return nil, exception.ErrInternalServer
 	}
 
-	ctx := context.Background()
-
 	// Check if the specific refresh token is banned
 	isBanned, err := s.authRepo.IsTokenBanned(ctx, claims.TokenID)
 	if err != nil {
@@ -195,42 +347,57 @@ func (s *jwtService) RefreshAccessToken(refreshTokenString string) (*entities.To
 		return nil, exception.NewTokenOutdatedError(validationResult.Reason)
 	}
 
-	newTokenID := uuid.New().String()
-	newTokenVersion := time.Now().Unix()
-	// Generate new access token
-	accessTokenParam := entities.GenerateTokenParams{
-		UserID:       claims.UserID,
-		Username:     claims.Username,
-		TokenVersion: newTokenVersion,
-		TokenID:      newTokenID,
-		TokenType:    "access",
-	}
-	accessToken, accessExpiry, err := s.generateToken(accessTokenParam)
+	// Keep the second factor, auth time and family of the session. Roles are
+	// read again so that a revoked role ends with the next refresh.
+	options := claims.TokenOptions()
+	options.Roles, options.Scopes, err = loadRoles(s.authRepo, claims.UserID)
 	if err != nil {
-		return nil, err
+		logger.Errorf("Failed to load roles of user %s: %v", claims.UserID, err)
+		return nil, exception.ErrInternalServer
 	}
 
-	refreshTokenID := uuid.New().String()
-	refreshTokenParam := entities.GenerateTokenParams{
-		UserID:       claims.UserID,
-		Username:     claims.Username,
-		TokenVersion: newTokenVersion,
-		TokenID:      refreshTokenID,
-		TokenType:    "refresh",
+	// Each refresh token works once. A second use means two parties hold it,
+	// so the whole family is revoked and the user has to sign in again.
+	firstUse, err := s.authRepo.UseRefreshToken(ctx, claims.TokenID, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		logger.Errorf("Failed to mark refresh token as used: %v", err)
+		return nil, exception.ErrInternalServer
+	}
+	if !firstUse {
+		s.revokeReusedFamily(ctx, claims)
+		return nil, exception.ErrRefreshTokenReused
 	}
-	refreshToken, _, err := s.generateToken(refreshTokenParam)
+
+	tokenResponse, err := s.GenerateTokensWithOptions(claims.UserID, claims.Username, options)
 	if err != nil {
 		return nil, err
 	}
+	tokenResponse.UserID = claims.UserID
+	return tokenResponse, nil
+}
 
-	return &entities.TokenResponse{
-		Token:        accessToken,
-		Expiry:       accessExpiry,
-		RefreshToken: refreshToken,
-		UserID:       claims.UserID,
-		TokenVersion: newTokenVersion,
-		TokenID:      newTokenID,
-	}, nil
+func (s *jwtService) revokeReusedFamily(ctx context.Context, claims *entities.Claims) {
+	logger.Warn("Security event: refresh token reused",
+		"event", "refresh_token_reuse",
+		"userID", claims.UserID,
+		"tokenID", claims.TokenID,
+		"familyID", claims.FamilyID,
+	)
+
+	// Tokens issued before families existed can only be banned one by one
+	if claims.FamilyID == "" {
+		err := s.authRepo.BanTokens(ctx, claims.UserID, "Refresh token reused", claims.TokenID)
+		if err != nil {
+			logger.Errorf("Failed to ban reused refresh token %s: %v", claims.TokenID, err)
+		}
+		recordEvent(ctx, claims.UserID, claims.Username, entities.AuthEventRefreshTokenReused, claims.TokenID, err)
+		return
+	}
+	err := s.authRepo.RevokeTokenFamily(ctx, claims.UserID, claims.FamilyID, "Refresh token reused")
+	if err != nil {
+		logger.Errorf("Failed to revoke token family %s: %v", claims.FamilyID, err)
+	}
+	recordEvent(ctx, claims.UserID, claims.Username, entities.AuthEventRefreshTokenReused, claims.FamilyID, err)
 }
 
 func (s *jwtService) ValidateTokenWithBanCheck(tokenString string) (*entities.TokenValidationResult, error) {
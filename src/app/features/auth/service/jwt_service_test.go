@@ -2,13 +2,20 @@ package service
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/Testzyler/banking-api/app/entities"
 	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/signing"
 	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -86,6 +93,29 @@ func (m *MockAuthRepositoryJWT) BanAllUserTokens(ctx context.Context, userID, re
 	return args.Error(0)
 }
 
+func (m *MockAuthRepositoryJWT) BanTokens(ctx context.Context, userID, reason string, tokenIDs ...string) error {
+	args := m.Called(ctx, userID, reason, tokenIDs)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) UseRefreshToken(ctx context.Context, tokenID string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, tokenID, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) RevokeTokenFamily(ctx context.Context, userID, familyID, reason string) error {
+	args := m.Called(ctx, userID, familyID, reason)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) GetUserSession(ctx context.Context, userID, tokenID string) (*entities.TokenResponse, error) {
+	args := m.Called(ctx, userID, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TokenResponse), args.Error(1)
+}
+
 func (m *MockAuthRepositoryJWT) IsTokenBanned(ctx context.Context, tokenID string) (bool, error) {
 	args := m.Called(ctx, tokenID)
 	return args.Bool(0), args.Error(1)
@@ -109,6 +139,134 @@ func (m *MockAuthRepositoryJWT) CleanupExpiredBans(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthRepositoryJWT) InvalidateUserWithPin(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) ClearPinAttempts(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) IncrementPinResetRequests(ctx context.Context, username string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, username, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) IncrementPinResetAttempts(ctx context.Context, userID string, ttl time.Duration) (int64, error) {
+	args := m.Called(ctx, userID, ttl)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) GetPinReset(ctx context.Context, userID string) (*entities.PinResetData, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PinResetData), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) SavePinReset(ctx context.Context, data *entities.PinResetData) error {
+	args := m.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) DeletePinReset(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) GetPinHistory(userID string, limit int) ([]models.UserPinHistory, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserPinHistory), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) UpdatePin(userID, hashedPin, previousHashedPin string, historySize int) error {
+	args := m.Called(userID, hashedPin, previousHashedPin, historySize)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) IncrementTwoFactorAttempts(ctx context.Context, userID string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, userID, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) ResetTwoFactorAttempts(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) GetUserTotp(userID string) (*models.UserTotp, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserTotp), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) SaveTotpEnrollment(totp *models.UserTotp, recoveryCodeHashes []string) error {
+	args := m.Called(totp, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) ConfirmUserTotp(userID string, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) UpdateTotpLastUsedStep(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) GetUnusedRecoveryCodes(userID string) ([]models.UserRecoveryCode, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.UserRecoveryCode), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) UseRecoveryCode(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) GetUserRoles(userID string) ([]models.Role, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) GetUserDevice(userID, deviceID string) (*models.UserDevice, error) {
+	args := m.Called(userID, deviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserDevice), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) SaveUserDevice(device *models.UserDevice) error {
+	args := m.Called(device)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepositoryJWT) ListUserDevices(userID string) ([]models.UserDevice, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserDevice), args.Error(1)
+}
+
+func (m *MockAuthRepositoryJWT) RevokeUserDevice(userID, deviceID string) error {
+	args := m.Called(userID, deviceID)
+	return args.Error(0)
+}
+
 func createMockAuthRepo() *MockAuthRepositoryJWT {
 	return new(MockAuthRepositoryJWT)
 }
@@ -184,6 +342,213 @@ func TestJwtService_GenerateTokens(t *testing.T) {
 	}
 }
 
+func TestJwtService_GenerateTokensWithOptions(t *testing.T) {
+	mockRepo := createMockAuthRepo()
+	mockRepo.On("IsTokenBanned", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+	mockRepo.On("IsInBlacklist", mock.Anything, "user123", mock.AnythingOfType("int64")).Return(false, nil)
+	mockRepo.On("ValidateTokenVersion", mock.Anything, mock.AnythingOfType("int64")).Return(&entities.TokenValidationResult{Valid: true}, nil)
+	mockRepo.On("GetUserRoles", "user123").Return([]models.Role{
+		{Name: entities.RoleSupport, Scopes: []models.RoleScope{{Scope: entities.ScopeUsersRead}}},
+	}, nil)
+	mockRepo.On("UseRefreshToken", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(true, nil)
+	service := NewJwtService(createTestConfig(), mockRepo)
+	authTime := time.Now().Add(-3 * time.Minute).Unix()
+
+	tokenResponse, err := service.GenerateTokensWithOptions("user123", "testuser", entities.TokenOptions{
+		TwoFactor: true,
+		AuthTime:  authTime,
+		DeviceID:  "device-1",
+		Roles:     []string{entities.RoleAdmin},
+		Scopes:    []string{entities.ScopeTokensBan},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, tokenResponse.TwoFactor)
+
+	claims, err := service.ValidateAccessToken(tokenResponse.Token)
+	assert.NoError(t, err)
+	assert.True(t, claims.TwoFactor)
+	assert.Equal(t, authTime, claims.AuthTime)
+	assert.True(t, claims.HasRole(entities.RoleAdmin))
+
+	// the access token names the refresh token issued with it
+	refreshClaims, err := service.ValidateRefreshToken(tokenResponse.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, refreshClaims.TokenID, claims.RefreshTokenID)
+	assert.Equal(t, refreshClaims.TokenID, tokenResponse.RefreshTokenID)
+
+	// a refreshed session keeps the second factor and the auth time, and
+	// picks up the current roles
+	refreshed, err := service.RefreshAccessToken(context.Background(), tokenResponse.RefreshToken)
+	assert.NoError(t, err)
+	refreshedClaims, err := service.ValidateAccessToken(refreshed.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TokenOptions{
+		TwoFactor: true,
+		AuthTime:  authTime,
+		FamilyID:  claims.FamilyID,
+		DeviceID:  "device-1",
+		Roles:     []string{entities.RoleSupport},
+		Scopes:    []string{entities.ScopeUsersRead},
+	}, refreshedClaims.TokenOptions())
+	assert.NotEmpty(t, claims.FamilyID)
+	assert.Equal(t, claims.FamilyID, refreshed.FamilyID)
+}
+
+func TestJwtService_RefreshAccessToken_Reuse(t *testing.T) {
+	tests := []struct {
+		name      string
+		familyID  string
+		mockSetup func(*MockAuthRepositoryJWT, string)
+	}{
+		{
+			name:     "revokes the family",
+			familyID: "family-1",
+			mockSetup: func(mockRepo *MockAuthRepositoryJWT, refreshTokenID string) {
+				mockRepo.On("RevokeTokenFamily", mock.Anything, "user123", "family-1", "Refresh token reused").Return(nil)
+			},
+		},
+		{
+			name: "token from before families bans only itself",
+			mockSetup: func(mockRepo *MockAuthRepositoryJWT, refreshTokenID string) {
+				mockRepo.On("BanTokens", mock.Anything, "user123", "Refresh token reused", []string{refreshTokenID}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := createMockAuthRepo()
+			mockRepo.On("IsTokenBanned", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+			mockRepo.On("IsInBlacklist", mock.Anything, "user123", mock.AnythingOfType("int64")).Return(false, nil)
+			mockRepo.On("ValidateTokenVersion", mock.Anything, mock.AnythingOfType("int64")).Return(&entities.TokenValidationResult{Valid: true}, nil)
+			service := NewJwtService(createTestConfig(), mockRepo).(*jwtService)
+
+			refreshToken, _, err := service.generateToken(entities.GenerateTokenParams{
+				UserID:       "user123",
+				Username:     "testuser",
+				TokenVersion: time.Now().Unix(),
+				TokenID:      "refresh-1",
+				TokenType:    "refresh",
+				FamilyID:     tt.familyID,
+			})
+			assert.NoError(t, err)
+
+			mockRepo.On("GetUserRoles", mock.AnythingOfType("string")).Return([]models.Role{}, nil)
+			mockRepo.On("UseRefreshToken", mock.Anything, "refresh-1", mock.AnythingOfType("time.Duration")).Return(false, nil)
+			tt.mockSetup(mockRepo, "refresh-1")
+
+			tokenResponse, err := service.RefreshAccessToken(context.Background(), refreshToken)
+
+			assert.Equal(t, exception.ErrRefreshTokenReused, err)
+			assert.Nil(t, tokenResponse)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestJwtService_GenerateAccessToken(t *testing.T) {
+	service := NewJwtService(createTestConfig(), createMockAuthRepo())
+	authTime := time.Now().Unix()
+
+	tokenResponse, err := service.GenerateAccessToken("user123", "testuser", "refresh-1", entities.TokenOptions{TwoFactor: true, AuthTime: authTime})
+
+	assert.NoError(t, err)
+	assert.Empty(t, tokenResponse.RefreshToken)
+	assert.Equal(t, "refresh-1", tokenResponse.RefreshTokenID)
+
+	claims, err := service.ValidateAccessToken(tokenResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh-1", claims.RefreshTokenID)
+	assert.True(t, claims.TwoFactor)
+	assert.Equal(t, authTime, claims.AuthTime)
+}
+
+func TestJwtService_AsymmetricAccessTokens(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	assert.NoError(t, err)
+	key, err := signing.ParseKey("key-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, err)
+	keys, err := signing.NewKeySet("key-1", []*signing.Key{key})
+	assert.NoError(t, err)
+
+	config := createTestConfig()
+	service := &jwtService{config: config, authRepo: createMockAuthRepo(), keys: keys}
+	hmacService := &jwtService{config: config, authRepo: createMockAuthRepo()}
+
+	tokenResponse, err := service.GenerateTokens("user123", "testuser")
+	assert.NoError(t, err)
+
+	// access tokens carry the kid and verify with the public key alone
+	parsed, err := jwt.ParseWithClaims(tokenResponse.Token, &entities.Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return &ecKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", parsed.Header["kid"])
+
+	claims, err := service.ValidateAccessToken(tokenResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+
+	// refresh tokens are only read by us and keep the HS256 secret
+	_, err = hmacService.ValidateRefreshToken(tokenResponse.RefreshToken)
+	assert.NoError(t, err)
+
+	// once keys are configured, tokens signed with the shared secret are rejected
+	legacy, err := hmacService.GenerateTokens("user123", "testuser")
+	assert.NoError(t, err)
+	_, err = hmacService.ValidateAccessToken(legacy.Token)
+	assert.NoError(t, err)
+	_, err = service.ValidateAccessToken(legacy.Token)
+	assert.ErrorIs(t, err, signing.ErrUnknownKey)
+
+	// the kid decides the algorithm, not the token
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "key-1"
+	forgedString, err := forged.SignedString([]byte(config.Auth.Jwt.AccessTokenSecret))
+	assert.NoError(t, err)
+	_, err = service.ValidateAccessToken(forgedString)
+	assert.Error(t, err)
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	unknown.Header["kid"] = "key-9"
+	unknownString, err := unknown.SignedString(ecKey)
+	assert.NoError(t, err)
+	_, err = service.ValidateAccessToken(unknownString)
+	assert.ErrorIs(t, err, signing.ErrUnknownKey)
+
+	// without keys, tokens without a kid need the secret
+	config.Auth.Jwt.AccessTokenSecret = ""
+	_, err = hmacService.ValidateAccessToken(legacy.Token)
+	assert.Error(t, err)
+}
+
+func TestJwtService_GenerateRestrictedToken(t *testing.T) {
+	config := createTestConfig()
+	config.Auth.Jwt.RestrictedTokenExpiry = 2 * time.Minute
+	service := NewJwtService(config, createMockAuthRepo())
+
+	tokenResponse, err := service.GenerateRestrictedToken("user123", "testuser", entities.TokenPurposeChangePin)
+
+	assert.NoError(t, err)
+	assert.Empty(t, tokenResponse.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), tokenResponse.Expiry, 5*time.Second)
+
+	claims, err := service.ValidateAccessToken(tokenResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TokenPurposeChangePin, claims.Purpose)
+	assert.Equal(t, tokenResponse.TokenID, claims.TokenID)
+
+	// regular tokens carry no purpose
+	regular, err := service.GenerateTokens("user123", "testuser")
+	assert.NoError(t, err)
+	claims, err = service.ValidateAccessToken(regular.Token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Purpose)
+}
+
 func TestJwtService_ValidateAccessToken(t *testing.T) {
 	config := createTestConfig()
 	mockRepo := createMockAuthRepo()
@@ -323,6 +688,8 @@ func TestJwtService_RefreshAccessToken(t *testing.T) {
 		Valid:        true,
 		TokenVersion: time.Now().Unix(),
 	}, nil)
+	mockRepo.On("GetUserRoles", mock.AnythingOfType("string")).Return([]models.Role{}, nil)
+	mockRepo.On("UseRefreshToken", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(true, nil)
 
 	service := NewJwtService(config, mockRepo)
 
@@ -364,7 +731,7 @@ func TestJwtService_RefreshAccessToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			newTokenResponse, err := service.RefreshAccessToken(tt.refreshTokenString)
+			newTokenResponse, err := service.RefreshAccessToken(context.Background(), tt.refreshTokenString)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -435,7 +802,7 @@ func TestJwtService_TokenExpiration(t *testing.T) {
 	assert.Error(t, err)
 
 	// Try to refresh with expired refresh token
-	_, err = service.RefreshAccessToken(tokenResponse.RefreshToken)
+	_, err = service.RefreshAccessToken(context.Background(), tokenResponse.RefreshToken)
 	assert.Error(t, err)
 }
 
@@ -502,6 +869,8 @@ func TestJwtService_Integration(t *testing.T) {
 		Valid:        true,
 		TokenVersion: time.Now().Unix(),
 	}, nil)
+	mockRepo.On("GetUserRoles", mock.AnythingOfType("string")).Return([]models.Role{}, nil)
+	mockRepo.On("UseRefreshToken", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(true, nil)
 
 	service := NewJwtService(config, mockRepo)
 
@@ -522,7 +891,7 @@ func TestJwtService_Integration(t *testing.T) {
 	assert.Equal(t, "refresh", refreshClaims.Type)
 
 	// Step 4: Refresh access token
-	newTokens, err := service.RefreshAccessToken(originalTokens.RefreshToken)
+	newTokens, err := service.RefreshAccessToken(context.Background(), originalTokens.RefreshToken)
 	assert.NoError(t, err)
 	assert.NotEqual(t, originalTokens.Token, newTokens.Token) // New access token should be different
 
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// buddhistEraOffset converts a Gregorian year to the Thai calendar year that
+// customers often use when writing their birthday.
+const buddhistEraOffset = 543
+
+// weakPinReason returns why pin is too easy to guess, or "" when it is
+// acceptable. birthDate may be nil when the profile has none.
+func weakPinReason(pin string, birthDate *time.Time) string {
+	switch {
+	case isRepeatedPattern(pin):
+		return "repeated digits"
+	case isSequence(pin):
+		return "sequential digits"
+	case birthDate != nil && isBirthday(pin, *birthDate):
+		return "matches your date of birth"
+	}
+	return ""
+}
+
+// isRepeatedPattern catches PINs built from one short block such as 111111,
+// 121212 or 123123.
+func isRepeatedPattern(pin string) bool {
+	for size := 1; size <= len(pin)/2; size++ {
+		if len(pin)%size != 0 {
+			continue
+		}
+		repeated := true
+		for i := size; i < len(pin); i++ {
+			if pin[i] != pin[i-size] {
+				repeated = false
+				break
+			}
+		}
+		if repeated {
+			return true
+		}
+	}
+	return false
+}
+
+// isSequence catches ascending and descending runs such as 123456 or 987654.
+func isSequence(pin string) bool {
+	if len(pin) < 2 {
+		return false
+	}
+	step := int(pin[1]) - int(pin[0])
+	if step != 1 && step != -1 {
+		return false
+	}
+	for i := 2; i < len(pin); i++ {
+		if int(pin[i])-int(pin[i-1]) != step {
+			return false
+		}
+	}
+	return true
+}
+
+// isBirthday checks the common six digit date layouts against both the
+// Gregorian and the Buddhist era year.
+func isBirthday(pin string, birthDate time.Time) bool {
+	day := birthDate.Format("02")
+	month := birthDate.Format("01")
+	for _, year := range []int{birthDate.Year(), birthDate.Year() + buddhistEraOffset} {
+		yy := fmt.Sprintf("%02d", year%100)
+		for _, candidate := range []string{day + month + yy, month + day + yy, yy + month + day} {
+			if pin == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
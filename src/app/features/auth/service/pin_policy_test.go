@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeakPinReason(t *testing.T) {
+	birthDate := time.Date(1990, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pin       string
+		birthDate *time.Time
+		expected  string
+	}{
+		{pin: "111111", expected: "repeated digits"},
+		{pin: "121212", expected: "repeated digits"},
+		{pin: "123123", expected: "repeated digits"},
+		{pin: "123456", expected: "sequential digits"},
+		{pin: "987654", expected: "sequential digits"},
+		{pin: "140390", birthDate: &birthDate, expected: "matches your date of birth"},
+		{pin: "031490", birthDate: &birthDate, expected: "matches your date of birth"},
+		{pin: "900314", birthDate: &birthDate, expected: "matches your date of birth"},
+		// 1990 is 2533 in the Buddhist era
+		{pin: "140333", birthDate: &birthDate, expected: "matches your date of birth"},
+		{pin: "140390", expected: ""},
+		{pin: "135792", birthDate: &birthDate, expected: ""},
+		{pin: "123465", expected: ""},
+		{pin: "112233", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			assert.Equal(t, tt.expected, weakPinReason(tt.pin, tt.birthDate))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/notifier"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	resetCodeDigits           = 6
+	defaultResetCodeExpiry    = 10 * time.Minute
+	defaultResetMaxRequests   = 3
+	defaultResetRequestWindow = time.Hour
+	defaultResetMaxAttempts   = 5
+)
+
+type pinResetService struct {
+	config     *config.Config
+	repository repository.AuthRepository
+	notifier   notifier.Notifier
+}
+
+// PinResetService lets users who forgot their PIN set a new one with a
+// one-time code sent through the notifier.
+type PinResetService interface {
+	StartPinReset(ctx context.Context, params entities.PinResetStartParams) error
+	CompletePinReset(ctx context.Context, params entities.PinResetCompleteParams) error
+}
+
+func NewPinResetService(repository repository.AuthRepository, notifier notifier.Notifier, config *config.Config) PinResetService {
+	return &pinResetService{repository: repository, notifier: notifier, config: config}
+}
+
+// StartPinReset sends a new code, replacing any earlier one. Requests are
+// counted per username before the user is looked up, and every outcome after
+// that succeeds silently, so the endpoint cannot be used to find users.
+func (s *pinResetService) StartPinReset(ctx context.Context, params entities.PinResetStartParams) error {
+	requests, err := s.repository.IncrementPinResetRequests(ctx, params.Username, s.requestWindow())
+	if err != nil {
+		logger.Errorf("Failed to count PIN reset requests for %s: %v", params.Username, err)
+		return nil
+	}
+	if requests > int64(s.maxRequests()) {
+		logger.Warnf("Too many PIN reset requests for %s", params.Username)
+		return nil
+	}
+
+	user, err := s.repository.GetUserWithPin(params.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Infof("PIN reset requested for unknown user %s", params.Username)
+			return nil
+		}
+		return err
+	}
+
+	code, err := generateResetCode()
+	if err != nil {
+		logger.Errorf("Failed to generate PIN reset code for user %s: %v", user.UserID, err)
+		return nil
+	}
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("Failed to hash PIN reset code for user %s: %v", user.UserID, err)
+		return nil
+	}
+
+	expiry := s.codeExpiry()
+	reset := &entities.PinResetData{
+		UserID:    user.UserID,
+		CodeHash:  string(codeHash),
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := s.repository.SavePinReset(ctx, reset); err != nil {
+		logger.Errorf("Failed to store PIN reset for user %s: %v", user.UserID, err)
+		return nil
+	}
+
+	message := notifier.Message{
+		UserID:   user.UserID,
+		Username: params.Username,
+		Subject:  "PIN reset code",
+		Body:     fmt.Sprintf("Your PIN reset code is %s. It expires in %s. Never share this code.", code, expiry),
+	}
+	if err := s.notifier.Send(ctx, message); err != nil {
+		logger.Errorf("Failed to send PIN reset code to user %s: %v", user.UserID, err)
+		if err := s.repository.DeletePinReset(ctx, user.UserID); err != nil {
+			logger.Errorf("Failed to discard undelivered PIN reset for user %s: %v", user.UserID, err)
+		}
+		return nil
+	}
+
+	logger.Infof("PIN reset code sent to user %s", user.UserID)
+	return nil
+}
+
+// CompletePinReset sets the new PIN when the code matches. Each code tried
+// counts towards MaxAttempts before it is checked, and the reset is cancelled
+// after the last wrong one. On success the PIN lockout is cleared and every
+// session is revoked.
+func (s *pinResetService) CompletePinReset(ctx context.Context, params entities.PinResetCompleteParams) error {
+	user, err := s.repository.GetUserWithPin(params.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exception.ErrInvalidResetCode
+		}
+		return err
+	}
+
+	reset, err := s.repository.GetPinReset(ctx, user.UserID)
+	if err != nil {
+		logger.Errorf("Failed to get PIN reset for user %s: %v", user.UserID, err)
+		return exception.ErrServiceUnavailable
+	}
+	if reset == nil || time.Now().After(reset.ExpiresAt) {
+		return exception.ErrInvalidResetCode
+	}
+
+	attempts, err := s.repository.IncrementPinResetAttempts(ctx, user.UserID, time.Until(reset.ExpiresAt))
+	if err != nil {
+		logger.Errorf("Failed to count PIN reset attempts for user %s: %v", user.UserID, err)
+		return exception.ErrServiceUnavailable
+	}
+	if attempts > int64(s.maxAttempts()) {
+		return exception.ErrInvalidResetCode
+	}
+
+	if !isPinCorrect(reset.CodeHash, params.Code) {
+		if attempts == int64(s.maxAttempts()) {
+			s.cancelPinReset(ctx, user.UserID, attempts)
+		}
+		recordEvent(ctx, user.UserID, params.Username, entities.AuthEventPinReset, "wrong code", exception.ErrInvalidResetCode)
+		return exception.ErrInvalidResetCode
+	}
+
+	if err := replacePin(ctx, s.repository, s.config.Auth.Pin, user, params.Username, params.NewPin, "PIN reset"); err != nil {
+		recordEvent(ctx, user.UserID, params.Username, entities.AuthEventPinReset, "", err)
+		return err
+	}
+
+	if err := s.repository.DeletePinReset(ctx, user.UserID); err != nil {
+		logger.Errorf("Failed to delete used PIN reset for user %s: %v", user.UserID, err)
+	}
+	if err := s.repository.ClearPinAttempts(ctx, user.UserID); err != nil {
+		logger.Errorf("Failed to clear PIN attempts for user %s: %v", user.UserID, err)
+	}
+
+	recordEvent(ctx, user.UserID, params.Username, entities.AuthEventPinReset, "", nil)
+	logger.Infof("User %s reset their PIN", user.UserID)
+	return nil
+}
+
+func (s *pinResetService) cancelPinReset(ctx context.Context, userID string, attempts int64) {
+	logger.Warnf("Cancelling PIN reset for user %s after %d wrong codes", userID, attempts)
+	if err := s.repository.DeletePinReset(ctx, userID); err != nil {
+		logger.Errorf("Failed to cancel PIN reset for user %s: %v", userID, err)
+	}
+}
+
+func generateResetCode() (string, error) {
+	limit := big.NewInt(1_000_000)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", resetCodeDigits, n.Int64()), nil
+}
+
+func (s *pinResetService) resetConfig() *config.PinResetConfig {
+	if s.config.Auth.PinReset == nil {
+		return &config.PinResetConfig{}
+	}
+	return s.config.Auth.PinReset
+}
+
+func (s *pinResetService) codeExpiry() time.Duration {
+	if expiry := s.resetConfig().CodeExpiry; expiry > 0 {
+		return expiry
+	}
+	return defaultResetCodeExpiry
+}
+
+func (s *pinResetService) maxRequests() int {
+	if limit := s.resetConfig().MaxRequests; limit > 0 {
+		return limit
+	}
+	return defaultResetMaxRequests
+}
+
+func (s *pinResetService) requestWindow() time.Duration {
+	if window := s.resetConfig().RequestWindow; window > 0 {
+		return window
+	}
+	return defaultResetRequestWindow
+}
+
+func (s *pinResetService) maxAttempts() int {
+	if limit := s.resetConfig().MaxAttempts; limit > 0 {
+		return limit
+	}
+	return defaultResetMaxAttempts
+}
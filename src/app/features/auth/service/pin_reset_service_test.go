@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/notifier"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Send(ctx context.Context, msg notifier.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func createPinResetConfig() *config.Config {
+	return &config.Config{
+		Auth: &config.AuthConfig{
+			Pin: &config.PinConfig{HistorySize: 5},
+			PinReset: &config.PinResetConfig{
+				CodeExpiry:    10 * time.Minute,
+				MaxRequests:   3,
+				RequestWindow: time.Hour,
+				MaxAttempts:   3,
+			},
+		},
+	}
+}
+
+func TestPinResetService_StartPinReset(t *testing.T) {
+	errDatabase := errors.New("connection refused")
+	codePattern := regexp.MustCompile(`code is (\d{6})\.`)
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthRepository, *MockNotifier)
+		expectError error
+	}{
+		{
+			name: "code stored and sent",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(1), nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 3, nil, nil), nil)
+
+				var storedHash string
+				mockRepo.On("SavePinReset", mock.Anything, mock.MatchedBy(func(reset *entities.PinResetData) bool {
+					storedHash = reset.CodeHash
+					return reset.UserID == "user123" &&
+						time.Until(reset.ExpiresAt) > 9*time.Minute
+				})).Return(nil)
+				mockNotifier.On("Send", mock.MatchedBy(func(msg notifier.Message) bool {
+					// the message carries the code the stored hash was made from
+					match := codePattern.FindStringSubmatch(msg.Body)
+					return msg.UserID == "user123" && match != nil &&
+						bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(match[1])) == nil
+				})).Return(nil)
+			},
+		},
+		{
+			name: "unknown user is counted and succeeds without sending",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(1), nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "too many requests succeed without looking up the user",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(4), nil)
+			},
+		},
+		{
+			name: "counter error succeeds without sending",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(0), errors.New("connection refused"))
+			},
+		},
+		{
+			name: "undelivered code is discarded",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(1), nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+				mockRepo.On("SavePinReset", mock.Anything, mock.Anything).Return(nil)
+				mockNotifier.On("Send", mock.Anything).Return(errors.New("sms gateway down"))
+				mockRepo.On("DeletePinReset", mock.Anything, "user123").Return(nil)
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mockRepo *MockAuthRepository, mockNotifier *MockNotifier) {
+				mockRepo.On("IncrementPinResetRequests", mock.Anything, "testuser", time.Hour).Return(int64(1), nil)
+				mockRepo.On("GetUserWithPin", "testuser").Return(nil, errDatabase)
+			},
+			expectError: errDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockNotifier := new(MockNotifier)
+			tt.mockSetup(mockRepo, mockNotifier)
+
+			err := NewPinResetService(mockRepo, mockNotifier, createPinResetConfig()).
+				StartPinReset(context.Background(), entities.PinResetStartParams{Username: "testuser"})
+
+			assert.Equal(t, tt.expectError, err)
+			mockRepo.AssertExpectations(t)
+			mockNotifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPinResetService_CompletePinReset(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+	codeHash, _ := bcrypt.GenerateFromPassword([]byte("482913"), bcrypt.MinCost)
+	lockedUntil := time.Now().Add(5 * time.Minute)
+
+	pendingReset := func() *entities.PinResetData {
+		return &entities.PinResetData{UserID: "user123", CodeHash: string(codeHash), ExpiresAt: time.Now().Add(5 * time.Minute)}
+	}
+	attempt := func(mockRepo *MockAuthRepository, count int64) {
+		mockRepo.On("IncrementPinResetAttempts", mock.Anything, "user123", mock.AnythingOfType("time.Duration")).Return(count, nil)
+	}
+	lockedUser := func() *models.User {
+		return createTestUser("user123", "testuser", string(hashedPin), 5, &lockedUntil, nil)
+	}
+
+	tests := []struct {
+		name        string
+		params      entities.PinResetCompleteParams
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name:   "pin reset and lockout cleared",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(pendingReset(), nil)
+				attempt(mockRepo, 1)
+				mockRepo.On("GetPinHistory", "user123", 5).Return([]models.UserPinHistory{}, nil)
+				mockRepo.On("UpdatePin", "user123", mock.AnythingOfType("string"), string(hashedPin), 5).Return(nil)
+				mockRepo.On("InvalidateUserWithPin", mock.Anything, "testuser").Return(nil)
+				mockRepo.On("BanAllUserTokens", mock.Anything, "user123", "PIN reset").Return(nil)
+				mockRepo.On("DeletePinReset", mock.Anything, "user123").Return(nil)
+				mockRepo.On("ClearPinAttempts", mock.Anything, "user123").Return(nil)
+			},
+		},
+		{
+			name:   "no pending reset",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(nil, nil)
+			},
+			expectError: exception.ErrInvalidResetCode,
+		},
+		{
+			name:   "wrong code is counted",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "000000", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(pendingReset(), nil)
+				attempt(mockRepo, 1)
+			},
+			expectError: exception.ErrInvalidResetCode,
+		},
+		{
+			name:   "last wrong code cancels the reset",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "000000", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(pendingReset(), nil)
+				attempt(mockRepo, 3)
+				mockRepo.On("DeletePinReset", mock.Anything, "user123").Return(nil)
+			},
+			expectError: exception.ErrInvalidResetCode,
+		},
+		{
+			name:   "attempts over the limit are refused before the code is checked",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(pendingReset(), nil)
+				// a parallel guess used up the last attempt
+				attempt(mockRepo, 4)
+			},
+			expectError: exception.ErrInvalidResetCode,
+		},
+		{
+			name:   "weak new pin keeps the code usable",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "000000"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(lockedUser(), nil)
+				mockRepo.On("GetPinReset", mock.Anything, "user123").Return(pendingReset(), nil)
+				attempt(mockRepo, 1)
+			},
+			expectError: exception.NewWeakPinError("repeated digits"),
+		},
+		{
+			name:   "unknown user",
+			params: entities.PinResetCompleteParams{Username: "testuser", Code: "482913", NewPin: "724159"},
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserWithPin", "testuser").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrInvalidResetCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			tt.mockSetup(mockRepo)
+
+			err := NewPinResetService(mockRepo, new(MockNotifier), createPinResetConfig()).
+				CompletePinReset(context.Background(), tt.params)
+
+			assert.Equal(t, tt.expectError, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
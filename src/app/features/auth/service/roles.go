@@ -0,0 +1,46 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/auth/repository"
+)
+
+// loadRoles returns the role names of the user and the union of their
+// scopes, both sorted.
+func loadRoles(repo repository.AuthRepository, userID string) (roles, scopes []string, err error) {
+	granted, err := repo.GetUserRoles(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, role := range granted {
+		roles = append(roles, role.Name)
+		for _, scope := range role.Scopes {
+			if !seen[scope.Scope] {
+				seen[scope.Scope] = true
+				scopes = append(scopes, scope.Scope)
+			}
+		}
+	}
+	sort.Strings(scopes)
+	return roles, scopes, nil
+}
+
+// newSessionOptions returns the options for a session started with a PIN
+// entered just now, carrying the current roles of the user.
+func (s *authService) newSessionOptions(userID, deviceID string) (entities.TokenOptions, error) {
+	roles, scopes, err := loadRoles(s.repository, userID)
+	if err != nil {
+		return entities.TokenOptions{}, err
+	}
+	return entities.TokenOptions{
+		AuthTime: time.Now().Unix(),
+		DeviceID: deviceID,
+		Roles:    roles,
+		Scopes:   scopes,
+	}, nil
+}
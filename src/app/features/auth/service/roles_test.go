@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoadRoles(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("GetUserRoles", "user123").Return([]models.Role{
+		{Name: entities.RoleAdmin, Scopes: []models.RoleScope{{Scope: entities.ScopeUsersRead}, {Scope: entities.ScopeTokensBan}}},
+		{Name: entities.RoleSupport, Scopes: []models.RoleScope{{Scope: entities.ScopeUsersRead}, {Scope: entities.ScopeAuditRead}}},
+	}, nil)
+	mockRepo.On("GetUserRoles", "user456").Return([]models.Role{}, nil)
+	mockRepo.On("GetUserRoles", "user789").Return(nil, errors.New("db down"))
+
+	roles, scopes, err := loadRoles(mockRepo, "user123")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entities.RoleAdmin, entities.RoleSupport}, roles)
+	assert.Equal(t, []string{entities.ScopeAuditRead, entities.ScopeTokensBan, entities.ScopeUsersRead}, scopes)
+
+	roles, scopes, err = loadRoles(mockRepo, "user456")
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+	assert.Empty(t, scopes)
+
+	_, _, err = loadRoles(mockRepo, "user789")
+	assert.Error(t, err)
+}
+
+func TestAuthService_AdminBanTokens(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("BanAllUserTokens", mock.Anything, "user456", "Banned by operator admin1").Return(nil)
+
+	err := NewAuthService(mockRepo, new(MockJwtService), new(MockNotifier), &config.Config{}).
+		AdminBanTokens(context.Background(), entities.Claims{UserID: "admin1", Username: "admin"}, "user456")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/totp"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount             = 10
+	recoveryCodeLength            = 10 // base32 characters, 50 bits
+	defaultTwoFactorIssuer        = "Banking API"
+	defaultTwoFactorMaxAttempts   = 5
+	defaultTwoFactorAttemptWindow = 15 * time.Minute
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type twoFactorService struct {
+	config      *config.Config
+	repository  repository.AuthRepository
+	jwtService  JwtService
+	authService AuthService
+}
+
+// TwoFactorService enrolls authenticator apps and upgrades a session to one
+// that passed the second factor.
+type TwoFactorService interface {
+	Enroll(ctx context.Context, userID, username string, params entities.TwoFactorEnrollParams) (*entities.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, claims entities.Claims, params entities.TwoFactorConfirmParams) (*entities.TokenResponse, error)
+	Verify(ctx context.Context, claims entities.Claims, params entities.TwoFactorVerifyParams) (*entities.TokenResponse, error)
+}
+
+func NewTwoFactorService(repository repository.AuthRepository, jwtService JwtService, authService AuthService, config *config.Config) TwoFactorService {
+	return &twoFactorService{repository: repository, jwtService: jwtService, authService: authService, config: config}
+}
+
+// Enroll creates a new secret and recovery codes after re-checking the PIN.
+// The enrollment stays pending until Confirm; enrolling again before that
+// replaces it.
+func (s *twoFactorService) Enroll(ctx context.Context, userID, username string, params entities.TwoFactorEnrollParams) (*entities.TwoFactorEnrollment, error) {
+	if err := s.authService.ConfirmPin(ctx, userID, username, params.Pin); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repository.GetUserTotp(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, exception.NewDatabaseError(err)
+	}
+	if existing != nil && existing.ConfirmedAt != nil {
+		return nil, exception.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+
+	if err := s.repository.SaveTotpEnrollment(&models.UserTotp{UserID: userID, Secret: secret}, hashes); err != nil {
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	logger.Infof("User %s started two-factor enrollment", userID)
+	return &entities.TwoFactorEnrollment{
+		Secret:        secret,
+		OtpauthURI:    totp.ProvisioningURI(s.issuer(), username, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Confirm activates a pending enrollment with a first code from the app and
+// returns a token pair that passed the second factor. The tokens of the
+// session it replaces are banned.
+func (s *twoFactorService) Confirm(ctx context.Context, claims entities.Claims, params entities.TwoFactorConfirmParams) (*entities.TokenResponse, error) {
+	userID := claims.UserID
+	userTotp, err := s.getUserTotp(userID)
+	if err != nil {
+		return nil, err
+	}
+	if userTotp.ConfirmedAt != nil {
+		return nil, exception.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := s.countAttempt(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(userTotp.Secret, params.Code, time.Now())
+	if !ok {
+		return nil, exception.ErrInvalidTwoFactorCode
+	}
+	if err := s.repository.ConfirmUserTotp(userID, step); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrTwoFactorAlreadyEnabled
+		}
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	logger.Infof("User %s enabled two-factor authentication", userID)
+	return s.issueTokens(ctx, claims)
+}
+
+// Verify checks a TOTP code, or a recovery code when no code is given, and
+// returns a token pair that passed the second factor. Each code works once.
+// The auth time of the session is kept, since no PIN was entered, and the
+// tokens of the session it replaces are banned.
+func (s *twoFactorService) Verify(ctx context.Context, claims entities.Claims, params entities.TwoFactorVerifyParams) (*entities.TokenResponse, error) {
+	userID := claims.UserID
+	userTotp, err := s.getUserTotp(userID)
+	if err != nil {
+		return nil, err
+	}
+	if userTotp.ConfirmedAt == nil {
+		return nil, exception.ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.countAttempt(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if params.Code != "" {
+		ok, err = s.useCode(userTotp, params.Code)
+	} else {
+		ok, err = s.useRecoveryCode(userID, params.RecoveryCode)
+	}
+	if err != nil {
+		return nil, exception.NewDatabaseError(err)
+	}
+	if !ok {
+		return nil, exception.ErrInvalidTwoFactorCode
+	}
+
+	return s.issueTokens(ctx, claims)
+}
+
+func (s *twoFactorService) getUserTotp(userID string) (*models.UserTotp, error) {
+	userTotp, err := s.repository.GetUserTotp(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrTwoFactorNotEnrolled
+		}
+		return nil, exception.NewDatabaseError(err)
+	}
+	return userTotp, nil
+}
+
+// countAttempt limits how many codes a user can try per window, since six
+// digits are easy to brute force otherwise.
+func (s *twoFactorService) countAttempt(ctx context.Context, userID string) error {
+	attempts, err := s.repository.IncrementTwoFactorAttempts(ctx, userID, s.attemptWindow())
+	if err != nil {
+		logger.Errorf("Failed to count two-factor attempts for user %s: %v", userID, err)
+		return exception.ErrServiceUnavailable
+	}
+	if attempts > int64(s.maxAttempts()) {
+		logger.Warnf("Too many two-factor attempts for user %s", userID)
+		return exception.ErrTooManyTwoFactorAttempts
+	}
+	return nil
+}
+
+func (s *twoFactorService) useCode(userTotp *models.UserTotp, code string) (bool, error) {
+	step, ok := totp.Validate(userTotp.Secret, code, time.Now())
+	if !ok || step <= userTotp.LastUsedStep {
+		return false, nil
+	}
+	return s.repository.UpdateTotpLastUsedStep(userTotp.UserID, step)
+}
+
+func (s *twoFactorService) useRecoveryCode(userID, code string) (bool, error) {
+	codes, err := s.repository.GetUnusedRecoveryCodes(userID)
+	if err != nil {
+		return false, err
+	}
+	normalized := normalizeRecoveryCode(code)
+	for _, candidate := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(candidate.CodeHash), []byte(normalized)) != nil {
+			continue
+		}
+		used, err := s.repository.UseRecoveryCode(candidate.ID)
+		if used {
+			logger.Infof("User %s used a recovery code, %d left", userID, len(codes)-1)
+		}
+		return used, err
+	}
+	return false, nil
+}
+
+// issueTokens upgrades the session of claims to one that passed the second
+// factor. Its old access and refresh tokens are banned so the weaker session
+// cannot be refreshed any further.
+func (s *twoFactorService) issueTokens(ctx context.Context, claims entities.Claims) (*entities.TokenResponse, error) {
+	userID := claims.UserID
+	if err := s.repository.ResetTwoFactorAttempts(ctx, userID); err != nil {
+		logger.Errorf("Failed to reset two-factor attempts for user %s: %v", userID, err)
+	}
+
+	session := claims.TokenOptions()
+	session.TwoFactor = true
+	tokenResponse, err := s.jwtService.GenerateTokensWithOptions(userID, claims.Username, session)
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	tokenResponse.UserID = userID
+	if err := s.repository.StoreToken(ctx, userID, tokenResponse); err != nil {
+		logger.Errorf("Failed to store token in Redis for user %s: %v", userID, err)
+	}
+	if err := s.repository.BanTokens(ctx, userID, "Replaced by two-factor verification", claims.TokenID, claims.RefreshTokenID); err != nil {
+		logger.Errorf("Failed to ban replaced tokens of user %s: %v", userID, err)
+	}
+	return tokenResponse, nil
+}
+
+// generateRecoveryCodes returns the codes to show once, formatted as
+// xxxxx-xxxxx, and the bcrypt hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeLength*5/8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(raw)
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		half := recoveryCodeLength / 2
+		codes = append(codes, strings.ToLower(code[:half]+"-"+code[half:]))
+		hashes = append(hashes, string(hash))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts codes typed in any case, with or without the
+// dash.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func (s *twoFactorService) twoFactorConfig() *config.TwoFactorConfig {
+	if s.config.Auth.TwoFactor == nil {
+		return &config.TwoFactorConfig{}
+	}
+	return s.config.Auth.TwoFactor
+}
+
+func (s *twoFactorService) issuer() string {
+	if issuer := s.twoFactorConfig().Issuer; issuer != "" {
+		return issuer
+	}
+	return defaultTwoFactorIssuer
+}
+
+func (s *twoFactorService) maxAttempts() int {
+	if limit := s.twoFactorConfig().MaxAttempts; limit > 0 {
+		return limit
+	}
+	return defaultTwoFactorMaxAttempts
+}
+
+func (s *twoFactorService) attemptWindow() time.Duration {
+	if window := s.twoFactorConfig().AttemptWindow; window > 0 {
+		return window
+	}
+	return defaultTwoFactorAttemptWindow
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/totp"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const testTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func createTwoFactorConfig() *config.Config {
+	return &config.Config{
+		Auth: &config.AuthConfig{
+			Pin: &config.PinConfig{LockThreshold: 3},
+			TwoFactor: &config.TwoFactorConfig{
+				Issuer:        "Test Bank",
+				MaxAttempts:   5,
+				AttemptWindow: 15 * time.Minute,
+			},
+		},
+	}
+}
+
+func newTestTwoFactorService(mockRepo *MockAuthRepository, mockJwt *MockJwtService) TwoFactorService {
+	cfg := createTwoFactorConfig()
+	return NewTwoFactorService(mockRepo, mockJwt, NewAuthService(mockRepo, mockJwt, new(MockNotifier), cfg), cfg)
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	hashedPin, _ := bcrypt.GenerateFromPassword([]byte("135790"), bcrypt.MinCost)
+	confirmedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthRepository)
+		expectError error
+	}{
+		{
+			name: "new enrollment",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserTotp", "user123").Return(nil, gorm.ErrRecordNotFound)
+				mockRepo.On("SaveTotpEnrollment", mock.MatchedBy(func(userTotp *models.UserTotp) bool {
+					return userTotp.UserID == "user123" && userTotp.Secret != "" && userTotp.ConfirmedAt == nil
+				}), mock.MatchedBy(func(hashes []string) bool {
+					return len(hashes) == recoveryCodeCount
+				})).Return(nil)
+			},
+		},
+		{
+			name: "already enabled",
+			mockSetup: func(mockRepo *MockAuthRepository) {
+				mockRepo.On("GetUserTotp", "user123").Return(&models.UserTotp{UserID: "user123", Secret: testTotpSecret, ConfirmedAt: &confirmedAt}, nil)
+			},
+			expectError: exception.ErrTwoFactorAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockRepo.On("GetUserWithPin", "testuser").Return(createTestUser("user123", "testuser", string(hashedPin), 0, nil, nil), nil)
+			mockRepo.On("GetPinAttemptData", mock.Anything, "user123").Return(&entities.PinAttemptData{UserID: "user123"}, nil)
+			mockRepo.On("ResetPinAttempts", mock.Anything, "user123").Return(nil)
+			tt.mockSetup(mockRepo)
+
+			enrollment, err := newTestTwoFactorService(mockRepo, new(MockJwtService)).
+				Enroll(context.Background(), "user123", "testuser", entities.TwoFactorEnrollParams{Pin: "135790"})
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(enrollment.OtpauthURI, "otpauth://totp/Test%20Bank:testuser?"))
+				assert.Len(t, enrollment.RecoveryCodes, recoveryCodeCount)
+				assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, enrollment.RecoveryCodes[0])
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTwoFactorService_Confirm(t *testing.T) {
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(testTotpSecret, step)
+
+	mockRepo := new(MockAuthRepository)
+	mockJwt := new(MockJwtService)
+	mockRepo.On("GetUserTotp", "user123").Return(&models.UserTotp{UserID: "user123", Secret: testTotpSecret}, nil)
+	mockRepo.On("IncrementTwoFactorAttempts", mock.Anything, "user123", 15*time.Minute).Return(int64(1), nil)
+	mockRepo.On("ConfirmUserTotp", "user123", mock.AnythingOfType("int64")).Return(nil)
+	mockRepo.On("ResetTwoFactorAttempts", mock.Anything, "user123").Return(nil)
+	mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", entities.TokenOptions{TwoFactor: true, FamilyID: "family1"}).
+		Return(&entities.TokenResponse{Token: "2fa_token", TwoFactor: true}, nil)
+	mockRepo.On("StoreToken", mock.Anything, "user123", mock.Anything).Return(nil)
+	// the session without the second factor ends
+	mockRepo.On("BanTokens", mock.Anything, "user123", "Replaced by two-factor verification", []string{"token1", "refresh1"}).Return(nil)
+
+	caller := entities.Claims{UserID: "user123", Username: "testuser", TokenID: "token1", RefreshTokenID: "refresh1", FamilyID: "family1"}
+	tokenResponse, err := newTestTwoFactorService(mockRepo, mockJwt).
+		Confirm(context.Background(), caller, entities.TwoFactorConfirmParams{Code: code})
+
+	assert.NoError(t, err)
+	assert.True(t, tokenResponse.TwoFactor)
+	mockRepo.AssertExpectations(t)
+	mockJwt.AssertExpectations(t)
+}
+
+func TestTwoFactorService_Verify(t *testing.T) {
+	confirmedAt := time.Now().Add(-time.Hour)
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(testTotpSecret, step)
+	recoveryHash, _ := bcrypt.GenerateFromPassword([]byte("ABCDEFGHIJ"), bcrypt.MinCost)
+	enrolled := func(lastUsedStep int64) *models.UserTotp {
+		return &models.UserTotp{UserID: "user123", Secret: testTotpSecret, ConfirmedAt: &confirmedAt, LastUsedStep: lastUsedStep}
+	}
+	caller := entities.Claims{UserID: "user123", Username: "testuser", TokenID: "token1", RefreshTokenID: "refresh1", AuthTime: 1725175440}
+	issuesTokens := func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+		mockRepo.On("ResetTwoFactorAttempts", mock.Anything, "user123").Return(nil)
+		// the auth time of the session is kept
+		mockJwt.On("GenerateTokensWithOptions", "user123", "testuser", entities.TokenOptions{TwoFactor: true, AuthTime: 1725175440}).
+			Return(&entities.TokenResponse{Token: "2fa_token", TwoFactor: true}, nil)
+		mockRepo.On("StoreToken", mock.Anything, "user123", mock.Anything).Return(nil)
+		mockRepo.On("BanTokens", mock.Anything, "user123", "Replaced by two-factor verification", []string{"token1", "refresh1"}).Return(nil)
+	}
+
+	tests := []struct {
+		name        string
+		params      entities.TwoFactorVerifyParams
+		mockSetup   func(*MockAuthRepository, *MockJwtService)
+		expectError error
+	}{
+		{
+			name:   "valid code",
+			params: entities.TwoFactorVerifyParams{Code: code},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserTotp", "user123").Return(enrolled(0), nil)
+				mockRepo.On("IncrementTwoFactorAttempts", mock.Anything, "user123", 15*time.Minute).Return(int64(1), nil)
+				mockRepo.On("UpdateTotpLastUsedStep", "user123", mock.AnythingOfType("int64")).Return(true, nil)
+				issuesTokens(mockRepo, mockJwt)
+			},
+		},
+		{
+			name:   "replayed code",
+			params: entities.TwoFactorVerifyParams{Code: code},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserTotp", "user123").Return(enrolled(step+totp.Skew), nil)
+				mockRepo.On("IncrementTwoFactorAttempts", mock.Anything, "user123", 15*time.Minute).Return(int64(2), nil)
+			},
+			expectError: exception.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:   "recovery code in any format",
+			params: entities.TwoFactorVerifyParams{RecoveryCode: "abcde-fghij"},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserTotp", "user123").Return(enrolled(0), nil)
+				mockRepo.On("IncrementTwoFactorAttempts", mock.Anything, "user123", 15*time.Minute).Return(int64(1), nil)
+				mockRepo.On("GetUnusedRecoveryCodes", "user123").Return([]models.UserRecoveryCode{
+					{ID: 1, UserID: "user123", CodeHash: "not-a-match"},
+					{ID: 2, UserID: "user123", CodeHash: string(recoveryHash)},
+				}, nil)
+				mockRepo.On("UseRecoveryCode", uint(2)).Return(true, nil)
+				issuesTokens(mockRepo, mockJwt)
+			},
+		},
+		{
+			name:   "pending enrollment",
+			params: entities.TwoFactorVerifyParams{Code: code},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserTotp", "user123").Return(&models.UserTotp{UserID: "user123", Secret: testTotpSecret}, nil)
+			},
+			expectError: exception.ErrTwoFactorNotEnrolled,
+		},
+		{
+			name:   "too many attempts",
+			params: entities.TwoFactorVerifyParams{Code: code},
+			mockSetup: func(mockRepo *MockAuthRepository, mockJwt *MockJwtService) {
+				mockRepo.On("GetUserTotp", "user123").Return(enrolled(0), nil)
+				mockRepo.On("IncrementTwoFactorAttempts", mock.Anything, "user123", 15*time.Minute).Return(int64(6), nil)
+			},
+			expectError: exception.ErrTooManyTwoFactorAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuthRepository)
+			mockJwt := new(MockJwtService)
+			tt.mockSetup(mockRepo, mockJwt)
+
+			tokenResponse, err := newTestTwoFactorService(mockRepo, mockJwt).
+				Verify(context.Background(), caller, tt.params)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+				assert.Nil(t, tokenResponse)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, tokenResponse.TwoFactor)
+			}
+			mockRepo.AssertExpectations(t)
+			mockJwt.AssertExpectations(t)
+		})
+	}
+}
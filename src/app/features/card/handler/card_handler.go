@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/card/service"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type cardHandler struct {
+	service service.CardService
+}
+
+var cardActionMessages = map[string]string{
+	entities.CardActionFreeze:     "Card frozen successfully",
+	entities.CardActionUnfreeze:   "Card unfrozen successfully",
+	entities.CardActionReportLost: "Card reported lost successfully",
+	entities.CardActionReplace:    "Card replaced successfully",
+	entities.CardActionClose:      "Card closed successfully",
+}
+
+func NewCardHandler(router fiber.Router, service service.CardService) {
+	handler := &cardHandler{
+		service: service,
+	}
+
+	cards := router.Group("/cards")
+	cards.Get("/", middlewares.AuthMiddleware(), handler.ListCards)
+	for _, action := range []string{
+		entities.CardActionFreeze,
+		entities.CardActionUnfreeze,
+		entities.CardActionReportLost,
+		entities.CardActionReplace,
+		entities.CardActionClose,
+	} {
+		cards.Post("/:cardID/"+action, middlewares.AuthMiddleware(), middlewares.IdempotencyMiddleware(), handler.ChangeStatus(action))
+	}
+	cards.Post("/:cardID/reveal", middlewares.AuthMiddleware(), middlewares.RequireTwoFactor(), handler.RevealCard)
+}
+
+func (h *cardHandler) ListCards(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	cards, err := h.service.ListCards(user.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Cards retrieved successfully",
+		Data:    cards,
+	})
+}
+
+func (h *cardHandler) ChangeStatus(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(entities.Claims)
+		if !ok {
+			return exception.ErrInternalServer
+		}
+
+		var req entities.CardActionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return exception.ErrValidationFailed
+		}
+
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		card, err := h.service.ChangeStatus(c.Context(), entities.CardActionParams{
+			UserID:    user.UserID,
+			Username:  user.Username,
+			CardID:    c.Params("cardID"),
+			Action:    action,
+			Pin:       req.Pin,
+			IPAddress: c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		})
+		if err != nil {
+			return err
+		}
+
+		status := fiber.StatusOK
+		if action == entities.CardActionReplace {
+			status = fiber.StatusCreated
+		}
+
+		return c.Status(status).JSON(&response.SuccessResponse{
+			Code:    response.Success,
+			Message: cardActionMessages[action],
+			Data:    card,
+		})
+	}
+}
+
+func (h *cardHandler) RevealCard(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var req entities.CardActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	card, err := h.service.RevealCard(c.Context(), entities.CardActionParams{
+		UserID:    user.UserID,
+		Username:  user.Username,
+		CardID:    c.Params("cardID"),
+		Action:    entities.CardActionReveal,
+		Pin:       req.Pin,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the full number must not end up in a shared cache
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Card number revealed successfully",
+		Data:    card,
+	})
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockCardService implements the card service interface for testing
+type MockCardService struct {
+	mock.Mock
+}
+
+func (m *MockCardService) ListCards(userID string) ([]entities.DebitCards, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.DebitCards), args.Error(1)
+}
+
+func (m *MockCardService) ChangeStatus(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.DebitCards), args.Error(1)
+}
+
+func (m *MockCardService) RevealCard(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.DebitCards), args.Error(1)
+}
+
+func setupTestApp(handler *cardHandler) *fiber.App {
+	logger.Logger = zap.NewNop().Sugar()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler(),
+	})
+	setUser := func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return c.Next()
+	}
+	app.Get("/cards", setUser, handler.ListCards)
+	app.Post("/cards/:cardID/freeze", setUser, handler.ChangeStatus(entities.CardActionFreeze))
+	app.Post("/cards/:cardID/replace", setUser, handler.ChangeStatus(entities.CardActionReplace))
+	app.Post("/cards/:cardID/reveal", setUser, handler.RevealCard)
+	return app
+}
+
+func TestCardHandler_ListCards(t *testing.T) {
+	mockService := new(MockCardService)
+	mockService.On("ListCards", "user123").Return([]entities.DebitCards{{CardID: "card1", Status: "active"}}, nil)
+	app := setupTestApp(&cardHandler{service: mockService})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/cards", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		mockSetup      func(*MockCardService)
+		expectedStatus int
+	}{
+		{
+			name: "freeze",
+			path: "/cards/card1/freeze",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("ChangeStatus", mock.MatchedBy(func(p entities.CardActionParams) bool {
+					return p.UserID == "user123" && p.Username == "testuser" && p.CardID == "card1" &&
+						p.Action == entities.CardActionFreeze && p.Pin == "123456" && p.UserAgent == "test-agent"
+				})).Return(&entities.DebitCards{CardID: "card1", Status: "frozen"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "replace creates a card",
+			path: "/cards/card1/replace",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("ChangeStatus", mock.Anything).Return(&entities.DebitCards{CardID: "card2", ReplacesCardID: "card1"}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "missing pin",
+			path:           "/cards/card1/freeze",
+			body:           `{}`,
+			mockSetup:      func(m *MockCardService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "invalid transition",
+			path: "/cards/card1/freeze",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("ChangeStatus", mock.Anything).Return(nil, exception.NewInvalidCardTransitionError("freeze", "closed"))
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "wrong pin",
+			path: "/cards/card1/freeze",
+			body: `{"pin":"000000"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("ChangeStatus", mock.Anything).Return(nil, exception.NewInvalidPinError(2))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCardService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&cardHandler{service: mockService})
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCardHandler_RevealCard(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockCardService)
+		expectedStatus int
+	}{
+		{
+			name: "reveal",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("RevealCard", mock.MatchedBy(func(p entities.CardActionParams) bool {
+					return p.UserID == "user123" && p.CardID == "card1" && p.Action == entities.CardActionReveal && p.Pin == "123456"
+				})).Return(&entities.DebitCards{CardID: "card1", CardNumber: "4000 1234 5678 9010"}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid pin format",
+			body:           `{"pin":"12ab"}`,
+			mockSetup:      func(m *MockCardService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "card not found",
+			body: `{"pin":"123456"}`,
+			mockSetup: func(m *MockCardService) {
+				m.On("RevealCard", mock.Anything).Return(nil, exception.ErrCardNotFound)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCardService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&cardHandler{service: mockService})
+
+			req := httptest.NewRequest("POST", "/cards/card1/reveal", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == fiber.StatusOK {
+				assert.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/models"
+	"gorm.io/gorm"
+)
+
+// ErrCardStatusChanged means the card left the expected status between
+// reading it and applying the change.
+var ErrCardStatusChanged = errors.New("card status changed concurrently")
+
+type cardRepository struct {
+	db *gorm.DB
+}
+
+type CardRepository interface {
+	ListCards(userID string) ([]models.DebitCard, error)
+	GetCard(userID, cardID string) (*models.DebitCard, error)
+	UpdateStatus(card *models.DebitCard, toStatus string, audit *models.CardAuditLog) error
+	ReplaceCard(card *models.DebitCard, newCard *models.DebitCard, audit *models.CardAuditLog) error
+	CreateAuditLog(audit *models.CardAuditLog) error
+	CardNumberExists(number string) (bool, error)
+}
+
+func NewCardRepository(db *gorm.DB) CardRepository {
+	return &cardRepository{
+		db: db,
+	}
+}
+
+func (r *cardRepository) cardsQuery(userID string) *gorm.DB {
+	return r.db.
+		Preload("DebitCardDetail").
+		Preload("DebitCardDesign").
+		Preload("DebitCardStatus").
+		Where("user_id = ?", userID)
+}
+
+func (r *cardRepository) ListCards(userID string) ([]models.DebitCard, error) {
+	var cards []models.DebitCard
+	if err := r.cardsQuery(userID).Order("card_id ASC").Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *cardRepository) GetCard(userID, cardID string) (*models.DebitCard, error) {
+	var card models.DebitCard
+	if err := r.cardsQuery(userID).Where("card_id = ?", cardID).First(&card).Error; err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// setStatus only updates the card while it still has the status it was read
+// with, so two concurrent changes cannot both pass the transition check.
+func setStatus(tx *gorm.DB, card *models.DebitCard, toStatus string) error {
+	result := tx.Model(&models.DebitCardStatus{}).
+		Where("card_id = ? AND status = ?", card.CardID, card.DebitCardStatus.Status).
+		Update("status", toStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCardStatusChanged
+	}
+	return nil
+}
+
+func (r *cardRepository) UpdateStatus(card *models.DebitCard, toStatus string, audit *models.CardAuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setStatus(tx, card, toStatus); err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+// ReplaceCard marks the card replaced and creates the new card with its
+// detail, design and status rows in one transaction.
+func (r *cardRepository) ReplaceCard(card *models.DebitCard, newCard *models.DebitCard, audit *models.CardAuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setStatus(tx, card, models.CardStatusReplaced); err != nil {
+			return err
+		}
+		if err := tx.Create(newCard).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+func (r *cardRepository) CreateAuditLog(audit *models.CardAuditLog) error {
+	return r.db.Create(audit).Error
+}
+
+// CardNumberExists looks the number up by its blind index, since the number
+// column itself is encrypted.
+func (r *cardRepository) CardNumberExists(number string) (bool, error) {
+	index, err := encryption.BlindIndex(number)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := r.db.Model(&models.DebitCardDetail{}).Where("number_index = ?", index).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func setupKeyring(t *testing.T) *encryption.Keyring {
+	keyring, err := encryption.NewKeyring("dk1", map[string][]byte{"dk1": bytes.Repeat([]byte{1}, 32)}, bytes.Repeat([]byte{2}, 32))
+	assert.NoError(t, err)
+
+	encryption.SetDefault(keyring)
+	t.Cleanup(func() { encryption.SetDefault(nil) })
+	return keyring
+}
+
+// encryptedArg matches a value the encrypted serializer wrote for plaintext.
+type encryptedArg struct {
+	plaintext string
+}
+
+func (a encryptedArg) Match(v driver.Value) bool {
+	value, ok := v.(string)
+	if !ok || !encryption.IsEncrypted(value) {
+		return false
+	}
+	plaintext, err := encryption.Default().Decrypt(value)
+	return err == nil && plaintext == a.plaintext
+}
+
+func testCard(status string) *models.DebitCard {
+	return &models.DebitCard{
+		CardID:          "card1",
+		UserID:          "user123",
+		DebitCardStatus: models.DebitCardStatus{CardID: "card1", UserID: "user123", Status: status},
+	}
+}
+
+func testAudit(toStatus string) *models.CardAuditLog {
+	return &models.CardAuditLog{
+		CardID:     "card1",
+		UserID:     "user123",
+		Action:     "freeze",
+		FromStatus: models.CardStatusActive,
+		ToStatus:   toStatus,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func TestCardRepository_GetCard(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT \\* FROM `debit_cards` WHERE user_id = \\? AND card_id = \\? ORDER BY `debit_cards`\\.`card_id` LIMIT \\?").
+		WithArgs("user123", "card1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "user_id", "name"}).AddRow("card1", "user123", "My Card"))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_design` WHERE `debit_card_design`\\.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "color"}).AddRow("card1", "#000000"))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_details` WHERE `debit_card_details`\\.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "number"}).AddRow("card1", "4000 1234 5678 9010"))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_status` WHERE `debit_card_status`\\.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "status"}).AddRow("card1", "active"))
+
+	card, err := NewCardRepository(gormDB).GetCard("user123", "card1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "My Card", card.Name)
+	assert.Equal(t, "4000 1234 5678 9010", card.DebitCardDetail.Number)
+	assert.Equal(t, "active", card.DebitCardStatus.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCardRepository_UpdateStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expectError  error
+	}{
+		{name: "status updated and audited", rowsAffected: 1},
+		{name: "status changed concurrently", rowsAffected: 0, expectError: ErrCardStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE `debit_card_status` SET `status`=\\? WHERE card_id = \\? AND status = \\?").
+				WithArgs(models.CardStatusFrozen, "card1", models.CardStatusActive).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			if tt.expectError == nil {
+				mock.ExpectExec("INSERT INTO `card_audit_logs`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := NewCardRepository(gormDB).UpdateStatus(testCard(models.CardStatusActive), models.CardStatusFrozen, testAudit(models.CardStatusFrozen))
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCardRepository_ReplaceCard(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+	keyring := setupKeyring(t)
+
+	oldID := "card1"
+	newCard := &models.DebitCard{
+		CardID:          "card2",
+		UserID:          "user123",
+		Name:            "My Card",
+		ReplacesCardID:  &oldID,
+		DebitCardDetail: models.DebitCardDetail{CardID: "card2", UserID: "user123", Number: "4000 0000 0000 0002"},
+		DebitCardDesign: models.DebitCardDesign{CardID: "card2", UserID: "user123", Color: "#000000"},
+		DebitCardStatus: models.DebitCardStatus{CardID: "card2", UserID: "user123", Status: models.CardStatusActive},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `debit_card_status` SET `status`=\\? WHERE card_id = \\? AND status = \\?").
+		WithArgs(models.CardStatusReplaced, "card1", models.CardStatusLost).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `debit_cards`").
+		WithArgs("card2", "user123", "My Card", "card1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `debit_card_details` \\(`card_id`,`user_id`,`issuer`,`number`,`number_index`\\)").
+		WithArgs("card2", "user123", "", encryptedArg{"4000 0000 0000 0002"}, keyring.BlindIndex("4000000000000002")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `debit_card_design`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `debit_card_status`").
+		WithArgs("card2", "user123", models.CardStatusActive).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `card_audit_logs`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := NewCardRepository(gormDB).ReplaceCard(testCard(models.CardStatusLost), newCard, testAudit(models.CardStatusReplaced))
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCardRepository_ReplaceCard_RollsBackOnError(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `debit_card_status`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `debit_cards`").WillReturnError(errors.New("duplicate entry"))
+	mock.ExpectRollback()
+
+	err := NewCardRepository(gormDB).ReplaceCard(testCard(models.CardStatusActive), &models.DebitCard{CardID: "card2"}, testAudit(models.CardStatusReplaced))
+
+	assert.EqualError(t, err, "duplicate entry")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCardRepository_CardNumberExists(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+	keyring := setupKeyring(t)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `debit_card_details` WHERE number_index = \\?").
+		WithArgs(keyring.BlindIndex("4000 1234 5678 9010")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	exists, err := NewCardRepository(gormDB).CardNumberExists("4000-1234-5678-9010")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
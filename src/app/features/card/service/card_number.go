@@ -0,0 +1,72 @@
+package service
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	cardNumberLength = 16
+	cardBINLength    = 6
+	defaultCardBIN   = "400000"
+)
+
+// newCardNumber generates a Luhn-valid number on the same BIN as the card it
+// replaces, keeping the old number's grouping when it was space separated.
+func newCardNumber(oldNumber string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, oldNumber)
+
+	bin := defaultCardBIN
+	if len(digits) >= cardBINLength {
+		bin = digits[:cardBINLength]
+	}
+
+	var b strings.Builder
+	b.WriteString(bin)
+	for b.Len() < cardNumberLength-1 {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + n.Int64()))
+	}
+	number := b.String()
+	number += string(rune('0' + luhnCheckDigit(number)))
+
+	if strings.Contains(oldNumber, " ") {
+		return groupDigits(number, 4), nil
+	}
+	return number, nil
+}
+
+func luhnCheckDigit(payload string) int {
+	sum := 0
+	double := true
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+func groupDigits(number string, size int) string {
+	var groups []string
+	for len(number) > size {
+		groups = append(groups, number[:size])
+		number = number[size:]
+	}
+	return strings.Join(append(groups, number), " ")
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/card/repository"
+	"github.com/Testzyler/banking-api/app/mask"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxCardNumberAttempts bounds how many numbers replace draws before giving up
+const maxCardNumberAttempts = 5
+
+// PinVerifier re-checks the signed-in user's PIN, the auth service
+// implements it.
+type PinVerifier interface {
+	ConfirmPin(ctx context.Context, userID, username, pin string) error
+}
+
+type cardService struct {
+	repo        repository.CardRepository
+	pinVerifier PinVerifier
+}
+
+type CardService interface {
+	ListCards(userID string) ([]entities.DebitCards, error)
+	ChangeStatus(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error)
+	RevealCard(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error)
+}
+
+func NewCardService(repo repository.CardRepository, pinVerifier PinVerifier) CardService {
+	return &cardService{
+		repo:        repo,
+		pinVerifier: pinVerifier,
+	}
+}
+
+func (s *cardService) ListCards(userID string) ([]entities.DebitCards, error) {
+	cards, err := s.repo.ListCards(userID)
+	if err != nil {
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	result := make([]entities.DebitCards, 0, len(cards))
+	for _, card := range cards {
+		result = append(result, toCardEntity(card))
+	}
+	return result, nil
+}
+
+// ChangeStatus applies a card action after re-checking the PIN. For replace
+// it returns the new card, otherwise the updated one.
+func (s *cardService) ChangeStatus(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error) {
+	if err := s.pinVerifier.ConfirmPin(ctx, params.UserID, params.Username, params.Pin); err != nil {
+		return nil, err
+	}
+
+	card, err := s.repo.GetCard(params.UserID, params.CardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrCardNotFound
+		}
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	fromStatus := card.DebitCardStatus.Status
+	toStatus, ok := nextStatus(params.Action, fromStatus)
+	if !ok {
+		return nil, exception.NewInvalidCardTransitionError(params.Action, fromStatus)
+	}
+
+	audit := &models.CardAuditLog{
+		CardID:     card.CardID,
+		UserID:     params.UserID,
+		Action:     params.Action,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		IPAddress:  params.IPAddress,
+		UserAgent:  params.UserAgent,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if params.Action == entities.CardActionReplace {
+		return s.replaceCard(card, audit)
+	}
+
+	if err := s.repo.UpdateStatus(card, toStatus, audit); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logger.Infof("Card %s of user %s moved from %s to %s", card.CardID, params.UserID, fromStatus, toStatus)
+	card.DebitCardStatus.Status = toStatus
+	result := toCardEntity(*card)
+	return &result, nil
+}
+
+func (s *cardService) replaceCard(card *models.DebitCard, audit *models.CardAuditLog) (*entities.DebitCards, error) {
+	number, err := s.unusedCardNumber(card.DebitCardDetail.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	newCardID := uuid.NewString()
+	newCard := &models.DebitCard{
+		CardID:         newCardID,
+		UserID:         card.UserID,
+		Name:           card.Name,
+		ReplacesCardID: &card.CardID,
+		DebitCardDetail: models.DebitCardDetail{
+			CardID: newCardID,
+			UserID: card.UserID,
+			Issuer: card.DebitCardDetail.Issuer,
+			Number: number,
+		},
+		DebitCardDesign: models.DebitCardDesign{
+			CardID:      newCardID,
+			UserID:      card.UserID,
+			Color:       card.DebitCardDesign.Color,
+			BorderColor: card.DebitCardDesign.BorderColor,
+		},
+		DebitCardStatus: models.DebitCardStatus{
+			CardID: newCardID,
+			UserID: card.UserID,
+			Status: models.CardStatusActive,
+		},
+	}
+	audit.NewCardID = &newCardID
+
+	if err := s.repo.ReplaceCard(card, newCard, audit); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logger.Infof("Card %s of user %s replaced by %s", card.CardID, card.UserID, newCardID)
+	result := toCardEntity(*newCard)
+	return &result, nil
+}
+
+// unusedCardNumber draws card numbers on the old card's BIN until one is not
+// issued yet. Numbers are encrypted, so the check goes through the blind index.
+func (s *cardService) unusedCardNumber(oldNumber string) (string, error) {
+	for attempt := 0; attempt < maxCardNumberAttempts; attempt++ {
+		number, err := newCardNumber(oldNumber)
+		if err != nil {
+			return "", exception.NewInternalError(err)
+		}
+
+		exists, err := s.repo.CardNumberExists(number)
+		if err != nil {
+			return "", exception.NewDatabaseError(err)
+		}
+		if !exists {
+			return number, nil
+		}
+	}
+	return "", exception.NewInternalError(errors.New("no unused card number found"))
+}
+
+// RevealCard returns the card with its full number after re-checking the PIN.
+// Every reveal is written to the card audit log.
+func (s *cardService) RevealCard(ctx context.Context, params entities.CardActionParams) (*entities.DebitCards, error) {
+	if err := s.pinVerifier.ConfirmPin(ctx, params.UserID, params.Username, params.Pin); err != nil {
+		return nil, err
+	}
+
+	card, err := s.repo.GetCard(params.UserID, params.CardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrCardNotFound
+		}
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	if err := s.repo.CreateAuditLog(&models.CardAuditLog{
+		CardID:     card.CardID,
+		UserID:     params.UserID,
+		Action:     entities.CardActionReveal,
+		FromStatus: card.DebitCardStatus.Status,
+		ToStatus:   card.DebitCardStatus.Status,
+		IPAddress:  params.IPAddress,
+		UserAgent:  params.UserAgent,
+		CreatedAt:  time.Now().UTC(),
+	}); err != nil {
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	logger.Infof("Card %s of user %s revealed", card.CardID, params.UserID)
+	result := toCardEntity(*card)
+	result.CardNumber = card.DebitCardDetail.Number
+	return &result, nil
+}
+
+func (s *cardService) wrapError(err error) error {
+	if errors.Is(err, repository.ErrCardStatusChanged) {
+		return exception.ErrCardStatusChanged
+	}
+	if _, ok := err.(*response.ErrorResponse); ok {
+		return err
+	}
+	return exception.NewDatabaseError(err)
+}
+
+func toCardEntity(card models.DebitCard) entities.DebitCards {
+	result := entities.DebitCards{
+		CardID:     card.CardID,
+		CardNumber: mask.Number(card.DebitCardDetail.Number),
+		CardName:   card.Name,
+		Issuer:     card.DebitCardDetail.Issuer,
+		Status:     card.DebitCardStatus.Status,
+		DebitCardDesign: entities.DebitCardDesign{
+			Color:       card.DebitCardDesign.Color,
+			BorderColor: card.DebitCardDesign.BorderColor,
+		},
+	}
+	if card.ReplacesCardID != nil {
+		result.ReplacesCardID = *card.ReplacesCardID
+	}
+	return result
+}
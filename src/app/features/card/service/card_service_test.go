@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/card/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Mock CardRepository
+type MockCardRepository struct {
+	mock.Mock
+}
+
+func (m *MockCardRepository) ListCards(userID string) ([]models.DebitCard, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DebitCard), args.Error(1)
+}
+
+func (m *MockCardRepository) GetCard(userID, cardID string) (*models.DebitCard, error) {
+	args := m.Called(userID, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DebitCard), args.Error(1)
+}
+
+func (m *MockCardRepository) UpdateStatus(card *models.DebitCard, toStatus string, audit *models.CardAuditLog) error {
+	args := m.Called(card, toStatus, audit)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) ReplaceCard(card *models.DebitCard, newCard *models.DebitCard, audit *models.CardAuditLog) error {
+	args := m.Called(card, newCard, audit)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) CreateAuditLog(audit *models.CardAuditLog) error {
+	args := m.Called(audit)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) CardNumberExists(number string) (bool, error) {
+	args := m.Called(number)
+	return args.Bool(0), args.Error(1)
+}
+
+// Mock PinVerifier
+type MockPinVerifier struct {
+	mock.Mock
+}
+
+func (m *MockPinVerifier) ConfirmPin(ctx context.Context, userID, username, pin string) error {
+	args := m.Called(userID, username, pin)
+	return args.Error(0)
+}
+
+func testCard(status string) *models.DebitCard {
+	return &models.DebitCard{
+		CardID:          "card1",
+		UserID:          "user123",
+		Name:            "My Card",
+		DebitCardDetail: models.DebitCardDetail{CardID: "card1", Issuer: "TestBank", Number: "4000 1234 5678 9010"},
+		DebitCardDesign: models.DebitCardDesign{CardID: "card1", Color: "#00a1e2", BorderColor: "#ffffff"},
+		DebitCardStatus: models.DebitCardStatus{CardID: "card1", Status: status},
+	}
+}
+
+func testParams(action string) entities.CardActionParams {
+	return entities.CardActionParams{
+		UserID:    "user123",
+		Username:  "testuser",
+		CardID:    "card1",
+		Action:    action,
+		Pin:       "123456",
+		IPAddress: "10.0.0.1",
+		UserAgent: "test-agent",
+	}
+}
+
+func TestCardService_ChangeStatus(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+
+	tests := []struct {
+		name           string
+		action         string
+		mockSetup      func(*MockCardRepository, *MockPinVerifier)
+		expectedStatus string
+		expectError    error
+		expectCode     response.ResponseCode
+	}{
+		{
+			name:   "freeze active card",
+			action: entities.CardActionFreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusActive), nil)
+				repo.On("UpdateStatus", mock.Anything, models.CardStatusFrozen, mock.MatchedBy(func(a *models.CardAuditLog) bool {
+					return a.Action == "freeze" && a.FromStatus == "active" && a.ToStatus == "frozen" &&
+						a.IPAddress == "10.0.0.1" && a.UserAgent == "test-agent" && a.NewCardID == nil
+				})).Return(nil)
+			},
+			expectedStatus: models.CardStatusFrozen,
+		},
+		{
+			name:   "unfreeze frozen card",
+			action: entities.CardActionUnfreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusFrozen), nil)
+				repo.On("UpdateStatus", mock.Anything, models.CardStatusActive, mock.Anything).Return(nil)
+			},
+			expectedStatus: models.CardStatusActive,
+		},
+		{
+			name:   "unfreeze active card is rejected",
+			action: entities.CardActionUnfreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusActive), nil)
+			},
+			expectCode: response.ErrCodeInvalidCardTransition,
+		},
+		{
+			name:   "closed card cannot be reported lost",
+			action: entities.CardActionReportLost,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusClosed), nil)
+			},
+			expectCode: response.ErrCodeInvalidCardTransition,
+		},
+		{
+			name:   "wrong pin",
+			action: entities.CardActionFreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(exception.NewInvalidPinError(2))
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+		{
+			name:   "card not found",
+			action: entities.CardActionClose,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrCardNotFound,
+		},
+		{
+			name:   "concurrent change",
+			action: entities.CardActionFreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusActive), nil)
+				repo.On("UpdateStatus", mock.Anything, models.CardStatusFrozen, mock.Anything).Return(repository.ErrCardStatusChanged)
+			},
+			expectError: exception.ErrCardStatusChanged,
+		},
+		{
+			name:   "database error",
+			action: entities.CardActionFreeze,
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusActive), nil)
+				repo.On("UpdateStatus", mock.Anything, models.CardStatusFrozen, mock.Anything).Return(errors.New("connection lost"))
+			},
+			expectCode: response.ErrCodeDatabaseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockCardRepository)
+			pin := new(MockPinVerifier)
+			tt.mockSetup(repo, pin)
+
+			card, err := NewCardService(repo, pin).ChangeStatus(context.Background(), testParams(tt.action))
+
+			switch {
+			case tt.expectError != nil:
+				assert.Equal(t, tt.expectError, err)
+			case tt.expectCode != 0:
+				errResp, ok := err.(*response.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectCode, errResp.Code)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, card.Status)
+				assert.Equal(t, "card1", card.CardID)
+			}
+			repo.AssertExpectations(t)
+			pin.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCardService_ChangeStatus_Replace(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+
+	repo := new(MockCardRepository)
+	pin := new(MockPinVerifier)
+	pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+	repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusLost), nil)
+	repo.On("CardNumberExists", mock.Anything).Return(true, nil).Once()
+	repo.On("CardNumberExists", mock.Anything).Return(false, nil).Once()
+
+	var newCard *models.DebitCard
+	var audit *models.CardAuditLog
+	repo.On("ReplaceCard", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			newCard = args.Get(1).(*models.DebitCard)
+			audit = args.Get(2).(*models.CardAuditLog)
+		}).
+		Return(nil)
+
+	card, err := NewCardService(repo, pin).ChangeStatus(context.Background(), testParams(entities.CardActionReplace))
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "card1", card.CardID)
+	assert.Equal(t, "card1", card.ReplacesCardID)
+	assert.Equal(t, models.CardStatusActive, card.Status)
+	assert.Equal(t, "My Card", card.CardName)
+	assert.Equal(t, "#00a1e2", card.DebitCardDesign.Color)
+
+	assert.Equal(t, newCard.CardID, newCard.DebitCardDetail.CardID)
+	assert.Equal(t, newCard.CardID, newCard.DebitCardDesign.CardID)
+	assert.Equal(t, newCard.CardID, newCard.DebitCardStatus.CardID)
+	assert.True(t, strings.HasPrefix(newCard.DebitCardDetail.Number, "4000 12"))
+	assert.NotEqual(t, "4000 1234 5678 9010", newCard.DebitCardDetail.Number)
+
+	assert.Equal(t, models.CardStatusLost, audit.FromStatus)
+	assert.Equal(t, models.CardStatusReplaced, audit.ToStatus)
+	assert.Equal(t, newCard.CardID, *audit.NewCardID)
+	repo.AssertExpectations(t)
+}
+
+func TestCardService_ListCards(t *testing.T) {
+	replaced := "card0"
+	card := testCard(models.CardStatusActive)
+	card.ReplacesCardID = &replaced
+
+	repo := new(MockCardRepository)
+	repo.On("ListCards", "user123").Return([]models.DebitCard{*card}, nil)
+
+	cards, err := NewCardService(repo, new(MockPinVerifier)).ListCards("user123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.DebitCards{{
+		CardID:          "card1",
+		CardNumber:      "**** **** **** 9010",
+		CardName:        "My Card",
+		Issuer:          "TestBank",
+		Status:          "active",
+		ReplacesCardID:  "card0",
+		DebitCardDesign: entities.DebitCardDesign{Color: "#00a1e2", BorderColor: "#ffffff"},
+	}}, cards)
+}
+
+func TestCardService_RevealCard(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockCardRepository, *MockPinVerifier)
+		expectError error
+		expectCode  response.ResponseCode
+	}{
+		{
+			name: "reveals the full number",
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusFrozen), nil)
+				repo.On("CreateAuditLog", mock.MatchedBy(func(a *models.CardAuditLog) bool {
+					return a.Action == "reveal" && a.FromStatus == "frozen" && a.ToStatus == "frozen" &&
+						a.IPAddress == "10.0.0.1" && a.UserAgent == "test-agent"
+				})).Return(nil)
+			},
+		},
+		{
+			name: "wrong pin",
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(exception.NewInvalidPinError(2))
+			},
+			expectError: exception.NewInvalidPinError(2),
+		},
+		{
+			name: "card not found",
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectError: exception.ErrCardNotFound,
+		},
+		{
+			name: "audit log fails",
+			mockSetup: func(repo *MockCardRepository, pin *MockPinVerifier) {
+				pin.On("ConfirmPin", "user123", "testuser", "123456").Return(nil)
+				repo.On("GetCard", "user123", "card1").Return(testCard(models.CardStatusActive), nil)
+				repo.On("CreateAuditLog", mock.Anything).Return(errors.New("connection lost"))
+			},
+			expectCode: response.ErrCodeDatabaseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockCardRepository)
+			pin := new(MockPinVerifier)
+			tt.mockSetup(repo, pin)
+
+			card, err := NewCardService(repo, pin).RevealCard(context.Background(), testParams(entities.CardActionReveal))
+
+			switch {
+			case tt.expectError != nil:
+				assert.Equal(t, tt.expectError, err)
+			case tt.expectCode != 0:
+				errResp, ok := err.(*response.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectCode, errResp.Code)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, "4000 1234 5678 9010", card.CardNumber)
+			}
+			repo.AssertExpectations(t)
+			pin.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		action   string
+		current  string
+		expected string
+		allowed  bool
+	}{
+		{entities.CardActionFreeze, models.CardStatusActive, models.CardStatusFrozen, true},
+		{entities.CardActionFreeze, models.CardStatusFrozen, "", false},
+		{entities.CardActionUnfreeze, models.CardStatusFrozen, models.CardStatusActive, true},
+		{entities.CardActionUnfreeze, models.CardStatusLost, "", false},
+		{entities.CardActionReportLost, models.CardStatusFrozen, models.CardStatusLost, true},
+		{entities.CardActionReplace, models.CardStatusLost, models.CardStatusReplaced, true},
+		{entities.CardActionReplace, models.CardStatusReplaced, "", false},
+		{entities.CardActionClose, models.CardStatusLost, models.CardStatusClosed, true},
+		{entities.CardActionClose, models.CardStatusClosed, "", false},
+		{entities.CardActionUnfreeze, models.CardStatusBlocked, "", false},
+		{entities.CardActionClose, models.CardStatusBlocked, models.CardStatusClosed, true},
+		{"activate", models.CardStatusClosed, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.current, func(t *testing.T) {
+			status, ok := nextStatus(tt.action, tt.current)
+			assert.Equal(t, tt.allowed, ok)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestNewCardNumber(t *testing.T) {
+	number, err := newCardNumber("4000 1234 5678 9010")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^4000 12\d{2} \d{4} \d{4}$`, number)
+	assert.Equal(t, 0, luhnSum(strings.ReplaceAll(number, " ", ""))%10)
+
+	number, err = newCardNumber("")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^400000\d{10}$`, number)
+	assert.Equal(t, 0, luhnSum(number)%10)
+}
+
+func luhnSum(number string) int {
+	sum := 0
+	for i := 0; i < len(number); i++ {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum
+}
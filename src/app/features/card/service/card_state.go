@@ -0,0 +1,49 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+)
+
+type cardTransition struct {
+	from []string
+	to   string
+}
+
+// cardTransitions is the card state machine. Replaced and closed cards are
+// final; a replacement is a new card that starts active. Blocked cards can
+// only be closed.
+var cardTransitions = map[string]cardTransition{
+	entities.CardActionFreeze: {
+		from: []string{models.CardStatusActive},
+		to:   models.CardStatusFrozen,
+	},
+	entities.CardActionUnfreeze: {
+		from: []string{models.CardStatusFrozen},
+		to:   models.CardStatusActive,
+	},
+	entities.CardActionReportLost: {
+		from: []string{models.CardStatusActive, models.CardStatusFrozen},
+		to:   models.CardStatusLost,
+	},
+	entities.CardActionReplace: {
+		from: []string{models.CardStatusActive, models.CardStatusFrozen, models.CardStatusLost},
+		to:   models.CardStatusReplaced,
+	},
+	entities.CardActionClose: {
+		from: []string{models.CardStatusActive, models.CardStatusFrozen, models.CardStatusLost, models.CardStatusBlocked},
+		to:   models.CardStatusClosed,
+	},
+}
+
+// nextStatus returns the status the action moves the card to, or false when
+// the action is unknown or not allowed from the current status.
+func nextStatus(action, current string) (string, bool) {
+	transition, ok := cardTransitions[action]
+	if !ok || !slices.Contains(transition.from, current) {
+		return "", false
+	}
+	return transition.to, true
+}
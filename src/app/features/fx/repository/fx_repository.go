@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/Testzyler/banking-api/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const upsertBatchSize = 500
+
+type fxRepository struct {
+	db *gorm.DB
+}
+
+type FxRepository interface {
+	GetRate(base, quote string) (*models.FxRate, error)
+	UpsertRates(rates []models.FxRate) error
+}
+
+func NewFxRepository(db *gorm.DB) FxRepository {
+	return &fxRepository{
+		db: db,
+	}
+}
+
+func (r *fxRepository) GetRate(base, quote string) (*models.FxRate, error) {
+	var rate models.FxRate
+	if err := r.db.Where("base_currency = ? AND quote_currency = ?", base, quote).
+		First(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// UpsertRates inserts rates, replacing any stored rate for the same pair.
+func (r *fxRepository) UpsertRates(rates []models.FxRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "as_of", "updated_at"}),
+	}).CreateInBatches(rates, upsertBatchSize).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func TestFxRepository_GetRate(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	asOf := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT \\* FROM `fx_rates` WHERE base_currency = \\? AND quote_currency = \\? ORDER BY `fx_rates`\\.`base_currency` LIMIT \\?").
+		WithArgs("USD", "THB", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate", "source", "as_of"}).
+			AddRow("USD", "THB", "35.5000000000", "csv", asOf))
+
+	rate, err := NewFxRepository(gormDB).GetRate("USD", "THB")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "35.5000000000", rate.Rate)
+	assert.Equal(t, asOf, rate.AsOf)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFxRepository_GetRate_NotFound(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT \\* FROM `fx_rates`").
+		WithArgs("EUR", "THB", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"base_currency"}))
+
+	rate, err := NewFxRepository(gormDB).GetRate("EUR", "THB")
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, rate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFxRepository_UpsertRates(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	asOf := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `fx_rates` .* ON DUPLICATE KEY UPDATE `rate`=VALUES\\(`rate`\\),`source`=VALUES\\(`source`\\),`as_of`=VALUES\\(`as_of`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := NewFxRepository(gormDB).UpsertRates([]models.FxRate{
+		{BaseCurrency: "USD", QuoteCurrency: "THB", Rate: "35.5000000000", Source: "csv", AsOf: asOf},
+		{BaseCurrency: "EUR", QuoteCurrency: "THB", Rate: "38.2000000000", Source: "csv", AsOf: asOf},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFxRepository_UpsertRates_Empty(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	assert.NoError(t, NewFxRepository(gormDB).UpsertRates(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
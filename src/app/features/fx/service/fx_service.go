@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/fx/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+)
+
+const (
+	csvSource     = "csv"
+	ratePrecision = 10
+)
+
+var csvHeader = []string{"base", "quote", "rate", "as_of"}
+
+type fxService struct {
+	repo     repository.FxRepository
+	provider RateProvider
+}
+
+type FxService interface {
+	ConvertTotal(ctx context.Context, balances []money.Money, currency string) (money.Money, []entities.FxRate, error)
+	ImportRatesCSV(r io.Reader) (int, error)
+}
+
+func NewFxService(repo repository.FxRepository, provider RateProvider) FxService {
+	return &fxService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// ConvertTotal converts every balance into currency and adds them up. Each
+// balance is rounded once, after conversion, and the rates used are returned
+// so clients can show how old they are.
+func (s *fxService) ConvertTotal(ctx context.Context, balances []money.Money, currency string) (money.Money, []entities.FxRate, error) {
+	total := money.New(0, currency)
+	rates := map[string]Rate{}
+	var used []entities.FxRate
+
+	for _, balance := range balances {
+		if balance.Currency == currency {
+			total.Amount += balance.Amount
+			continue
+		}
+
+		rate, ok := rates[balance.Currency]
+		if !ok {
+			var err error
+			rate, err = s.provider.GetRate(ctx, balance.Currency, currency)
+			if err != nil {
+				return money.Money{}, nil, fmt.Errorf("%s/%s: %w", balance.Currency, currency, err)
+			}
+			rates[balance.Currency] = rate
+			used = append(used, entities.FxRate{
+				Base:   rate.Base,
+				Quote:  rate.Quote,
+				Rate:   rate.Rate.FloatString(ratePrecision),
+				Source: rate.Source,
+				AsOf:   rate.AsOf,
+			})
+		}
+
+		total.Amount += money.Convert(balance, rate.Rate, currency).Amount
+	}
+
+	return total, used, nil
+}
+
+// ImportRatesCSV loads "base,quote,rate,as_of" rows (with that header) and
+// upserts them. as_of is RFC 3339 or YYYY-MM-DD.
+func (s *fxService) ImportRatesCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header: %w", err)
+	}
+	for i, column := range csvHeader {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), column) {
+			return 0, fmt.Errorf("unexpected header %v, want %v", header, csvHeader)
+		}
+	}
+
+	var rates []models.FxRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rate, err := parseRateRecord(record)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := s.repo.UpsertRates(rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func parseRateRecord(record []string) (models.FxRate, error) {
+	base := strings.ToUpper(strings.TrimSpace(record[0]))
+	quote := strings.ToUpper(strings.TrimSpace(record[1]))
+	if len(base) != 3 || len(quote) != 3 || base == quote {
+		return models.FxRate{}, fmt.Errorf("invalid currency pair %q/%q", record[0], record[1])
+	}
+
+	rate, err := money.ParseRate(strings.TrimSpace(record[2]))
+	if err != nil {
+		return models.FxRate{}, fmt.Errorf("invalid rate %q", record[2])
+	}
+
+	asOf, err := parseAsOf(strings.TrimSpace(record[3]))
+	if err != nil {
+		return models.FxRate{}, fmt.Errorf("invalid as_of %q", record[3])
+	}
+
+	return models.FxRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate.FloatString(ratePrecision),
+		Source:        csvSource,
+		AsOf:          asOf,
+	}, nil
+}
+
+func parseAsOf(value string) (time.Time, error) {
+	if asOf, err := time.Parse(time.RFC3339, value); err == nil {
+		return asOf.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
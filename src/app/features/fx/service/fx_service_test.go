@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock FxRepository
+type MockFxRepository struct {
+	mock.Mock
+}
+
+func (m *MockFxRepository) GetRate(base, quote string) (*models.FxRate, error) {
+	args := m.Called(base, quote)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FxRate), args.Error(1)
+}
+
+func (m *MockFxRepository) UpsertRates(rates []models.FxRate) error {
+	args := m.Called(rates)
+	return args.Error(0)
+}
+
+// Mock RateProvider
+type MockRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockRateProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	args := m.Called(base, quote)
+	return args.Get(0).(Rate), args.Error(1)
+}
+
+var testAsOf = time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFxService_ConvertTotal(t *testing.T) {
+	provider := new(MockRateProvider)
+	provider.On("GetRate", "USD", "THB").
+		Return(Rate{Base: "USD", Quote: "THB", Rate: big.NewRat(355, 10), Source: "csv", AsOf: testAsOf}, nil).Once()
+
+	service := NewFxService(new(MockFxRepository), provider)
+	total, rates, err := service.ConvertTotal(context.Background(), []money.Money{
+		money.New(100000, "THB"),
+		money.New(1000, "USD"), // 10.00 USD = 355.00 THB
+		money.New(1, "USD"),    // 0.01 USD = 0.355 THB, rounds to even 0.36
+	}, "THB")
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(100000+35500+36, "THB"), total)
+	assert.Len(t, rates, 1)
+	assert.Equal(t, "35.5000000000", rates[0].Rate)
+	assert.Equal(t, testAsOf, rates[0].AsOf)
+	provider.AssertExpectations(t)
+}
+
+func TestFxService_ConvertTotal_RateNotFound(t *testing.T) {
+	provider := new(MockRateProvider)
+	provider.On("GetRate", "EUR", "THB").Return(Rate{}, ErrRateNotFound)
+
+	service := NewFxService(new(MockFxRepository), provider)
+	_, _, err := service.ConvertTotal(context.Background(), []money.Money{money.New(100, "EUR")}, "THB")
+
+	assert.ErrorIs(t, err, ErrRateNotFound)
+	assert.Contains(t, err.Error(), "EUR/THB")
+}
+
+func TestStoredRateProvider_GetRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockFxRepository)
+		expectedRate *big.Rat
+		expectedErr  error
+	}{
+		{
+			name: "direct pair",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("GetRate", "USD", "THB").Return(&models.FxRate{Rate: "35.5000000000", Source: "csv", AsOf: testAsOf}, nil)
+			},
+			expectedRate: big.NewRat(71, 2),
+		},
+		{
+			name: "inverse pair",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("GetRate", "USD", "THB").Return(nil, gorm.ErrRecordNotFound)
+				m.On("GetRate", "THB", "USD").Return(&models.FxRate{Rate: "0.0250000000", Source: "csv", AsOf: testAsOf}, nil)
+			},
+			expectedRate: big.NewRat(40, 1),
+		},
+		{
+			name: "no rate for either direction",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("GetRate", "USD", "THB").Return(nil, gorm.ErrRecordNotFound)
+				m.On("GetRate", "THB", "USD").Return(nil, gorm.ErrRecordNotFound)
+			},
+			expectedErr: ErrRateNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("GetRate", "USD", "THB").Return(nil, gorm.ErrInvalidDB)
+			},
+			expectedErr: gorm.ErrInvalidDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFxRepository)
+			tt.mockSetup(repo)
+
+			rate, err := NewStoredRateProvider(repo).GetRate(context.Background(), "USD", "THB")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, tt.expectedRate.Cmp(rate.Rate))
+				assert.Equal(t, "USD", rate.Base)
+				assert.Equal(t, "THB", rate.Quote)
+				assert.Equal(t, testAsOf, rate.AsOf)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChainProvider_GetRate(t *testing.T) {
+	live := new(MockRateProvider)
+	live.On("GetRate", "USD", "THB").Return(Rate{}, errors.New("feed unavailable"))
+	stored := new(MockRateProvider)
+	stored.On("GetRate", "USD", "THB").Return(Rate{Base: "USD", Quote: "THB", Rate: big.NewRat(355, 10), Source: "csv"}, nil)
+
+	rate, err := NewChainProvider(live, stored).GetRate(context.Background(), "USD", "THB")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "csv", rate.Source)
+
+	missing := new(MockRateProvider)
+	missing.On("GetRate", "USD", "THB").Return(Rate{}, ErrRateNotFound)
+
+	_, err = NewChainProvider(live, missing).GetRate(context.Background(), "USD", "THB")
+	assert.EqualError(t, err, "feed unavailable")
+
+	_, err = NewChainProvider(missing).GetRate(context.Background(), "USD", "THB")
+	assert.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFxService_ImportRatesCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		csv           string
+		mockSetup     func(*MockFxRepository)
+		expectedCount int
+		expectError   string
+	}{
+		{
+			name: "valid rows",
+			csv:  "base,quote,rate,as_of\nusd,thb,35.50,2025-08-01\nEUR,THB,38.2,2025-08-01T09:00:00+07:00\n",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("UpsertRates", []models.FxRate{
+					{BaseCurrency: "USD", QuoteCurrency: "THB", Rate: "35.5000000000", Source: "csv", AsOf: testAsOf},
+					{BaseCurrency: "EUR", QuoteCurrency: "THB", Rate: "38.2000000000", Source: "csv", AsOf: time.Date(2025, 8, 1, 2, 0, 0, 0, time.UTC)},
+				}).Return(nil)
+			},
+			expectedCount: 2,
+		},
+		{
+			name:        "bad header",
+			csv:         "from,to,rate,date\nUSD,THB,35.5,2025-08-01\n",
+			mockSetup:   func(m *MockFxRepository) {},
+			expectError: "unexpected header",
+		},
+		{
+			name:        "bad rate",
+			csv:         "base,quote,rate,as_of\nUSD,THB,-1,2025-08-01\n",
+			mockSetup:   func(m *MockFxRepository) {},
+			expectError: "line 2: invalid rate",
+		},
+		{
+			name:        "same currency pair",
+			csv:         "base,quote,rate,as_of\nTHB,THB,1,2025-08-01\n",
+			mockSetup:   func(m *MockFxRepository) {},
+			expectError: "line 2: invalid currency pair",
+		},
+		{
+			name: "repository error",
+			csv:  "base,quote,rate,as_of\nUSD,THB,35.5,2025-08-01\n",
+			mockSetup: func(m *MockFxRepository) {
+				m.On("UpsertRates", mock.Anything).Return(errors.New("database error"))
+			},
+			expectError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFxRepository)
+			tt.mockSetup(repo)
+
+			count, err := NewFxService(repo, nil).ImportRatesCSV(strings.NewReader(tt.csv))
+
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				assert.Equal(t, 0, count)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, count)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/features/fx/repository"
+	"github.com/Testzyler/banking-api/app/money"
+	"gorm.io/gorm"
+)
+
+var ErrRateNotFound = errors.New("fx: rate not found")
+
+// Rate converts one unit of Base into Quote.
+type Rate struct {
+	Base   string
+	Quote  string
+	Rate   *big.Rat
+	Source string
+	AsOf   time.Time
+}
+
+// RateProvider looks up exchange rates. Live market data feeds implement it
+// and are chained in front of the stored rates with NewChainProvider.
+type RateProvider interface {
+	GetRate(ctx context.Context, base, quote string) (Rate, error)
+}
+
+type storedRateProvider struct {
+	repo repository.FxRepository
+}
+
+// NewStoredRateProvider serves rates from the fx_rates table, deriving the
+// inverse when only the opposite pair has been imported.
+func NewStoredRateProvider(repo repository.FxRepository) RateProvider {
+	return &storedRateProvider{
+		repo: repo,
+	}
+}
+
+func (p *storedRateProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	stored, err := p.repo.GetRate(base, quote)
+	if err == nil {
+		rate, err := money.ParseRate(stored.Rate)
+		if err != nil {
+			return Rate{}, err
+		}
+		return Rate{Base: base, Quote: quote, Rate: rate, Source: stored.Source, AsOf: stored.AsOf}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Rate{}, err
+	}
+
+	inverse, err := p.repo.GetRate(quote, base)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Rate{}, ErrRateNotFound
+		}
+		return Rate{}, err
+	}
+	rate, err := money.ParseRate(inverse.Rate)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{Base: base, Quote: quote, Rate: rate.Inv(rate), Source: inverse.Source, AsOf: inverse.AsOf}, nil
+}
+
+type chainProvider struct {
+	providers []RateProvider
+}
+
+// NewChainProvider asks each provider in turn and returns the first rate
+// found, so a failing live feed falls back to the stored rates.
+func NewChainProvider(providers ...RateProvider) RateProvider {
+	return &chainProvider{
+		providers: providers,
+	}
+}
+
+func (p *chainProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	lastErr := ErrRateNotFound
+	for _, provider := range p.providers {
+		rate, err := provider.GetRate(ctx, base, quote)
+		if err == nil {
+			return rate, nil
+		}
+		if !errors.Is(err, ErrRateNotFound) {
+			lastErr = err
+		}
+	}
+	return Rate{}, lastErr
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/Testzyler/banking-api/app/entities"
 	"github.com/Testzyler/banking-api/app/features/home/service"
 	"github.com/Testzyler/banking-api/server/exception"
@@ -9,6 +12,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
 type homeHandler struct {
 	service service.HomeService
 }
@@ -33,7 +38,12 @@ func (h *homeHandler) GetHomeData(c *fiber.Ctx) error {
 		return exception.ErrInternalServer
 	}
 
-	data, err := h.service.GetHomeData(user.UserID)
+	currency := strings.ToUpper(c.Query("currency"))
+	if currency != "" && !currencyPattern.MatchString(currency) {
+		return exception.ErrInvalidCurrency
+	}
+
+	data, err := h.service.GetHomeData(c.Context(), user.UserID, currency)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/money"
 	"github.com/Testzyler/banking-api/logger"
 	"github.com/Testzyler/banking-api/server/middlewares"
 	"github.com/gofiber/fiber/v2"
@@ -21,8 +23,13 @@ type MockHomeService struct {
 	mock.Mock
 }
 
-func (m *MockHomeService) GetHomeData(userID string) (entities.HomeResponse, error) {
-	args := m.Called(userID)
+func totalBalance(amount money.Amount, currency string) *money.Money {
+	total := money.New(amount, currency)
+	return &total
+}
+
+func (m *MockHomeService) GetHomeData(ctx context.Context, userID, currency string) (entities.HomeResponse, error) {
+	args := m.Called(userID, currency)
 	if args.Error(1) != nil {
 		return entities.HomeResponse{}, args.Error(1)
 	}
@@ -52,10 +59,10 @@ func TestGetHomeData_Success(t *testing.T) {
 		Banners:      []entities.Banner{},
 		Transactions: []entities.Transaction{},
 		Accounts:     []entities.Account{},
-		TotalBalance: 1000.0,
+		TotalBalance: totalBalance(100000, "THB"),
 	}
 
-	mockService.On("GetHomeData", "1").Return(expectedResponse, nil)
+	mockService.On("GetHomeData", "1", "").Return(expectedResponse, nil)
 
 	// Create handler with mock service
 	handler := &homeHandler{
@@ -157,7 +164,7 @@ func TestGetHomeData_ServiceError(t *testing.T) {
 	mockService := new(MockHomeService)
 	serviceError := errors.New("database error")
 
-	mockService.On("GetHomeData", "1").Return(entities.HomeResponse{}, serviceError)
+	mockService.On("GetHomeData", "1", "").Return(entities.HomeResponse{}, serviceError)
 
 	handler := &homeHandler{
 		service: mockService,
@@ -190,7 +197,7 @@ func TestGetHomeData_EmptyUserID(t *testing.T) {
 	mockService := new(MockHomeService)
 	serviceError := errors.New("invalid user ID")
 
-	mockService.On("GetHomeData", "").Return(entities.HomeResponse{}, serviceError)
+	mockService.On("GetHomeData", "", "").Return(entities.HomeResponse{}, serviceError)
 
 	handler := &homeHandler{
 		service: mockService,
@@ -224,10 +231,10 @@ func TestGetHomeData_DirectCall(t *testing.T) {
 			UserID: "1",
 			Name:   "testuser",
 		},
-		TotalBalance: 1000.0,
+		TotalBalance: totalBalance(100000, "THB"),
 	}
 
-	mockService.On("GetHomeData", "1").Return(expectedResponse, nil)
+	mockService.On("GetHomeData", "1", "").Return(expectedResponse, nil)
 
 	handler := &homeHandler{
 		service: mockService,
@@ -294,9 +301,9 @@ func TestHomeHandler_HTTPMethodValidation(t *testing.T) {
 						UserID: "1",
 						Name:   "testuser",
 					},
-					TotalBalance: 1000.0,
+					TotalBalance: totalBalance(100000, "THB"),
 				}
-				mockService.On("GetHomeData", "1").Return(expectedResponse, nil)
+				mockService.On("GetHomeData", "1", "").Return(expectedResponse, nil)
 			}
 
 			handler := &homeHandler{service: mockService}
@@ -372,7 +379,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 						Type:      "savings",
 						Currency:  "USD",
 						Issuer:    "Bank ABC",
-						Amount:    5000.50,
+						Amount:    money.New(500050, "USD"),
 						AccountDetails: entities.AccountDetails{
 							Color:         "#00FF00",
 							IsMainAccount: true,
@@ -386,7 +393,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 						},
 					},
 				},
-				TotalBalance: 5000.50,
+				TotalBalance: totalBalance(500050, "USD"),
 			},
 			expectedStatus: fiber.StatusOK,
 			validateResponse: func(t *testing.T, body []byte) {
@@ -402,7 +409,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 				assert.Equal(t, "user123", data["userID"])
 				assert.Equal(t, "John Doe", data["name"])
 				assert.Equal(t, "Good morning!", data["greeting"])
-				assert.Equal(t, 5000.50, data["totalBalance"])
+				assert.Equal(t, map[string]interface{}{"amount": "5000.50", "currency": "USD"}, data["totalBalance"])
 				assert.NotNil(t, data["debitCards"])
 				assert.NotNil(t, data["banners"])
 				assert.NotNil(t, data["transactions"])
@@ -421,7 +428,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 				Banners:      []entities.Banner{},
 				Transactions: []entities.Transaction{},
 				Accounts:     []entities.Account{},
-				TotalBalance: 0,
+				TotalBalance: totalBalance(0, ""),
 			},
 			expectedStatus: fiber.StatusOK,
 			validateResponse: func(t *testing.T, body []byte) {
@@ -432,7 +439,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 
 				data, ok := response["data"].(map[string]interface{})
 				assert.True(t, ok)
-				assert.Equal(t, float64(0), data["totalBalance"])
+				assert.Equal(t, map[string]interface{}{"amount": "0.00", "currency": ""}, data["totalBalance"])
 				assert.NotNil(t, data["debitCards"])
 				assert.NotNil(t, data["banners"])
 				assert.NotNil(t, data["transactions"])
@@ -444,7 +451,7 @@ func TestHomeHandler_ResponseStructureValidation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockHomeService)
-			mockService.On("GetHomeData", "user123").Return(tt.serviceResponse, tt.serviceError)
+			mockService.On("GetHomeData", "user123", "").Return(tt.serviceResponse, tt.serviceError)
 
 			handler := &homeHandler{service: mockService}
 			app := setupTestApp()
@@ -542,9 +549,9 @@ func TestHomeHandler_UserContextEdgeCases(t *testing.T) {
 						Name:     "testuser",
 						Greeting: "Good morning!",
 					},
-					TotalBalance: 1000.0,
+					TotalBalance: totalBalance(100000, "THB"),
 				}
-				mockService.On("GetHomeData", mock.AnythingOfType("string")).Return(expectedResponse, nil)
+				mockService.On("GetHomeData", mock.AnythingOfType("string"), "").Return(expectedResponse, nil)
 			}
 
 			handler := &homeHandler{service: mockService}
@@ -563,3 +570,47 @@ func TestHomeHandler_UserContextEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetHomeData_DisplayCurrency(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockHomeService)
+		expectedStatus int
+	}{
+		{
+			name:  "currency is upper-cased and passed through",
+			query: "?currency=usd",
+			mockSetup: func(m *MockHomeService) {
+				m.On("GetHomeData", "1", "USD").Return(entities.HomeResponse{TotalBalance: totalBalance(2800, "USD")}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid currency",
+			query:          "?currency=US1",
+			mockSetup:      func(m *MockHomeService) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockHomeService)
+			tt.mockSetup(mockService)
+			handler := &homeHandler{service: mockService}
+
+			app := setupTestApp()
+			app.Get("/home", func(c *fiber.Ctx) error {
+				c.Locals("user", entities.Claims{UserID: "1", Username: "testuser"})
+				return handler.GetHomeData(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/home"+tt.query, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -2,16 +2,22 @@ package repository
 
 import (
 	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/mask"
 	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
 	"gorm.io/gorm"
 )
 
+// number of transactions embedded in the home payload, the full
+// history is served by the paginated transactions endpoint
+const recentTransactionsLimit = 5
+
 type homeRepository struct {
 	db *gorm.DB
 }
 
 type HomeRepository interface {
-	GetTotalBalance(userID string) float64
+	GetTotalBalance(userID string) (money.Money, error)
 	GetHomeData(userID string) (entities.HomeResponse, error)
 }
 
@@ -21,16 +27,25 @@ func NewHomeRepository(repo *gorm.DB) HomeRepository {
 	}
 }
 
-func (r *homeRepository) GetTotalBalance(userID string) float64 {
-	var total float64
+// GetTotalBalance sums the user's balances per currency and refuses to add
+// balances held in different currencies.
+func (r *homeRepository) GetTotalBalance(userID string) (money.Money, error) {
+	var totals []money.Money
 	if err := r.db.Model(&models.AccountBalance{}).
-		Select("SUM(amount)").
+		Select("accounts.currency AS currency, SUM(account_balances.amount) AS amount").
 		Joins("JOIN accounts ON account_balances.account_id = accounts.account_id").
 		Where("accounts.user_id = ?", userID).
-		Scan(&total).Error; err != nil {
-		return 0
+		Group("accounts.currency").
+		Scan(&totals).Error; err != nil {
+		return money.Money{}, err
+	}
+	if len(totals) > 1 {
+		return money.Money{}, money.ErrCurrencyMismatch
+	}
+	if len(totals) == 0 {
+		return money.Money{}, nil
 	}
-	return total
+	return totals[0], nil
 }
 
 func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, error) {
@@ -67,7 +82,7 @@ func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, erro
 					BorderColor: c.DebitCardDesign.BorderColor,
 				},
 				Status:     c.DebitCardStatus.Status,
-				CardNumber: c.DebitCardDetail.Number,
+				CardNumber: mask.Number(c.DebitCardDetail.Number),
 				Issuer:     c.DebitCardDetail.Issuer,
 			})
 		}
@@ -87,9 +102,11 @@ func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, erro
 			})
 		}
 
-		// Transactions
+		// Recent transactions preview
 		var transactions []models.Transaction
-		if err := tx.Order("transaction_id ASC").Find(&transactions, "user_id = ?", userID).Error; err != nil {
+		if err := tx.Order("transaction_id DESC").
+			Limit(recentTransactionsLimit).
+			Find(&transactions, "user_id = ?", userID).Error; err != nil {
 			return err
 		}
 		for _, t := range transactions {
@@ -114,11 +131,11 @@ func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, erro
 			return err
 		}
 
-		total := 0.0
 		for _, acc := range accounts {
 			var flags []entities.AccountFlags
 			for _, f := range acc.AccountFlags {
 				flags = append(flags, entities.AccountFlags{
+					FlagID:    f.FlagID,
 					FlagType:  f.FlagType,
 					FlagValue: f.FlagValue,
 					CreatedAt: f.CreatedAt,
@@ -126,11 +143,12 @@ func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, erro
 				})
 			}
 			response.Accounts = append(response.Accounts, entities.Account{
-				AccountID: acc.AccountID,
-				Type:      acc.Type,
-				Currency:  acc.Currency,
-				Issuer:    acc.Issuer,
-				Amount:    acc.AccountBalance.Amount,
+				AccountID:     acc.AccountID,
+				Type:          acc.Type,
+				Currency:      acc.Currency,
+				AccountNumber: mask.Number(acc.AccountNumber),
+				Issuer:        acc.Issuer,
+				Amount:        money.New(acc.AccountBalance.Amount, acc.Currency),
 				AccountDetails: entities.AccountDetails{
 					Color:         acc.AccountDetails.Color,
 					IsMainAccount: acc.AccountDetails.IsMainAccount,
@@ -138,9 +156,7 @@ func (r *homeRepository) GetHomeData(userID string) (entities.HomeResponse, erro
 				},
 				AccountFlags: flags,
 			})
-			total += acc.AccountBalance.Amount
 		}
-		response.TotalBalance = total
 
 		return nil
 	})
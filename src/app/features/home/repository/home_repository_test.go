@@ -6,40 +6,43 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/money"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+const totalBalanceQuery = "SELECT accounts\\.currency AS currency, SUM\\(account_balances\\.amount\\) AS amount FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\? GROUP BY `accounts`\\.`currency`"
+
 func TestDashboardRepository_GetTotalBalance(t *testing.T) {
 	tests := []struct {
 		name          string
 		userID        string
 		mockSetup     func(sqlmock.Sqlmock)
-		expectedTotal float64
+		expectedTotal money.Money
 	}{
 		{
 			name:   "successful get total balance",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"SUM(amount)"}).
-					AddRow(15000.50)
+				rows := sqlmock.NewRows([]string{"currency", "amount"}).
+					AddRow("THB", "15000.50")
 
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedTotal: 15000.50,
+			expectedTotal: money.New(1500050, "THB"),
 		},
 		{
 			name:   "user with no accounts returns zero",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
-					WillReturnError(gorm.ErrRecordNotFound)
+					WillReturnRows(sqlmock.NewRows([]string{"currency", "amount"}))
 			},
-			expectedTotal: 0,
+			expectedTotal: money.Money{},
 		},
 	}
 
@@ -62,9 +65,10 @@ func TestDashboardRepository_GetTotalBalance(t *testing.T) {
 			tt.mockSetup(mock)
 
 			// Act
-			total := repo.GetTotalBalance(tt.userID)
+			total, err := repo.GetTotalBalance(tt.userID)
 
 			// Assert
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedTotal, total)
 
 			// Verify all expectations were met
@@ -78,79 +82,91 @@ func TestHomeRepository_GetTotalBalance_AdvancedCases(t *testing.T) {
 		name          string
 		userID        string
 		mockSetup     func(sqlmock.Sqlmock)
-		expectedTotal float64
+		expectedTotal money.Money
+		expectError   error
 	}{
 		{
 			name:   "user with negative balance",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"SUM(amount)"}).
-					AddRow(-500.25)
+				rows := sqlmock.NewRows([]string{"currency", "amount"}).
+					AddRow("THB", "-500.25")
 
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedTotal: -500.25,
+			expectedTotal: money.New(-50025, "THB"),
 		},
 		{
 			name:   "user with zero balance",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"SUM(amount)"}).
-					AddRow(0.0)
+				rows := sqlmock.NewRows([]string{"currency", "amount"}).
+					AddRow("THB", "0.00")
 
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedTotal: 0.0,
+			expectedTotal: money.New(0, "THB"),
 		},
 		{
 			name:   "empty userID",
 			userID: "",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"SUM(amount)"}).
-					AddRow(0.0)
-
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("").
+					WillReturnRows(sqlmock.NewRows([]string{"currency", "amount"}))
+			},
+			expectedTotal: money.Money{},
+		},
+		{
+			name:   "very large balance is exact",
+			userID: "user123",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"currency", "amount"}).
+					AddRow("THB", "9999999999999.99")
+
+				mock.ExpectQuery(totalBalanceQuery).
+					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedTotal: 0.0,
+			expectedTotal: money.New(999999999999999, "THB"),
 		},
 		{
-			name:   "very large balance",
+			name:   "balances in several currencies are not added",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"SUM(amount)"}).
-					AddRow(999999999.99)
+				rows := sqlmock.NewRows([]string{"currency", "amount"}).
+					AddRow("THB", "100.00").
+					AddRow("USD", "5.00")
 
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedTotal: 999999999.99,
+			expectError: money.ErrCurrencyMismatch,
 		},
 		{
 			name:   "database connection error",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnError(errors.New("connection lost"))
 			},
-			expectedTotal: 0,
+			expectError: errors.New("connection lost"),
 		},
 		{
 			name:   "query timeout error",
 			userID: "user123",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT SUM\\(amount\\) FROM `account_balances` JOIN accounts ON account_balances\\.account_id = accounts\\.account_id WHERE accounts\\.user_id = \\?").
+				mock.ExpectQuery(totalBalanceQuery).
 					WithArgs("user123").
 					WillReturnError(errors.New("query timeout"))
 			},
-			expectedTotal: 0,
+			expectError: errors.New("query timeout"),
 		},
 	}
 
@@ -173,10 +189,16 @@ func TestHomeRepository_GetTotalBalance_AdvancedCases(t *testing.T) {
 			tt.mockSetup(mock)
 
 			// Act
-			total := repo.GetTotalBalance(tt.userID)
+			total, err := repo.GetTotalBalance(tt.userID)
 
 			// Assert
-			assert.Equal(t, tt.expectedTotal, total)
+			if tt.expectError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedTotal, total)
+			}
 
 			// Verify all expectations were met
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -226,12 +248,12 @@ func TestHomeRepository_GetHomeData_Success(t *testing.T) {
 		WillReturnRows(bannerRows)
 
 	txnRows := sqlmock.NewRows([]string{"transaction_id", "user_id", "name"})
-	mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? ORDER BY transaction_id ASC").
-		WithArgs("test123").
+	mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? ORDER BY transaction_id DESC LIMIT \\?").
+		WithArgs("test123", recentTransactionsLimit).
 		WillReturnRows(txnRows)
 
 	accountRows := sqlmock.NewRows([]string{"account_id", "user_id", "type"})
-	mock.ExpectQuery("SELECT `accounts`\\.`account_id`,`accounts`\\.`user_id`,`accounts`\\.`type`,`accounts`\\.`currency`,`accounts`\\.`account_number`,`accounts`\\.`issuer` FROM `accounts` JOIN account_details ON accounts\\.account_id = account_details\\.account_id WHERE accounts\\.user_id = \\? ORDER BY account_details\\.is_main_account DESC, accounts\\.type ASC").
+	mock.ExpectQuery("SELECT `accounts`\\.`account_id`,`accounts`\\.`user_id`,`accounts`\\.`type`,`accounts`\\.`currency`,`accounts`\\.`account_number`,`accounts`\\.`issuer`,`accounts`\\.`account_number_index` FROM `accounts` JOIN account_details ON accounts\\.account_id = account_details\\.account_id WHERE accounts\\.user_id = \\? ORDER BY account_details\\.is_main_account DESC, accounts\\.type ASC").
 		WithArgs("test123").
 		WillReturnRows(accountRows)
 
@@ -244,8 +266,8 @@ func TestHomeRepository_GetHomeData_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test123", response.UserID)
 	assert.Equal(t, "Test User", response.Name)
-	assert.Equal(t, "", response.Greeting)      // No greeting found
-	assert.Equal(t, 0.0, response.TotalBalance) // No accounts
+	assert.Equal(t, "", response.Greeting) // No greeting found
+	assert.Nil(t, response.TotalBalance)   // Converted by the service
 	assert.Empty(t, response.DebitCards)
 	assert.Empty(t, response.Banners)
 	assert.Empty(t, response.Transactions)
@@ -255,6 +277,72 @@ func TestHomeRepository_GetHomeData_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestHomeRepository_GetHomeData_MasksNumbers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	repo := &homeRepository{db: gormDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE user_id = \\? ORDER BY `users`.`user_id` LIMIT \\?").
+		WithArgs("test123", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("test123", "Test User"))
+	mock.ExpectQuery("SELECT \\* FROM `user_greetings` WHERE `user_greetings`.`user_id` = \\?").
+		WithArgs("test123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "greeting"}))
+
+	mock.ExpectQuery("SELECT \\* FROM `debit_cards` WHERE user_id = \\? ORDER BY name ASC").
+		WithArgs("test123").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "user_id", "name"}).AddRow("card1", "test123", "My Card"))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_design` WHERE `debit_card_design`.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id"}))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_details` WHERE `debit_card_details`.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "number"}).AddRow("card1", "4000 1234 5678 9010"))
+	mock.ExpectQuery("SELECT \\* FROM `debit_card_status` WHERE `debit_card_status`.`card_id` = \\?").
+		WithArgs("card1").
+		WillReturnRows(sqlmock.NewRows([]string{"card_id", "status"}).AddRow("card1", "active"))
+
+	mock.ExpectQuery("SELECT \\* FROM `banners` WHERE user_id = \\? ORDER BY banner_id ASC").
+		WithArgs("test123").
+		WillReturnRows(sqlmock.NewRows([]string{"banner_id"}))
+	mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? ORDER BY transaction_id DESC LIMIT \\?").
+		WithArgs("test123", recentTransactionsLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}))
+
+	mock.ExpectQuery("SELECT `accounts`\\.`account_id`.* FROM `accounts` JOIN account_details").
+		WithArgs("test123").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "user_id", "type", "currency", "account_number"}).
+			AddRow("acc1", "test123", "saving-account", "THB", "123456789012"))
+	mock.ExpectQuery("SELECT \\* FROM `account_balances` WHERE `account_balances`.`account_id` = \\?").
+		WithArgs("acc1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "amount"}).AddRow("acc1", "100.00"))
+	mock.ExpectQuery("SELECT \\* FROM `account_details` WHERE `account_details`.`account_id` = \\?").
+		WithArgs("acc1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	mock.ExpectQuery("SELECT \\* FROM `account_flags` WHERE `account_flags`.`account_id` = \\?").
+		WithArgs("acc1").
+		WillReturnRows(sqlmock.NewRows([]string{"flag_id"}))
+	mock.ExpectCommit()
+
+	response, err := repo.GetHomeData("test123")
+
+	assert.NoError(t, err)
+	assert.Len(t, response.DebitCards, 1)
+	assert.Equal(t, "**** **** **** 9010", response.DebitCards[0].CardNumber)
+	assert.Len(t, response.Accounts, 1)
+	assert.Equal(t, "********9012", response.Accounts[0].AccountNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestHomeRepository_GetHomeData_ErrorCases(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -346,3 +434,73 @@ func TestHomeRepository_GetHomeData_ErrorCases(t *testing.T) {
 		})
 	}
 }
+
+func TestHomeRepository_GetHomeData_Balances(t *testing.T) {
+	tests := []struct {
+		name       string
+		currencies []string
+		amounts    []string
+		expected   []money.Money
+	}{
+		{
+			name:       "same currency balances are read exactly",
+			currencies: []string{"THB", "THB"},
+			amounts:    []string{"0.10", "0.20"},
+			expected:   []money.Money{money.New(10, "THB"), money.New(20, "THB")},
+		},
+		{
+			name:       "mixed currencies keep their own currency",
+			currencies: []string{"THB", "USD"},
+			amounts:    []string{"100.00", "5.00"},
+			expected:   []money.Money{money.New(10000, "THB"), money.New(500, "USD")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			gormDB, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      db,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{})
+			assert.NoError(t, err)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT \\* FROM `users`").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "name"}).AddRow("test123", "Test User"))
+			mock.ExpectQuery("SELECT \\* FROM `user_greetings`").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "greeting"}))
+			mock.ExpectQuery("SELECT \\* FROM `debit_cards`").
+				WillReturnRows(sqlmock.NewRows([]string{"card_id"}))
+			mock.ExpectQuery("SELECT \\* FROM `banners`").
+				WillReturnRows(sqlmock.NewRows([]string{"banner_id"}))
+			mock.ExpectQuery("SELECT \\* FROM `transactions`").
+				WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}))
+			mock.ExpectQuery("SELECT `accounts`.*FROM `accounts`").
+				WillReturnRows(sqlmock.NewRows([]string{"account_id", "user_id", "currency"}).
+					AddRow("acc1", "test123", tt.currencies[0]).
+					AddRow("acc2", "test123", tt.currencies[1]))
+			mock.ExpectQuery("SELECT \\* FROM `account_balances`").
+				WillReturnRows(sqlmock.NewRows([]string{"account_id", "amount"}).
+					AddRow("acc1", tt.amounts[0]).
+					AddRow("acc2", tt.amounts[1]))
+			mock.ExpectQuery("SELECT \\* FROM `account_details`").
+				WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc1").AddRow("acc2"))
+			mock.ExpectQuery("SELECT \\* FROM `account_flags`").
+				WillReturnRows(sqlmock.NewRows([]string{"flag_id"}))
+			mock.ExpectCommit()
+
+			response, err := NewHomeRepository(gormDB).GetHomeData("test123")
+
+			assert.NoError(t, err)
+			assert.Len(t, response.Accounts, 2)
+			assert.Equal(t, tt.expected[0], response.Accounts[0].Amount)
+			assert.Equal(t, tt.expected[1], response.Accounts[1].Amount)
+			assert.Nil(t, response.TotalBalance)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,29 +1,59 @@
 package service
 
 import (
+	"context"
+
 	"github.com/Testzyler/banking-api/app/entities"
+	fxService "github.com/Testzyler/banking-api/app/features/fx/service"
 	"github.com/Testzyler/banking-api/app/features/home/repository"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
 )
 
 type homeService struct {
-	repo repository.HomeRepository
+	repo   repository.HomeRepository
+	fx     fxService.FxService
+	config *config.Config
 }
 
 type HomeService interface {
-	GetHomeData(userID string) (entities.HomeResponse, error)
+	GetHomeData(ctx context.Context, userID, currency string) (entities.HomeResponse, error)
 }
 
-func NewHomeService(repo repository.HomeRepository) *homeService {
+func NewHomeService(repo repository.HomeRepository, fx fxService.FxService, config *config.Config) *homeService {
 	return &homeService{
-		repo: repo,
+		repo:   repo,
+		fx:     fx,
+		config: config,
 	}
 }
 
-func (s *homeService) GetHomeData(userID string) (entities.HomeResponse, error) {
+// GetHomeData returns the home payload with the total balance converted into
+// currency, or the configured display currency when currency is empty.
+func (s *homeService) GetHomeData(ctx context.Context, userID, currency string) (entities.HomeResponse, error) {
 	homeData, err := s.repo.GetHomeData(userID)
 	if err != nil {
 		return entities.HomeResponse{}, err
 	}
 
+	if currency == "" {
+		currency = s.config.FX.DisplayCurrency
+	}
+
+	balances := make([]money.Money, 0, len(homeData.Accounts))
+	for _, account := range homeData.Accounts {
+		balances = append(balances, account.Amount)
+	}
+
+	total, rates, err := s.fx.ConvertTotal(ctx, balances, currency)
+	if err != nil {
+		// the rest of the home screen is still useful without a total
+		logger.Warnf("Cannot total balances for user %s in %s: %v", userID, currency, err)
+		return homeData, nil
+	}
+	homeData.TotalBalance = &total
+	homeData.TotalBalanceRates = rates
+
 	return homeData, nil
 }
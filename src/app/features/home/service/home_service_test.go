@@ -1,12 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -16,7 +23,7 @@ type MockHomeRepository struct {
 }
 
 // GetTotalBalance implements repository.HomeRepository.
-func (m *MockHomeRepository) GetTotalBalance(userID string) float64 {
+func (m *MockHomeRepository) GetTotalBalance(userID string) (money.Money, error) {
 	panic("unimplemented")
 }
 
@@ -25,6 +32,30 @@ func (m *MockHomeRepository) GetHomeData(userID string) (entities.HomeResponse,
 	return args.Get(0).(entities.HomeResponse), args.Error(1)
 }
 
+// Mock FxService
+type MockFxService struct {
+	mock.Mock
+}
+
+func (m *MockFxService) ConvertTotal(ctx context.Context, balances []money.Money, currency string) (money.Money, []entities.FxRate, error) {
+	args := m.Called(balances, currency)
+	if args.Error(2) != nil {
+		return money.Money{}, nil, args.Error(2)
+	}
+	return args.Get(0).(money.Money), args.Get(1).([]entities.FxRate), nil
+}
+
+func (m *MockFxService) ImportRatesCSV(r io.Reader) (int, error) {
+	args := m.Called(r)
+	return args.Int(0), args.Error(1)
+}
+
+func createTestConfig() *config.Config {
+	return &config.Config{
+		FX: &config.FxConfig{DisplayCurrency: "THB"},
+	}
+}
+
 func TestHomeService_GetHomeData(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -66,10 +97,9 @@ func TestHomeService_GetHomeData(t *testing.T) {
 						{
 							AccountID: "acc1",
 							Type:      "savings",
-							Amount:    5000.0,
+							Amount:    money.New(500000, "THB"),
 						},
 					},
-					TotalBalance: 5000.0,
 				}
 				mockRepo.On("GetHomeData", "user123").Return(homeData, nil)
 			},
@@ -101,13 +131,15 @@ func TestHomeService_GetHomeData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(MockHomeRepository)
-			service := NewHomeService(mockRepo)
+			mockFx := new(MockFxService)
+			mockFx.On("ConvertTotal", mock.Anything, "THB").Return(money.New(500000, "THB"), []entities.FxRate{}, nil)
+			service := NewHomeService(mockRepo, mockFx, createTestConfig())
 
 			// Setup mock expectations
 			tt.mockSetup(mockRepo)
 
 			// Act
-			data, err := service.GetHomeData(tt.userID)
+			data, err := service.GetHomeData(context.Background(), tt.userID, "")
 
 			// Assert
 			if tt.expectError {
@@ -124,7 +156,7 @@ func TestHomeService_GetHomeData(t *testing.T) {
 					assert.NotNil(t, data.Banners)
 					assert.NotNil(t, data.Transactions)
 					assert.NotNil(t, data.Accounts)
-					assert.GreaterOrEqual(t, data.TotalBalance, 0.0)
+					assert.GreaterOrEqual(t, int64(data.TotalBalance.Amount), int64(0))
 				}
 			}
 
@@ -133,3 +165,72 @@ func TestHomeService_GetHomeData(t *testing.T) {
 		})
 	}
 }
+
+func TestHomeService_GetHomeData_DisplayCurrency(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	asOf := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	homeData := entities.HomeResponse{
+		User: entities.User{UserID: "user123", Name: "John Doe"},
+		Accounts: []entities.Account{
+			{AccountID: "acc1", Amount: money.New(100000, "THB")},
+			{AccountID: "acc2", Amount: money.New(1000, "USD")},
+		},
+	}
+	balances := []money.Money{money.New(100000, "THB"), money.New(1000, "USD")}
+
+	tests := []struct {
+		name          string
+		currency      string
+		mockSetup     func(*MockFxService)
+		expectedTotal *money.Money
+		expectRates   int
+	}{
+		{
+			name:     "converted into requested currency",
+			currency: "USD",
+			mockSetup: func(m *MockFxService) {
+				m.On("ConvertTotal", balances, "USD").Return(money.New(3817, "USD"), []entities.FxRate{
+					{Base: "THB", Quote: "USD", Rate: "0.0281700000", Source: "csv", AsOf: asOf},
+				}, nil)
+			},
+			expectedTotal: &money.Money{Amount: 3817, Currency: "USD"},
+			expectRates:   1,
+		},
+		{
+			name:     "falls back to configured display currency",
+			currency: "",
+			mockSetup: func(m *MockFxService) {
+				m.On("ConvertTotal", balances, "THB").Return(money.New(135500, "THB"), []entities.FxRate{
+					{Base: "USD", Quote: "THB", Rate: "35.5000000000", Source: "csv", AsOf: asOf},
+				}, nil)
+			},
+			expectedTotal: &money.Money{Amount: 135500, Currency: "THB"},
+			expectRates:   1,
+		},
+		{
+			name:     "missing rate leaves total empty",
+			currency: "EUR",
+			mockSetup: func(m *MockFxService) {
+				m.On("ConvertTotal", balances, "EUR").Return(nil, nil, errors.New("THB/EUR: fx: rate not found"))
+			},
+			expectedTotal: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockHomeRepository)
+			mockRepo.On("GetHomeData", "user123").Return(homeData, nil)
+			mockFx := new(MockFxService)
+			tt.mockSetup(mockFx)
+
+			data, err := NewHomeService(mockRepo, mockFx, createTestConfig()).GetHomeData(context.Background(), "user123", tt.currency)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, data.TotalBalance)
+			assert.Len(t, data.TotalBalanceRates, tt.expectRates)
+			assert.Len(t, data.Accounts, 2)
+			mockFx.AssertExpectations(t)
+		})
+	}
+}
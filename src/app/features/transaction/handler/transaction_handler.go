@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/transaction/service"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type transactionHandler struct {
+	service service.TransactionService
+}
+
+func NewTransactionHandler(router fiber.Router, service service.TransactionService) {
+	handler := &transactionHandler{
+		service: service,
+	}
+
+	transactions := router.Group("/transactions")
+	transactions.Get("/", middlewares.AuthMiddleware(), handler.GetTransactions)
+}
+
+func (h *transactionHandler) GetTransactions(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var params entities.PaginationParams
+	if err := c.QueryParser(&params); err != nil {
+		return exception.ErrInvalidPagination
+	}
+	params.SetDefaults()
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	transactions, meta, err := h.service.GetTransactions(user.UserID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(&entities.PaginatedResponse{
+		SuccessResponse: response.SuccessResponse{
+			Code:    response.Success,
+			Message: "Transactions retrieved successfully",
+			Data:    transactions,
+		},
+		Meta: meta,
+	})
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockTransactionService implements the transaction service interface for testing
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, entities.PaginationMeta, error) {
+	args := m.Called(userID, params)
+	if args.Error(2) != nil {
+		return nil, entities.PaginationMeta{}, args.Error(2)
+	}
+	return args.Get(0).([]entities.Transaction), args.Get(1).(entities.PaginationMeta), nil
+}
+
+func setupTestApp(handler *transactionHandler) *fiber.App {
+	logger.Logger = zap.NewNop().Sugar()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler(),
+	})
+	app.Get("/transactions", func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return handler.GetTransactions(c)
+	})
+	return app
+}
+
+func TestTransactionHandler_GetTransactions(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockTransactionService)
+		expectedStatus int
+		expectMeta     bool
+	}{
+		{
+			name:  "defaults applied when query is empty",
+			query: "",
+			mockSetup: func(m *MockTransactionService) {
+				params := entities.PaginationParams{Page: 1, PerPage: entities.DefaultPerPage, Order: "asc"}
+				m.On("GetTransactions", "user123", params).Return(
+					[]entities.Transaction{{TransactionID: "txn1"}},
+					entities.NewPaginationMeta(1, entities.DefaultPerPage, 1),
+					nil,
+				)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectMeta:     true,
+		},
+		{
+			name:  "explicit page, search and sort",
+			query: "?page=2&perPage=5&search=coffee&sort=name&order=desc",
+			mockSetup: func(m *MockTransactionService) {
+				params := entities.PaginationParams{Page: 2, PerPage: 5, Search: "coffee", Sort: "name", Order: "desc"}
+				m.On("GetTransactions", "user123", params).Return(
+					[]entities.Transaction{},
+					entities.NewPaginationMeta(2, 5, 6),
+					nil,
+				)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectMeta:     true,
+		},
+		{
+			name:  "cursor mode defaults limit",
+			query: "?cursor=abc.def",
+			mockSetup: func(m *MockTransactionService) {
+				params := entities.PaginationParams{Page: 1, PerPage: entities.DefaultPerPage, Order: "asc", Cursor: "abc.def", Limit: entities.DefaultPerPage}
+				m.On("GetTransactions", "user123", params).Return(
+					[]entities.Transaction{},
+					entities.PaginationMeta{PerPage: entities.DefaultPerPage, NextCursor: "next"},
+					nil,
+				)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectMeta:     true,
+		},
+		{
+			name:           "limit over maximum fails validation",
+			query:          "?limit=1000",
+			mockSetup:      func(m *MockTransactionService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "perPage over limit fails validation",
+			query:          "?perPage=500",
+			mockSetup:      func(m *MockTransactionService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid order fails validation",
+			query:          "?order=sideways",
+			mockSetup:      func(m *MockTransactionService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "non numeric page",
+			query:          "?page=abc",
+			mockSetup:      func(m *MockTransactionService) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:  "unsupported sort field from service",
+			query: "?sort=amount",
+			mockSetup: func(m *MockTransactionService) {
+				m.On("GetTransactions", "user123", mock.Anything).Return(nil, nil, exception.ErrInvalidSortField)
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&transactionHandler{service: mockService})
+
+			req := httptest.NewRequest("GET", "/transactions"+tt.query, nil)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectMeta {
+				body, _ := io.ReadAll(resp.Body)
+				var result map[string]interface{}
+				assert.NoError(t, json.Unmarshal(body, &result))
+				assert.Contains(t, result, "meta")
+				assert.Contains(t, result, "data")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransactionHandler_GetTransactions_NoUserContext(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	handler := &transactionHandler{service: new(MockTransactionService)}
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler()})
+	app.Get("/transactions", handler.GetTransactions)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/transactions", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
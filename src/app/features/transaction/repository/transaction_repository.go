@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/server/exception"
+	"gorm.io/gorm"
+)
+
+// sortable API fields mapped to their column names
+var transactionSortColumns = map[string]string{
+	"transactionID": "transaction_id",
+	"name":          "name",
+}
+
+// likeEscaper makes LIKE wildcards in a search term match literally. '!' is
+// used as the escape character since a backslash depends on the SQL mode.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+type TransactionRepository interface {
+	GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, int, error)
+	GetTransactionsByCursor(userID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.Transaction, bool, error)
+}
+
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{
+		db: db,
+	}
+}
+
+func (r *transactionRepository) sortColumn(sort string) (string, error) {
+	if sort == "" {
+		return "transaction_id", nil
+	}
+	column, ok := transactionSortColumns[sort]
+	if !ok {
+		return "", exception.ErrInvalidSortField
+	}
+	return column, nil
+}
+
+func (r *transactionRepository) baseQuery(userID, search string) *gorm.DB {
+	query := r.db.Model(&models.Transaction{}).Where("user_id = ?", userID)
+	if search != "" {
+		query = query.Where("name LIKE ? ESCAPE '!'", "%"+likeEscaper.Replace(search)+"%")
+	}
+	return query
+}
+
+func (r *transactionRepository) GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, int, error) {
+	column, err := r.sortColumn(params.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	order := "ASC"
+	if params.Order == "desc" {
+		order = "DESC"
+	}
+
+	query := r.baseQuery(userID, params.Search)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []models.Transaction
+	orderBy := fmt.Sprintf("%s %s", column, order)
+	if column != "transaction_id" {
+		// tie-breaker keeps pages stable when sort values repeat
+		orderBy += ", transaction_id " + order
+	}
+	if err := query.Order(orderBy).
+		Limit(params.PerPage).
+		Offset(params.Offset()).
+		Find(&transactions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return toTransactionEntities(transactions), int(total), nil
+}
+
+// GetTransactionsByCursor seeks past the cursor instead of counting and
+// skipping rows, it reports whether more rows exist in the scan direction.
+func (r *transactionRepository) GetTransactionsByCursor(userID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.Transaction, bool, error) {
+	column, err := r.sortColumn(params.Sort)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := pagination.ApplyKeyset(r.baseQuery(userID, params.Search), column, "transaction_id", params.Order, cursor)
+
+	var transactions []models.Transaction
+	if err := query.Limit(params.Limit + 1).Find(&transactions).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(transactions) > params.Limit
+	if hasMore {
+		transactions = transactions[:params.Limit]
+	}
+
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+			transactions[i], transactions[j] = transactions[j], transactions[i]
+		}
+	}
+
+	return toTransactionEntities(transactions), hasMore, nil
+}
+
+func toTransactionEntities(transactions []models.Transaction) []entities.Transaction {
+	result := make([]entities.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, entities.Transaction{
+			TransactionID: t.TransactionID,
+			UserID:        t.UserID,
+			Name:          t.Name,
+			Image:         t.Image,
+			IsBank:        t.IsBank,
+		})
+	}
+	return result
+}
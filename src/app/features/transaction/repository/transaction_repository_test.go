@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func TestTransactionRepository_GetTransactions(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        entities.PaginationParams
+		mockSetup     func(sqlmock.Sqlmock)
+		expectError   error
+		expectCount   int
+		expectTotal   int
+		expectFirstID string
+	}{
+		{
+			name:   "first page default order",
+			params: entities.PaginationParams{Page: 1, PerPage: 2, Order: "asc"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `transactions` WHERE user_id = \\?").
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? ORDER BY transaction_id ASC LIMIT \\?").
+					WithArgs("user123", 2).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "user_id", "name", "image", "isBank"}).
+						AddRow("txn1", "user123", "Coffee", "img1", false).
+						AddRow("txn2", "user123", "Bank", "img2", true))
+			},
+			expectCount:   2,
+			expectTotal:   3,
+			expectFirstID: "txn1",
+		},
+		{
+			name:   "second page with search and sort by name desc",
+			params: entities.PaginationParams{Page: 2, PerPage: 10, Search: "cof", Sort: "name", Order: "desc"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `transactions` WHERE user_id = \\? AND name LIKE \\? ESCAPE '!'").
+					WithArgs("user123", "%cof%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+				mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? AND name LIKE \\? ESCAPE '!' ORDER BY name DESC, transaction_id DESC LIMIT \\? OFFSET \\?").
+					WithArgs("user123", "%cof%", 10, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "user_id", "name"}).
+						AddRow("txn11", "user123", "Coffee"))
+			},
+			expectCount:   1,
+			expectTotal:   11,
+			expectFirstID: "txn11",
+		},
+		{
+			name:   "search wildcards match literally",
+			params: entities.PaginationParams{Page: 1, PerPage: 10, Search: "50%_off!"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `transactions` WHERE user_id = \\? AND name LIKE \\? ESCAPE '!'").
+					WithArgs("user123", "%50!%!_off!!%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? AND name LIKE \\? ESCAPE '!' ORDER BY transaction_id ASC LIMIT \\?").
+					WithArgs("user123", "%50!%!_off!!%", 10).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "user_id", "name"}).
+						AddRow("txn7", "user123", "50%_off!"))
+			},
+			expectCount:   1,
+			expectTotal:   1,
+			expectFirstID: "txn7",
+		},
+		{
+			name:        "unsupported sort field",
+			params:      entities.PaginationParams{Page: 1, PerPage: 10, Sort: "amount"},
+			mockSetup:   func(mock sqlmock.Sqlmock) {},
+			expectError: exception.ErrInvalidSortField,
+		},
+		{
+			name:   "count query error",
+			params: entities.PaginationParams{Page: 1, PerPage: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `transactions`").
+					WillReturnError(errors.New("connection lost"))
+			},
+			expectError: errors.New("connection lost"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			repo := NewTransactionRepository(gormDB)
+			tt.mockSetup(mock)
+
+			transactions, total, err := repo.GetTransactions("user123", tt.params)
+
+			if tt.expectError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, transactions, tt.expectCount)
+				assert.Equal(t, tt.expectTotal, total)
+				assert.Equal(t, tt.expectFirstID, transactions[0].TransactionID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionRepository_GetTransactionsByCursor(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      entities.PaginationParams
+		cursor      *pagination.Cursor
+		mockSetup   func(sqlmock.Sqlmock)
+		expectIDs   []string
+		expectMore  bool
+		expectError error
+	}{
+		{
+			name:   "first page fetches one extra row to detect more",
+			params: entities.PaginationParams{Limit: 2, Order: "asc"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? ORDER BY transaction_id ASC LIMIT \\?").
+					WithArgs("user123", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "user_id"}).
+						AddRow("txn1", "user123").
+						AddRow("txn2", "user123").
+						AddRow("txn3", "user123"))
+			},
+			expectIDs:  []string{"txn1", "txn2"},
+			expectMore: true,
+		},
+		{
+			name:   "backward page is reversed into display order",
+			params: entities.PaginationParams{Limit: 2, Sort: "name", Order: "asc"},
+			cursor: &pagination.Cursor{Sort: "name", Order: "asc", Value: "Cafe", ID: "txn9", Backward: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `transactions` WHERE user_id = \\? AND \\(\\(name < \\? OR \\(name = \\? AND transaction_id < \\?\\)\\)\\) ORDER BY name DESC, transaction_id DESC LIMIT \\?").
+					WithArgs("user123", "Cafe", "Cafe", "txn9", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "name"}).
+						AddRow("txn8", "Bakery").
+						AddRow("txn7", "Apple"))
+			},
+			expectIDs:  []string{"txn7", "txn8"},
+			expectMore: false,
+		},
+		{
+			name:        "unsupported sort field",
+			params:      entities.PaginationParams{Limit: 2, Sort: "amount"},
+			mockSetup:   func(mock sqlmock.Sqlmock) {},
+			expectError: exception.ErrInvalidSortField,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			repo := NewTransactionRepository(gormDB)
+			tt.mockSetup(mock)
+
+			transactions, hasMore, err := repo.GetTransactionsByCursor("user123", tt.params, tt.cursor)
+
+			if tt.expectError != nil {
+				assert.Equal(t, tt.expectError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectMore, hasMore)
+				var ids []string
+				for _, txn := range transactions {
+					ids = append(ids, txn.TransactionID)
+				}
+				assert.Equal(t, tt.expectIDs, ids)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/transaction/repository"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+)
+
+type transactionService struct {
+	config *config.Config
+	repo   repository.TransactionRepository
+}
+
+type TransactionService interface {
+	GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, entities.PaginationMeta, error)
+}
+
+func NewTransactionService(repo repository.TransactionRepository, config *config.Config) TransactionService {
+	return &transactionService{
+		config: config,
+		repo:   repo,
+	}
+}
+
+func (s *transactionService) GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, entities.PaginationMeta, error) {
+	if params.IsCursorMode() {
+		return s.getTransactionsByCursor(userID, params)
+	}
+
+	transactions, total, err := s.repo.GetTransactions(userID, params)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	return transactions, entities.NewPaginationMeta(params.Page, params.PerPage, total), nil
+}
+
+func (s *transactionService) getTransactionsByCursor(userID string, params entities.PaginationParams) ([]entities.Transaction, entities.PaginationMeta, error) {
+	secret := s.config.Pagination.CursorSecret
+
+	cursor, err := pagination.DecodeParams(params, secret)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, entities.PaginationMeta{}, exception.ErrInvalidCursor
+		}
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	transactions, hasMore, err := s.repo.GetTransactionsByCursor(userID, params, cursor)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, err
+	}
+
+	var first, last *pagination.Cursor
+	if len(transactions) > 0 {
+		first = transactionCursor(transactions[0], params)
+		last = transactionCursor(transactions[len(transactions)-1], params)
+	}
+
+	meta, err := pagination.NewCursorMeta(params.Limit, cursor, hasMore, first, last, secret)
+	if err != nil {
+		return nil, entities.PaginationMeta{}, exception.NewInternalError(err)
+	}
+
+	return transactions, meta, nil
+}
+
+func transactionCursor(t entities.Transaction, params entities.PaginationParams) *pagination.Cursor {
+	cursor := &pagination.Cursor{
+		Sort:  params.Sort,
+		Order: params.Order,
+		ID:    t.TransactionID,
+	}
+	if params.Sort == "name" {
+		cursor.Value = t.Name
+	}
+	return cursor
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/pagination"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock TransactionRepository
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) GetTransactions(userID string, params entities.PaginationParams) ([]entities.Transaction, int, error) {
+	args := m.Called(userID, params)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]entities.Transaction), args.Int(1), args.Error(2)
+}
+
+func (m *MockTransactionRepository) GetTransactionsByCursor(userID string, params entities.PaginationParams, cursor *pagination.Cursor) ([]entities.Transaction, bool, error) {
+	args := m.Called(userID, params, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]entities.Transaction), args.Bool(1), args.Error(2)
+}
+
+func createTestConfig() *config.Config {
+	return &config.Config{
+		Pagination: &config.PaginationConfig{CursorSecret: "test-cursor-secret"},
+	}
+}
+
+func TestTransactionService_GetTransactions(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      entities.PaginationParams
+		mockSetup   func(*MockTransactionRepository)
+		expectError bool
+		expectMeta  entities.PaginationMeta
+	}{
+		{
+			name:   "middle page has next and previous",
+			params: entities.PaginationParams{Page: 2, PerPage: 10},
+			mockSetup: func(m *MockTransactionRepository) {
+				m.On("GetTransactions", "user123", entities.PaginationParams{Page: 2, PerPage: 10}).
+					Return([]entities.Transaction{{TransactionID: "txn11"}}, 25, nil)
+			},
+			expectMeta: entities.PaginationMeta{Page: 2, PerPage: 10, Total: 25, TotalPages: 3, HasNext: true, HasPrevious: true},
+		},
+		{
+			name:   "empty history",
+			params: entities.PaginationParams{Page: 1, PerPage: 20},
+			mockSetup: func(m *MockTransactionRepository) {
+				m.On("GetTransactions", "user123", entities.PaginationParams{Page: 1, PerPage: 20}).
+					Return([]entities.Transaction{}, 0, nil)
+			},
+			expectMeta: entities.PaginationMeta{Page: 1, PerPage: 20},
+		},
+		{
+			name:   "repository error",
+			params: entities.PaginationParams{Page: 1, PerPage: 20},
+			mockSetup: func(m *MockTransactionRepository) {
+				m.On("GetTransactions", "user123", entities.PaginationParams{Page: 1, PerPage: 20}).
+					Return(nil, 0, errors.New("db error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTransactionRepository)
+			tt.mockSetup(mockRepo)
+			service := NewTransactionService(mockRepo, createTestConfig())
+
+			transactions, meta, err := service.GetTransactions("user123", tt.params)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, transactions)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectMeta, meta)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransactionService_GetTransactions_CursorMode(t *testing.T) {
+	cfg := createTestConfig()
+	secret := cfg.Pagination.CursorSecret
+
+	t.Run("first page returns next cursor on last row", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		params := entities.PaginationParams{Limit: 2, Sort: "name", Order: "asc"}
+		mockRepo.On("GetTransactionsByCursor", "user123", params, (*pagination.Cursor)(nil)).
+			Return([]entities.Transaction{
+				{TransactionID: "txn1", Name: "Apple"},
+				{TransactionID: "txn2", Name: "Bakery"},
+			}, true, nil)
+
+		transactions, meta, err := NewTransactionService(mockRepo, cfg).GetTransactions("user123", params)
+
+		assert.NoError(t, err)
+		assert.Len(t, transactions, 2)
+		assert.True(t, meta.HasNext)
+		assert.False(t, meta.HasPrevious)
+		assert.Equal(t, 2, meta.PerPage)
+
+		next, err := pagination.Decode(meta.NextCursor, secret)
+		assert.NoError(t, err)
+		assert.Equal(t, "txn2", next.ID)
+		assert.Equal(t, "Bakery", next.Value)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("following a cursor passes it to the repository", func(t *testing.T) {
+		token, _ := pagination.Encode(pagination.Cursor{Order: "asc", ID: "txn2"}, secret)
+		params := entities.PaginationParams{Cursor: token, Limit: 2, Order: "asc"}
+
+		mockRepo := new(MockTransactionRepository)
+		mockRepo.On("GetTransactionsByCursor", "user123", params, &pagination.Cursor{Order: "asc", ID: "txn2"}).
+			Return([]entities.Transaction{{TransactionID: "txn3"}}, false, nil)
+
+		_, meta, err := NewTransactionService(mockRepo, cfg).GetTransactions("user123", params)
+
+		assert.NoError(t, err)
+		assert.False(t, meta.HasNext)
+		assert.True(t, meta.HasPrevious)
+		assert.Empty(t, meta.NextCursor)
+		assert.NotEmpty(t, meta.PrevCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("tampered cursor is rejected", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		params := entities.PaginationParams{Cursor: "bogus.cursor", Limit: 2, Order: "asc"}
+
+		_, _, err := NewTransactionService(mockRepo, cfg).GetTransactions("user123", params)
+
+		assert.Equal(t, exception.ErrInvalidCursor, err)
+		mockRepo.AssertNotCalled(t, "GetTransactionsByCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/transfer/service"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// transferRecentAuthMaxAge is how long after entering the PIN a session can
+// still make transfers.
+const transferRecentAuthMaxAge = 10 * time.Minute
+
+type transferHandler struct {
+	service service.TransferService
+}
+
+func NewTransferHandler(router fiber.Router, service service.TransferService) {
+	handler := &transferHandler{
+		service: service,
+	}
+
+	router.Post("/transfers", middlewares.AuthMiddleware(), middlewares.RequireTwoFactor(), middlewares.RequireRecentAuth(transferRecentAuthMaxAge), middlewares.IdempotencyMiddleware(), handler.CreateTransfer)
+}
+
+func (h *transferHandler) CreateTransfer(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(entities.Claims)
+	if !ok {
+		return exception.ErrInternalServer
+	}
+
+	var req entities.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return exception.ErrValidationFailed
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	transfer, err := h.service.CreateTransfer(user.UserID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(&response.SuccessResponse{
+		Code:    response.Success,
+		Message: "Transfer completed successfully",
+		Data:    transfer,
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/validators"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/middlewares"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockTransferService implements the transfer service interface for testing
+type MockTransferService struct {
+	mock.Mock
+}
+
+func (m *MockTransferService) CreateTransfer(userID string, req entities.TransferRequest) (*entities.TransferResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TransferResponse), args.Error(1)
+}
+
+func setupTestApp(handler *transferHandler) *fiber.App {
+	logger.Logger = zap.NewNop().Sugar()
+	validators.RegisterCustomValidations()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler(),
+	})
+	app.Post("/transfers", func(c *fiber.Ctx) error {
+		c.Locals("user", entities.Claims{UserID: "user123", Username: "testuser"})
+		return handler.CreateTransfer(c)
+	})
+	return app
+}
+
+func TestTransferHandler_CreateTransfer(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockTransferService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: `{"fromAccountID":"acc1","toAccountID":"acc2","amount":25.5}`,
+			mockSetup: func(m *MockTransferService) {
+				req := entities.TransferRequest{FromAccountID: "acc1", ToAccountID: "acc2", Amount: 2550}
+				m.On("CreateTransfer", "user123", req).Return(&entities.TransferResponse{TransferID: "tr-1"}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "missing amount",
+			body:           `{"fromAccountID":"acc1","toAccountID":"acc2"}`,
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "negative amount",
+			body:           `{"fromAccountID":"acc1","toAccountID":"acc2","amount":-5}`,
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "amount as decimal string",
+			body: `{"fromAccountID":"acc1","toAccountID":"acc2","amount":"0.10"}`,
+			mockSetup: func(m *MockTransferService) {
+				req := entities.TransferRequest{FromAccountID: "acc1", ToAccountID: "acc2", Amount: 10}
+				m.On("CreateTransfer", "user123", req).Return(&entities.TransferResponse{TransferID: "tr-2"}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "sub-cent amount",
+			body:           `{"fromAccountID":"acc1","toAccountID":"acc2","amount":1.001}`,
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "malformed body",
+			body:           `{"amount":`,
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "insufficient funds",
+			body: `{"fromAccountID":"acc1","toAccountID":"acc2","amount":1000}`,
+			mockSetup: func(m *MockTransferService) {
+				m.On("CreateTransfer", "user123", mock.Anything).Return(nil, exception.ErrInsufficientFunds)
+			},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "same account",
+			body: `{"fromAccountID":"acc1","toAccountID":"acc1","amount":10}`,
+			mockSetup: func(m *MockTransferService) {
+				m.On("CreateTransfer", "user123", mock.Anything).Return(nil, exception.ErrSameAccountTransfer)
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransferService)
+			tt.mockSetup(mockService)
+			app := setupTestApp(&transferHandler{service: mockService})
+
+			req := httptest.NewRequest("POST", "/transfers", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
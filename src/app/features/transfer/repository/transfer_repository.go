@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/server/exception"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+type TransferRepository interface {
+	CreateTransfer(transfer *models.Transfer) ([]models.LedgerEntry, error)
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{
+		db: db,
+	}
+}
+
+// CreateTransfer moves transfer.Amount between two of transfer.UserID's accounts
+// and records both legs in the ledger. It returns the debit entry followed by
+// the credit entry.
+func (r *transferRepository) CreateTransfer(transfer *models.Transfer) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		accountIDs := []string{transfer.FromAccountID, transfer.ToAccountID}
+
+		var accounts []models.Account
+		if err := tx.Where("account_id IN ? AND user_id = ?", accountIDs, transfer.UserID).
+			Find(&accounts).Error; err != nil {
+			return err
+		}
+		if len(accounts) != 2 {
+			return exception.ErrAccountNotFound
+		}
+		if accounts[0].Currency != accounts[1].Currency {
+			return exception.ErrCurrencyMismatch
+		}
+		transfer.Currency = accounts[0].Currency
+
+		// lock in a fixed order so concurrent opposite transfers cannot deadlock
+		var balances []models.AccountBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id IN ?", accountIDs).
+			Order("account_id").
+			Find(&balances).Error; err != nil {
+			return err
+		}
+		if len(balances) != 2 {
+			return exception.ErrAccountNotFound
+		}
+
+		var from, to *models.AccountBalance
+		for i := range balances {
+			if balances[i].AccountID == transfer.FromAccountID {
+				from = &balances[i]
+			} else {
+				to = &balances[i]
+			}
+		}
+		if from.Amount < transfer.Amount {
+			return exception.ErrInsufficientFunds
+		}
+
+		from.Amount -= transfer.Amount
+		to.Amount += transfer.Amount
+
+		for _, balance := range []*models.AccountBalance{from, to} {
+			if err := tx.Model(&models.AccountBalance{}).
+				Where("account_id = ?", balance.AccountID).
+				Update("amount", balance.Amount).Error; err != nil {
+				return err
+			}
+		}
+
+		transfer.CreatedAt = time.Now()
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+
+		entries = []models.LedgerEntry{
+			newTransferEntry(transfer, from, models.LedgerDirectionDebit),
+			newTransferEntry(transfer, to, models.LedgerDirectionCredit),
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func newTransferEntry(transfer *models.Transfer, balance *models.AccountBalance, direction string) models.LedgerEntry {
+	return models.LedgerEntry{
+		TransferID:   &transfer.TransferID,
+		AccountID:    balance.AccountID,
+		UserID:       transfer.UserID,
+		EntryType:    models.LedgerEntryTypeTransfer,
+		Direction:    direction,
+		Amount:       transfer.Amount,
+		Currency:     transfer.Currency,
+		BalanceAfter: balance.Amount,
+		CreatedAt:    transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB, mock, func() { db.Close() }
+}
+
+func expectAccounts(mock sqlmock.Sqlmock, fromCurrency, toCurrency string) {
+	mock.ExpectQuery("SELECT \\* FROM `accounts` WHERE account_id IN \\(\\?,\\?\\) AND user_id = \\?").
+		WithArgs("acc1", "acc2", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "user_id", "currency"}).
+			AddRow("acc1", "user123", fromCurrency).
+			AddRow("acc2", "user123", toCurrency))
+}
+
+func expectLockedBalances(mock sqlmock.Sqlmock, fromAmount, toAmount string) {
+	mock.ExpectQuery("SELECT \\* FROM `account_balances` WHERE account_id IN \\(\\?,\\?\\) ORDER BY account_id FOR UPDATE").
+		WithArgs("acc1", "acc2").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "user_id", "amount"}).
+			AddRow("acc1", "user123", fromAmount).
+			AddRow("acc2", "user123", toAmount))
+}
+
+func newTransfer(amount money.Amount) *models.Transfer {
+	return &models.Transfer{
+		TransferID:    "tr-1",
+		UserID:        "user123",
+		FromAccountID: "acc1",
+		ToAccountID:   "acc2",
+		Amount:        amount,
+	}
+}
+
+func TestTransferRepository_CreateTransfer(t *testing.T) {
+	gormDB, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectAccounts(mock, "THB", "THB")
+	expectLockedBalances(mock, "100.10", "50.00")
+	mock.ExpectExec("UPDATE `account_balances` SET `amount`=\\? WHERE account_id = \\?").
+		WithArgs("75.05", "acc1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `account_balances` SET `amount`=\\? WHERE account_id = \\?").
+		WithArgs("75.05", "acc2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `transfers`").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `ledger_entries`").
+		WithArgs(
+			"tr-1", "acc1", "user123", models.LedgerEntryTypeTransfer, models.LedgerDirectionDebit, "25.05", "THB", "75.05", sqlmock.AnyArg(),
+			"tr-1", "acc2", "user123", models.LedgerEntryTypeTransfer, models.LedgerDirectionCredit, "25.05", "THB", "75.05", sqlmock.AnyArg(),
+		).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	transfer := newTransfer(2505)
+	entries, err := NewTransferRepository(gormDB).CreateTransfer(transfer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "THB", transfer.Currency)
+	assert.False(t, transfer.CreatedAt.IsZero())
+	assert.Len(t, entries, 2)
+	assert.Equal(t, models.LedgerDirectionDebit, entries[0].Direction)
+	assert.Equal(t, models.LedgerDirectionCredit, entries[1].Direction)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferRepository_CreateTransfer_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      money.Amount
+		mockSetup   func(sqlmock.Sqlmock)
+		expectError error
+	}{
+		{
+			name:   "account not owned by user",
+			amount: 1000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `accounts`").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id", "currency"}).AddRow("acc1", "THB"))
+			},
+			expectError: exception.ErrAccountNotFound,
+		},
+		{
+			name:   "currency mismatch",
+			amount: 1000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectAccounts(mock, "THB", "USD")
+			},
+			expectError: exception.ErrCurrencyMismatch,
+		},
+		{
+			name:   "insufficient funds",
+			amount: 10001,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectAccounts(mock, "THB", "THB")
+				expectLockedBalances(mock, "100.00", "0.00")
+			},
+			expectError: exception.ErrInsufficientFunds,
+		},
+		{
+			name:   "ledger insert fails",
+			amount: 1000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectAccounts(mock, "THB", "THB")
+				expectLockedBalances(mock, "100.00", "0.00")
+				mock.ExpectExec("UPDATE `account_balances`").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `account_balances`").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `transfers`").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `ledger_entries`").WillReturnError(errors.New("connection lost"))
+			},
+			expectError: errors.New("connection lost"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			tt.mockSetup(mock)
+			mock.ExpectRollback()
+
+			entries, err := NewTransferRepository(gormDB).CreateTransfer(newTransfer(tt.amount))
+
+			assert.Nil(t, entries)
+			assert.Equal(t, tt.expectError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
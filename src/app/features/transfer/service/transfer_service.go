@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/features/transfer/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/google/uuid"
+)
+
+type transferService struct {
+	repo repository.TransferRepository
+}
+
+type TransferService interface {
+	CreateTransfer(userID string, req entities.TransferRequest) (*entities.TransferResponse, error)
+}
+
+func NewTransferService(repo repository.TransferRepository) TransferService {
+	return &transferService{
+		repo: repo,
+	}
+}
+
+func (s *transferService) CreateTransfer(userID string, req entities.TransferRequest) (*entities.TransferResponse, error) {
+	if req.FromAccountID == req.ToAccountID {
+		return nil, exception.ErrSameAccountTransfer
+	}
+
+	transfer := &models.Transfer{
+		TransferID:    uuid.New().String(),
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Note:          req.Note,
+	}
+
+	entries, err := s.repo.CreateTransfer(transfer)
+	if err != nil {
+		if errResp, ok := err.(*response.ErrorResponse); ok {
+			return nil, errResp
+		}
+		logger.Errorf("Failed to create transfer for user %s: %v", userID, err)
+		return nil, exception.NewDatabaseError(err)
+	}
+
+	logger.Infof("Transfer %s completed for user %s: %s -> %s", transfer.TransferID, userID, transfer.FromAccountID, transfer.ToAccountID)
+
+	return &entities.TransferResponse{
+		TransferID:    transfer.TransferID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, transfer.Currency),
+		Note:          transfer.Note,
+		FromBalance:   money.New(entries[0].BalanceAfter, transfer.Currency),
+		ToBalance:     money.New(entries[1].BalanceAfter, transfer.Currency),
+		CreatedAt:     transfer.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/app/money"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock TransferRepository
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) CreateTransfer(transfer *models.Transfer) ([]models.LedgerEntry, error) {
+	args := m.Called(transfer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LedgerEntry), args.Error(1)
+}
+
+func TestTransferService_CreateTransfer(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+
+	req := entities.TransferRequest{FromAccountID: "acc1", ToAccountID: "acc2", Amount: 2550, Note: "rent"}
+
+	tests := []struct {
+		name           string
+		req            entities.TransferRequest
+		mockSetup      func(*MockTransferRepository)
+		expectError    error
+		expectDBError  bool
+		expectResponse bool
+	}{
+		{
+			name: "success",
+			req:  req,
+			mockSetup: func(m *MockTransferRepository) {
+				m.On("CreateTransfer", mock.MatchedBy(func(tr *models.Transfer) bool {
+					return tr.TransferID != "" && tr.UserID == "user123" && tr.Amount == 2550 && tr.Note == "rent"
+				})).Run(func(args mock.Arguments) {
+					tr := args.Get(0).(*models.Transfer)
+					tr.Currency = "THB"
+					tr.CreatedAt = time.Now()
+				}).Return([]models.LedgerEntry{
+					{Direction: models.LedgerDirectionDebit, BalanceAfter: 7450},
+					{Direction: models.LedgerDirectionCredit, BalanceAfter: 12550},
+				}, nil)
+			},
+			expectResponse: true,
+		},
+		{
+			name:        "same account",
+			req:         entities.TransferRequest{FromAccountID: "acc1", ToAccountID: "acc1", Amount: 100},
+			mockSetup:   func(m *MockTransferRepository) {},
+			expectError: exception.ErrSameAccountTransfer,
+		},
+		{
+			name: "domain error passes through",
+			req:  req,
+			mockSetup: func(m *MockTransferRepository) {
+				m.On("CreateTransfer", mock.Anything).Return(nil, exception.ErrInsufficientFunds)
+			},
+			expectError: exception.ErrInsufficientFunds,
+		},
+		{
+			name: "database error is wrapped",
+			req:  req,
+			mockSetup: func(m *MockTransferRepository) {
+				m.On("CreateTransfer", mock.Anything).Return(nil, errors.New("deadlock found"))
+			},
+			expectDBError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTransferRepository)
+			tt.mockSetup(mockRepo)
+
+			result, err := NewTransferService(mockRepo).CreateTransfer("user123", tt.req)
+
+			switch {
+			case tt.expectError != nil:
+				assert.Equal(t, tt.expectError, err)
+				assert.Nil(t, result)
+			case tt.expectDBError:
+				errResp, ok := err.(*response.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, response.ErrCodeDatabaseError, errResp.Code)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.TransferID)
+				assert.Equal(t, money.New(2550, "THB"), result.Amount)
+				assert.Equal(t, money.New(7450, "THB"), result.FromBalance)
+				assert.Equal(t, money.New(12550, "THB"), result.ToBalance)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
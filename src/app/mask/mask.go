@@ -0,0 +1,59 @@
+package mask
+
+import "regexp"
+
+// VisibleDigits is how many trailing digits stay readable in a masked number.
+const VisibleDigits = 4
+
+const maskChar = '*'
+
+// numberPattern matches card PANs (plain or in 4-4-4-4 / 4-6-5 groups) and
+// account numbers (plain or in the 3-1-5-1 bank format). Dates and times are
+// never grouped like this, so they are left alone.
+var numberPattern = regexp.MustCompile(`\b(?:` +
+	`\d{4}[ -]\d{4}[ -]\d{4}[ -]\d{1,7}|` +
+	`\d{4}[ -]\d{6}[ -]\d{4,5}|` +
+	`\d{3}-\d-\d{5}-\d|` +
+	`\d{10,19}` +
+	`)\b`)
+
+// Number replaces every digit except the last VisibleDigits with '*' and keeps
+// separators, so "4000 1234 5678 9010" becomes "**** **** **** 9010".
+func Number(number string) string {
+	digits := countDigits(number)
+	if digits <= VisibleDigits {
+		return number
+	}
+
+	masked := []byte(number)
+	toMask := digits - VisibleDigits
+	for i := 0; i < len(masked) && toMask > 0; i++ {
+		if isDigit(masked[i]) {
+			masked[i] = maskChar
+			toMask--
+		}
+	}
+	return string(masked)
+}
+
+// Redact masks every card-like or account-like number found in s.
+func Redact(s string) string {
+	if countDigits(s) < 10 {
+		return s
+	}
+	return numberPattern.ReplaceAllStringFunc(s, Number)
+}
+
+func countDigits(s string) int {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			digits++
+		}
+	}
+	return digits
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,77 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "4000 1234 5678 9010", expected: "**** **** **** 9010"},
+		{input: "4000123456789010", expected: "************9010"},
+		{input: "123456789012", expected: "********9012"},
+		{input: "123-4-56789-0", expected: "***-*-**789-0"},
+		{input: "1234", expected: "1234"},
+		{input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Number(tt.input))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "grouped card number",
+			input:    "card 4000 1234 5678 9010 frozen",
+			expected: "card **** **** **** 9010 frozen",
+		},
+		{
+			name:     "dashed card number",
+			input:    "pan=4000-1234-5678-9010",
+			expected: "pan=****-****-****-9010",
+		},
+		{
+			name:     "account number",
+			input:    `{"accountNumber":"123456789012"}`,
+			expected: `{"accountNumber":"********9012"}`,
+		},
+		{
+			name:     "bank formatted account number",
+			input:    "to 123-4-56789-0",
+			expected: "to ***-*-**789-0",
+		},
+		{
+			name:     "dates and times are kept",
+			input:    "statement 2025-07-01 12:30:00 to 2025-07-31",
+			expected: "statement 2025-07-01 12:30:00 to 2025-07-31",
+		},
+		{
+			name:     "ids with letters are kept",
+			input:    "card 000018b0e1a211ef95a30242ac180002",
+			expected: "card 000018b0e1a211ef95a30242ac180002",
+		},
+		{
+			name:     "short numbers are kept",
+			input:    "3 attempts left, retry in 300s",
+			expected: "3 attempts left, retry in 300s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Redact(tt.input))
+		})
+	}
+}
@@ -1,15 +1,24 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/money"
+	"gorm.io/gorm"
+)
 
 type Account struct {
 	AccountID     string `gorm:"column:account_id;primaryKey"`
 	UserID        string `gorm:"column:user_id"`
 	Type          string `gorm:"column:type"`
 	Currency      string `gorm:"column:currency"`
-	AccountNumber string `gorm:"column:account_number"`
+	AccountNumber string `gorm:"column:account_number;serializer:encrypted"`
 	Issuer        string `gorm:"column:issuer"`
 
+	// blind index of AccountNumber for lookups
+	AccountNumberIndex string `gorm:"column:account_number_index"`
+
 	AccountDetails AccountDetail  `gorm:"foreignKey:AccountID"`
 	AccountBalance AccountBalance `gorm:"foreignKey:AccountID"`
 	AccountFlags   []AccountFlag  `gorm:"foreignKey:AccountID"`
@@ -19,10 +28,15 @@ func (Account) TableName() string {
 	return "accounts"
 }
 
+func (a *Account) BeforeSave(tx *gorm.DB) (err error) {
+	a.AccountNumberIndex, err = encryption.BlindIndex(a.AccountNumber)
+	return err
+}
+
 type AccountBalance struct {
-	AccountID string  `gorm:"column:account_id;primaryKey"`
-	UserID    string  `gorm:"column:user_id"`
-	Amount    float64 `gorm:"column:amount"`
+	AccountID string       `gorm:"column:account_id;primaryKey"`
+	UserID    string       `gorm:"column:user_id"`
+	Amount    money.Amount `gorm:"column:amount;type:decimal(15,2)"`
 }
 
 func (AccountBalance) TableName() string {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AuditLog is one entry of the append-only auth audit log. Hash covers every
+// other column and PrevHash, so editing or removing an entry breaks the chain
+// from that entry on. PrevHash is unique, two writers can never extend the
+// same entry.
+type AuditLog struct {
+	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	EventID      string    `gorm:"column:event_id;size:36;not null;uniqueIndex"`
+	TargetUserID string    `gorm:"column:target_user_id;size:50;not null;index:idx_audit_logs_target,priority:1"`
+	Action       string    `gorm:"column:action;size:50;not null"`
+	Outcome      string    `gorm:"column:outcome;size:20;not null"`
+	ActorID      string    `gorm:"column:actor_id;size:50;not null"`
+	ActorName    string    `gorm:"column:actor_name;size:100"`
+	IPAddress    string    `gorm:"column:ip_address;size:45"`
+	UserAgent    string    `gorm:"column:user_agent;size:255"`
+	RequestID    string    `gorm:"column:request_id;size:64"`
+	Details      string    `gorm:"column:details;size:255"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:datetime(6);not null;index;index:idx_audit_logs_target,priority:2"`
+	PrevHash     string    `gorm:"column:prev_hash;size:64;not null;uniqueIndex"`
+	Hash         string    `gorm:"column:hash;size:64;not null;uniqueIndex"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
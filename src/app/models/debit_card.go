@@ -1,9 +1,27 @@
 package models
 
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"gorm.io/gorm"
+)
+
+const (
+	CardStatusActive   = "active"
+	CardStatusFrozen   = "frozen"
+	CardStatusLost     = "lost"
+	CardStatusReplaced = "replaced"
+	CardStatusClosed   = "closed"
+	// CardStatusBlocked holds migrated cards whose status was not recognised
+	CardStatusBlocked = "blocked"
+)
+
 type DebitCard struct {
-	CardID   string `json:"card_id" gorm:"column:card_id;primaryKey"`
-	UserID   string `json:"user_id" gorm:"column:user_id"`
-	Name     string `json:"name" gorm:"column:name"`
+	CardID         string  `json:"card_id" gorm:"column:card_id;primaryKey"`
+	UserID         string  `json:"user_id" gorm:"column:user_id"`
+	Name           string  `json:"name" gorm:"column:name"`
+	ReplacesCardID *string `json:"replaces_card_id" gorm:"column:replaces_card_id"`
 
 	DebitCardDetail DebitCardDetail `gorm:"foreignKey:CardID"`
 	DebitCardDesign DebitCardDesign `gorm:"foreignKey:CardID"`
@@ -15,9 +33,9 @@ func (DebitCard) TableName() string {
 }
 
 type DebitCardStatus struct {
-	CardID   string `gorm:"column:card_id;primaryKey"`
-	UserID   string `gorm:"column:user_id"`
-	Status   string `gorm:"column:status"`
+	CardID string `gorm:"column:card_id;primaryKey"`
+	UserID string `gorm:"column:user_id"`
+	Status string `gorm:"column:status"`
 }
 
 func (DebitCardStatus) TableName() string {
@@ -36,12 +54,36 @@ func (DebitCardDesign) TableName() string {
 }
 
 type DebitCardDetail struct {
-	CardID   string `gorm:"column:card_id;primaryKey"`
-	UserID   string `gorm:"column:user_id"`
-	Issuer   string `gorm:"column:issuer"`
-	Number   string `gorm:"column:number"`
+	CardID      string `gorm:"column:card_id;primaryKey"`
+	UserID      string `gorm:"column:user_id"`
+	Issuer      string `gorm:"column:issuer"`
+	Number      string `gorm:"column:number;serializer:encrypted"`
+	NumberIndex string `gorm:"column:number_index"` // blind index of Number for lookups
 }
 
 func (DebitCardDetail) TableName() string {
 	return "debit_card_details"
 }
+
+func (d *DebitCardDetail) BeforeSave(tx *gorm.DB) (err error) {
+	d.NumberIndex, err = encryption.BlindIndex(d.Number)
+	return err
+}
+
+// CardAuditLog records every card status change. Rows are only inserted.
+type CardAuditLog struct {
+	AuditID    uint64    `gorm:"column:audit_id;primaryKey;autoIncrement"`
+	CardID     string    `gorm:"column:card_id;size:50;not null;index"`
+	UserID     string    `gorm:"column:user_id;size:50;not null;index"`
+	Action     string    `gorm:"column:action;size:20;not null"`
+	FromStatus string    `gorm:"column:from_status;size:20;not null"`
+	ToStatus   string    `gorm:"column:to_status;size:20;not null"`
+	NewCardID  *string   `gorm:"column:new_card_id;size:50"`
+	IPAddress  string    `gorm:"column:ip_address;size:45"`
+	UserAgent  string    `gorm:"column:user_agent;size:255"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null"`
+}
+
+func (CardAuditLog) TableName() string {
+	return "card_audit_logs"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/Testzyler/banking-api/app/encryption"
+
+var (
+	CardNumberColumn    = encryption.Column{Table: "debit_card_details", PrimaryKey: "card_id", Name: "number", IndexName: "number_index"}
+	AccountNumberColumn = encryption.Column{Table: "accounts", PrimaryKey: "account_id", Name: "account_number", IndexName: "account_number_index"}
+	TotpSecretColumn    = encryption.Column{Table: "user_totp", PrimaryKey: "user_id", Name: "secret"}
+)
+
+// EncryptedColumns lists every column stored through the encrypted serializer,
+// for rotate_data_key to re-encrypt in batches. Migrations encrypt the columns
+// of the tables they create.
+var EncryptedColumns = []encryption.Column{
+	CardNumberColumn,
+	AccountNumberColumn,
+	TotpSecretColumn,
+}
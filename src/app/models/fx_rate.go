@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// FxRate is the price of one unit of BaseCurrency in QuoteCurrency.
+type FxRate struct {
+	BaseCurrency  string    `gorm:"column:base_currency;primaryKey;size:3"`
+	QuoteCurrency string    `gorm:"column:quote_currency;primaryKey;size:3"`
+	Rate          string    `gorm:"column:rate;type:decimal(20,10);not null"`
+	Source        string    `gorm:"column:source;size:50;not null"`
+	AsOf          time.Time `gorm:"column:as_of;not null"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FxRate) TableName() string {
+	return "fx_rates"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/money"
+)
+
+const (
+	LedgerEntryTypeOpening  = "opening"
+	LedgerEntryTypeTransfer = "transfer"
+
+	LedgerDirectionDebit  = "debit"
+	LedgerDirectionCredit = "credit"
+)
+
+type Transfer struct {
+	TransferID    string       `gorm:"column:transfer_id;primaryKey;size:36"`
+	UserID        string       `gorm:"column:user_id;size:50;not null;index"`
+	FromAccountID string       `gorm:"column:from_account_id;size:50;not null"`
+	ToAccountID   string       `gorm:"column:to_account_id;size:50;not null"`
+	Amount        money.Amount `gorm:"column:amount;type:decimal(15,2);not null"`
+	Currency      string       `gorm:"column:currency;size:10;not null"`
+	Note          string       `gorm:"column:note;size:255"`
+	CreatedAt     time.Time    `gorm:"column:created_at;not null"`
+}
+
+func (Transfer) TableName() string {
+	return "transfers"
+}
+
+// LedgerEntry is one leg of a balance movement. Rows are only ever inserted,
+// so summing credits minus debits per account rebuilds account_balances.
+type LedgerEntry struct {
+	EntryID      uint64       `gorm:"column:entry_id;primaryKey;autoIncrement"`
+	TransferID   *string      `gorm:"column:transfer_id;size:36;index"`
+	AccountID    string       `gorm:"column:account_id;size:50;not null;index"`
+	UserID       string       `gorm:"column:user_id;size:50;not null"`
+	EntryType    string       `gorm:"column:entry_type;size:20;not null"`
+	Direction    string       `gorm:"column:direction;size:10;not null"`
+	Amount       money.Amount `gorm:"column:amount;type:decimal(15,2);not null"`
+	Currency     string       `gorm:"column:currency;size:10;not null"`
+	BalanceAfter money.Amount `gorm:"column:balance_after;type:decimal(15,2);not null"`
+	CreatedAt    time.Time    `gorm:"column:created_at;not null"`
+}
+
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Role groups the scopes granted to its users, e.g. admin or support.
+type Role struct {
+	Name        string    `gorm:"column:name;primaryKey;size:50"`
+	Description string    `gorm:"column:description;size:255"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+
+	Scopes []RoleScope `gorm:"foreignKey:RoleName;references:Name" json:"scopes,omitempty"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+type RoleScope struct {
+	RoleName string `gorm:"column:role_name;primaryKey;size:50"`
+	Scope    string `gorm:"column:scope;primaryKey;size:100"`
+}
+
+func (RoleScope) TableName() string {
+	return "role_scopes"
+}
+
+type UserRole struct {
+	UserID    string    `gorm:"column:user_id;primaryKey;size:50"`
+	RoleName  string    `gorm:"column:role_name;primaryKey;size:50;index"`
+	GrantedBy string    `gorm:"column:granted_by;size:50"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
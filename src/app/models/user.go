@@ -6,6 +6,7 @@ type User struct {
 	UserID    string     `gorm:"column:user_id;primaryKey"`
 	Name      string     `gorm:"column:name"`
 	Password  string     `gorm:"column:password"`
+	BirthDate *time.Time `gorm:"column:birth_date;type:date"`
 	UpdatedAt *time.Time `gorm:"column:updated_at;autoUpdateTime"`
 
 	// Relationships - Proper GORM associations
@@ -39,6 +40,7 @@ type UserPin struct {
 	FailedPinAttempts int        `gorm:"column:failed_pin_attempts"`
 	LastPinAttemptAt  *time.Time `gorm:"column:last_pin_attempt_at"`
 	PinLockedUntil    *time.Time `gorm:"column:pin_locked_until"`
+	MustChangePin     bool       `gorm:"column:must_change_pin;not null;default:false"`
 
 	// Relationship back to User
 	User *User `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
@@ -47,3 +49,58 @@ type UserPin struct {
 func (UserPin) TableName() string {
 	return "user_pins"
 }
+
+type UserPinHistory struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    string    `gorm:"column:user_id;size:50;not null;index"`
+	HashedPin string    `gorm:"column:hashed_pin;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UserPinHistory) TableName() string {
+	return "user_pin_history"
+}
+
+// UserTotp is the user's authenticator app secret. It is pending until the
+// user confirms a first code.
+type UserTotp struct {
+	UserID       string     `gorm:"column:user_id;primaryKey;size:50"`
+	Secret       string     `gorm:"column:secret;type:varchar(255);not null;serializer:encrypted"`
+	ConfirmedAt  *time.Time `gorm:"column:confirmed_at"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserTotp) TableName() string {
+	return "user_totp"
+}
+
+type UserRecoveryCode struct {
+	ID       uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID   string     `gorm:"column:user_id;size:50;not null;index"`
+	CodeHash string     `gorm:"column:code_hash;not null"`
+	UsedAt   *time.Time `gorm:"column:used_at"`
+}
+
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// UserDevice is a device the user signed in from. A revoked device has to be
+// registered again on its next sign-in.
+type UserDevice struct {
+	ID         uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     string     `gorm:"column:user_id;size:50;not null;uniqueIndex:idx_user_devices_user_device"`
+	DeviceID   string     `gorm:"column:device_id;size:100;not null;uniqueIndex:idx_user_devices_user_device"`
+	Name       string     `gorm:"column:name;size:100;not null"`
+	Platform   string     `gorm:"column:platform;size:20;not null"`
+	PublicKey  string     `gorm:"column:public_key;type:text;not null"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	LastSeenAt time.Time  `gorm:"column:last_seen_at;not null"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+}
+
+func (UserDevice) TableName() string {
+	return "user_devices"
+}
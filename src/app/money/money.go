@@ -0,0 +1,196 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places stored for every currency, matching
+// the decimal(15,2) balance columns.
+const Scale = 2
+
+const unitsPerMajor = 100
+
+var (
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrTooPrecise       = errors.New("money: amount has more than 2 decimal places")
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+)
+
+// Amount is an exact monetary value in minor units (1/100 of the major unit).
+// It scans from and writes to decimal columns and encodes to JSON as a decimal
+// string, so values never pass through float64.
+type Amount int64
+
+// ParseAmount parses a decimal string such as "1234.5" or "-0.05". Inputs with
+// more than Scale decimal places are rejected rather than rounded.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidAmount
+	}
+	if len(frac) > Scale {
+		// trailing zeros from a wider decimal column are still exact
+		if strings.TrimRight(frac[Scale:], "0") != "" {
+			return 0, ErrTooPrecise
+		}
+		frac = frac[:Scale]
+	}
+	frac += strings.Repeat("0", Scale-len(frac))
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major > math.MaxInt64/unitsPerMajor-1 {
+		return 0, ErrInvalidAmount
+	}
+	minor, _ := strconv.ParseInt(frac, 10, 64)
+
+	units := major*unitsPerMajor + minor
+	if negative {
+		units = -units
+	}
+	return Amount(units), nil
+}
+
+// FromFloat converts a float, rounding half away from zero to the nearest
+// minor unit. Only use it at boundaries that hand over floats.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * unitsPerMajor))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with exactly Scale decimal places.
+func (a Amount) String() string {
+	units := int64(a)
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/unitsPerMajor, units%unitsPerMajor)
+}
+
+// Scan implements sql.Scanner for decimal columns.
+func (a *Amount) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		parsed, err := ParseAmount(string(v))
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	case string:
+		parsed, err := ParseAmount(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	case int64:
+		*a = Amount(v * unitsPerMajor)
+	case float64:
+		*a = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", value)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, writing the decimal string.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number and parses it exactly.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if raw == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	parsed, err := ParseAmount(raw)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Money is an amount tagged with its ISO 4217 currency code.
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// Add returns m + other. Amounts in different currencies are never added.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+// Sub returns m - other. Amounts in different currencies are never subtracted.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+// Sum adds values that all share one currency. It returns ErrCurrencyMismatch
+// when they do not, and the zero Money for an empty slice.
+func Sum(values []Money) (Money, error) {
+	if len(values) == 0 {
+		return Money{}, nil
+	}
+	total := New(0, values[0].Currency)
+	for _, v := range values {
+		var err error
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
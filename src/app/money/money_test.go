@@ -0,0 +1,130 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  Amount
+		expectErr error
+	}{
+		{input: "1234.56", expected: 123456},
+		{input: "0.29", expected: 29},
+		{input: "12.5", expected: 1250},
+		{input: "100", expected: 10000},
+		{input: "-0.05", expected: -5},
+		{input: "+3.10", expected: 310},
+		{input: "15000.5000", expected: 1500050},
+		{input: "1.005", expectErr: ErrTooPrecise},
+		{input: "", expectErr: ErrInvalidAmount},
+		{input: "1.", expectErr: ErrInvalidAmount},
+		{input: ".5", expectErr: ErrInvalidAmount},
+		{input: "1e3", expectErr: ErrInvalidAmount},
+		{input: "abc", expectErr: ErrInvalidAmount},
+		{input: "99999999999999999999", expectErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, err := ParseAmount(tt.input)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	assert.Equal(t, "1234.56", Amount(123456).String())
+	assert.Equal(t, "0.05", Amount(5).String())
+	assert.Equal(t, "-0.05", Amount(-5).String())
+	assert.Equal(t, "0.00", Amount(0).String())
+	assert.Equal(t, "-12.30", Amount(-1230).String())
+}
+
+func TestAmount_Scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected Amount
+	}{
+		{name: "decimal bytes", value: []byte("15000.50"), expected: 1500050},
+		{name: "decimal string", value: "0.10", expected: 10},
+		{name: "null", value: nil, expected: 0},
+		{name: "integer", value: int64(7), expected: 700},
+		{name: "float rounds to nearest cent", value: 0.1 + 0.2, expected: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var amount Amount
+			assert.NoError(t, amount.Scan(tt.value))
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+
+	var amount Amount
+	assert.Error(t, amount.Scan(true))
+}
+
+func TestAmount_Value(t *testing.T) {
+	value, err := Amount(-1999).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-19.99", value)
+}
+
+func TestAmount_JSON(t *testing.T) {
+	data, err := json.Marshal(Amount(25075))
+	require.NoError(t, err)
+	assert.Equal(t, `"250.75"`, string(data))
+
+	var fromNumber, fromString Amount
+	require.NoError(t, json.Unmarshal([]byte(`250.75`), &fromNumber))
+	require.NoError(t, json.Unmarshal([]byte(`"250.75"`), &fromString))
+	assert.Equal(t, Amount(25075), fromNumber)
+	assert.Equal(t, Amount(25075), fromString)
+
+	var tooPrecise Amount
+	assert.Error(t, json.Unmarshal([]byte(`1.001`), &tooPrecise))
+}
+
+func TestMoney_Add(t *testing.T) {
+	total, err := New(1010, "THB").Add(New(2020, "THB"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(3030, "THB"), total)
+
+	_, err = New(1010, "THB").Add(New(2020, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoney_Sub(t *testing.T) {
+	diff, err := New(1000, "THB").Sub(New(2525, "THB"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(-1525, "THB"), diff)
+
+	_, err = New(1000, "THB").Sub(New(1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestSum(t *testing.T) {
+	// 0.1 + 0.2 is exact in minor units
+	total, err := Sum([]Money{New(10, "THB"), New(20, "THB")})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.30 THB", total.String())
+
+	empty, err := Sum(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Money{}, empty)
+
+	_, err = Sum([]Money{New(10, "THB"), New(20, "USD")})
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,43 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidRate = errors.New("money: invalid exchange rate")
+
+// ParseRate parses a positive decimal exchange rate such as "35.8125" exactly.
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+// Convert multiplies m by rate (units of currency per unit of m.Currency) and
+// rounds half to even to the nearest minor unit, so repeated conversions do
+// not drift in one direction.
+func Convert(m Money, rate *big.Rat, currency string) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m.Amount)), rate)
+	return New(Amount(roundHalfEven(product)), currency)
+}
+
+func roundHalfEven(r *big.Rat) int64 {
+	num, den := r.Num(), r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// compare |2*rem| with den to find which side of the half we are on
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+	cmp := twiceRem.Cmp(den)
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo.Int64()
+}
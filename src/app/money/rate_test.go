@@ -0,0 +1,46 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("35.8125")
+	require.NoError(t, err)
+	assert.Equal(t, "35.8125", rate.FloatString(4))
+
+	for _, input := range []string{"", "abc", "0", "-1.5"} {
+		_, err := ParseRate(input)
+		assert.ErrorIs(t, err, ErrInvalidRate, input)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Amount
+		rate     string
+		expected Amount
+	}{
+		{name: "exact", amount: 10000, rate: "35.5", expected: 355000},
+		{name: "rounds down below half", amount: 100, rate: "0.0281", expected: 3},
+		{name: "half rounds to even down", amount: 5, rate: "0.5", expected: 2},
+		{name: "half rounds to even up", amount: 7, rate: "0.5", expected: 4},
+		{name: "negative half rounds to even", amount: -5, rate: "0.5", expected: -2},
+		{name: "negative rounds to nearest", amount: -7, rate: "0.9", expected: -6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			require.NoError(t, err)
+
+			converted := Convert(New(tt.amount, "USD"), rate, "THB")
+
+			assert.Equal(t, New(tt.expected, "THB"), converted)
+		})
+	}
+}
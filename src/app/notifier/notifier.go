@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+var ErrUnknownDriver = errors.New("notifier: unknown driver")
+
+// Message is a notification for one user. Delivery details such as the phone
+// number or email address are resolved by the Notifier.
+type Message struct {
+	UserID   string `json:"userID"`
+	Username string `json:"username"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+}
+
+// Notifier delivers messages to users over SMS, email or any other channel.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the notifier selected by Notifier.Driver, defaulting to the
+// log notifier when nothing is configured.
+func New(cfg *config.NotifierConfig) (Notifier, error) {
+	if cfg == nil || cfg.Driver == "" || cfg.Driver == DriverLog {
+		return NewLogNotifier(), nil
+	}
+	if cfg.Driver == DriverFile {
+		return NewFileNotifier(cfg.File)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier writes messages to the application log. Use it for local
+// development only, the log then contains one-time codes.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Send(ctx context.Context, msg Message) error {
+	logger.Infof("Notification for user %s (%s): %s", msg.UserID, msg.Subject, msg.Body)
+	return nil
+}
+
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+type fileEntry struct {
+	SentAt time.Time `json:"sentAt"`
+	Message
+}
+
+// NewFileNotifier appends each message as a JSON line to path, creating the
+// directory when needed.
+func NewFileNotifier(path string) (Notifier, error) {
+	if path == "" {
+		return nil, errors.New("notifier: file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("notifier: failed to create directory: %w", err)
+	}
+	return &fileNotifier{path: path}, nil
+}
+
+func (n *fileNotifier) Send(ctx context.Context, msg Message) error {
+	line, err := json.Marshal(fileEntry{SentAt: time.Now(), Message: msg})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("notifier: failed to open %s: %w", n.path, err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	n, err := New(nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &logNotifier{}, n)
+
+	n, err = New(&config.NotifierConfig{Driver: DriverFile, File: filepath.Join(t.TempDir(), "out.log")})
+	assert.NoError(t, err)
+	assert.IsType(t, &fileNotifier{}, n)
+
+	_, err = New(&config.NotifierConfig{Driver: "pigeon"})
+	assert.ErrorIs(t, err, ErrUnknownDriver)
+}
+
+func TestFileNotifier_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "notifications.log")
+	n, err := NewFileNotifier(path)
+	require.NoError(t, err)
+
+	require.NoError(t, n.Send(context.Background(), Message{UserID: "user123", Subject: "PIN reset", Body: "first"}))
+	require.NoError(t, n.Send(context.Background(), Message{UserID: "user123", Subject: "PIN reset", Body: "second"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry fileEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "user123", entry.UserID)
+	assert.Equal(t, "second", entry.Body)
+	assert.False(t, entry.SentAt.IsZero())
+}
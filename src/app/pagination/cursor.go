@@ -0,0 +1,162 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position handed to clients as an opaque, signed token.
+// Sort and Order pin the cursor to the ordering it was issued for, so a
+// cursor cannot be replayed against a different sort.
+type Cursor struct {
+	Sort     string `json:"s,omitempty"`
+	Order    string `json:"o"`
+	Value    string `json:"v,omitempty"`
+	ID       string `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// Encode serialises the cursor as base64(payload).base64(hmac)
+func Encode(cursor Cursor, secret string) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(encoded, secret), nil
+}
+
+// Decode verifies the signature and returns the cursor it carries
+func Decode(token, secret string) (*Cursor, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || encoded == "" || signature == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(encoded, secret))) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == "" || (cursor.Order != "asc" && cursor.Order != "desc") {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func sign(encoded, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ApplyKeyset adds the seek predicate and ordering for a keyset page.
+// column is the sort column and idColumn the unique tie-breaker; when they
+// are the same column only the id is compared. Backward cursors invert the
+// scan direction, callers must reverse the fetched rows afterwards.
+func ApplyKeyset(query *gorm.DB, column, idColumn, order string, cursor *Cursor) *gorm.DB {
+	descending := order == "desc"
+	if cursor != nil && cursor.Backward {
+		descending = !descending
+	}
+
+	op, direction := ">", "ASC"
+	if descending {
+		op, direction = "<", "DESC"
+	}
+
+	if cursor != nil {
+		if column == idColumn {
+			query = query.Where(fmt.Sprintf("%s %s ?", idColumn, op), cursor.ID)
+		} else {
+			query = query.Where(
+				fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", column, op, column, idColumn, op),
+				cursor.Value, cursor.Value, cursor.ID,
+			)
+		}
+	}
+
+	if column == idColumn {
+		return query.Order(fmt.Sprintf("%s %s", idColumn, direction))
+	}
+	return query.Order(fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction))
+}
+
+// DecodeParams returns the cursor carried by params, or nil for the first
+// page. The cursor must have been issued for the same sort and order.
+func DecodeParams(params entities.PaginationParams, secret string) (*Cursor, error) {
+	if params.Cursor == "" {
+		return nil, nil
+	}
+
+	cursor, err := Decode(params.Cursor, secret)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != params.Sort || cursor.Order != params.Order {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// NewCursorMeta builds the meta block for a keyset page. first and last are
+// positioned on the first and last returned rows, nil when the page is empty.
+func NewCursorMeta(limit int, current *Cursor, hasMore bool, first, last *Cursor, secret string) (entities.PaginationMeta, error) {
+	meta := entities.PaginationMeta{PerPage: limit}
+
+	backward := current != nil && current.Backward
+	if backward {
+		meta.HasPrevious = hasMore
+		meta.HasNext = true
+	} else {
+		meta.HasNext = hasMore
+		meta.HasPrevious = current != nil
+	}
+
+	if first == nil || last == nil {
+		return meta, nil
+	}
+
+	if meta.HasNext {
+		next := *last
+		next.Backward = false
+		token, err := Encode(next, secret)
+		if err != nil {
+			return entities.PaginationMeta{}, err
+		}
+		meta.NextCursor = token
+	}
+
+	if meta.HasPrevious {
+		prev := *first
+		prev.Backward = true
+		token, err := Encode(prev, secret)
+		if err != nil {
+			return entities.PaginationMeta{}, err
+		}
+		meta.PrevCursor = token
+	}
+
+	return meta, nil
+}
@@ -0,0 +1,179 @@
+package pagination
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const testSecret = "test-cursor-secret"
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	original := Cursor{Sort: "name", Order: "desc", Value: "Coffee", ID: "txn5", Backward: true}
+
+	token, err := Encode(original, testSecret)
+	require.NoError(t, err)
+
+	decoded, err := Decode(token, testSecret)
+	require.NoError(t, err)
+	assert.Equal(t, original, *decoded)
+}
+
+func TestCursor_DecodeRejectsInvalidTokens(t *testing.T) {
+	valid, err := Encode(Cursor{Order: "asc", ID: "txn1"}, testSecret)
+	require.NoError(t, err)
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	forged, err := Encode(Cursor{Order: "asc", ID: "txn999"}, "other-secret")
+	require.NoError(t, err)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "missing signature", token: payload},
+		{name: "wrong secret", token: forged},
+		{name: "tampered payload", token: forgedPayload + "." + signature},
+		{name: "garbage", token: "not-a-cursor.at-all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := Decode(tt.token, testSecret)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			assert.Nil(t, cursor)
+		})
+	}
+}
+
+func TestDecodeParams(t *testing.T) {
+	token, err := Encode(Cursor{Sort: "name", Order: "asc", Value: "A", ID: "txn1"}, testSecret)
+	require.NoError(t, err)
+
+	t.Run("no cursor is the first page", func(t *testing.T) {
+		cursor, err := DecodeParams(entities.PaginationParams{Order: "asc"}, testSecret)
+		assert.NoError(t, err)
+		assert.Nil(t, cursor)
+	})
+
+	t.Run("matching sort and order", func(t *testing.T) {
+		cursor, err := DecodeParams(entities.PaginationParams{Cursor: token, Sort: "name", Order: "asc"}, testSecret)
+		assert.NoError(t, err)
+		assert.Equal(t, "txn1", cursor.ID)
+	})
+
+	t.Run("cursor issued for another order", func(t *testing.T) {
+		cursor, err := DecodeParams(entities.PaginationParams{Cursor: token, Sort: "name", Order: "desc"}, testSecret)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, cursor)
+	})
+}
+
+func TestNewCursorMeta(t *testing.T) {
+	first := &Cursor{Order: "asc", ID: "txn1"}
+	last := &Cursor{Order: "asc", ID: "txn3"}
+
+	t.Run("first page with more rows", func(t *testing.T) {
+		meta, err := NewCursorMeta(3, nil, true, first, last, testSecret)
+		require.NoError(t, err)
+		assert.True(t, meta.HasNext)
+		assert.False(t, meta.HasPrevious)
+		assert.Empty(t, meta.PrevCursor)
+
+		next, err := Decode(meta.NextCursor, testSecret)
+		require.NoError(t, err)
+		assert.Equal(t, "txn3", next.ID)
+		assert.False(t, next.Backward)
+	})
+
+	t.Run("last page reached going forward", func(t *testing.T) {
+		meta, err := NewCursorMeta(3, &Cursor{Order: "asc", ID: "txn0"}, false, first, last, testSecret)
+		require.NoError(t, err)
+		assert.False(t, meta.HasNext)
+		assert.True(t, meta.HasPrevious)
+
+		prev, err := Decode(meta.PrevCursor, testSecret)
+		require.NoError(t, err)
+		assert.Equal(t, "txn1", prev.ID)
+		assert.True(t, prev.Backward)
+	})
+
+	t.Run("backward page at the start", func(t *testing.T) {
+		meta, err := NewCursorMeta(3, &Cursor{Order: "asc", ID: "txn4", Backward: true}, false, first, last, testSecret)
+		require.NoError(t, err)
+		assert.True(t, meta.HasNext)
+		assert.False(t, meta.HasPrevious)
+		assert.NotEmpty(t, meta.NextCursor)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		meta, err := NewCursorMeta(3, nil, false, nil, nil, testSecret)
+		require.NoError(t, err)
+		assert.Equal(t, entities.PaginationMeta{PerPage: 3}, meta)
+	})
+}
+
+func TestApplyKeyset(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		order  string
+		cursor *Cursor
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name:   "first page by id",
+			column: "id",
+			order:  "asc",
+			query:  "SELECT \\* FROM `items` ORDER BY id ASC",
+		},
+		{
+			name:   "forward by id descending",
+			column: "id",
+			order:  "desc",
+			cursor: &Cursor{ID: "10"},
+			query:  "SELECT \\* FROM `items` WHERE id < \\? ORDER BY id DESC",
+			args:   []driver.Value{"10"},
+		},
+		{
+			name:   "backward by name ascending",
+			column: "name",
+			order:  "asc",
+			cursor: &Cursor{Value: "Bob", ID: "7", Backward: true},
+			query:  "SELECT \\* FROM `items` WHERE \\(name < \\? OR \\(name = \\? AND id < \\?\\)\\) ORDER BY name DESC, id DESC",
+			args:   []driver.Value{"Bob", "Bob", "7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			gormDB, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      db,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{})
+			require.NoError(t, err)
+
+			mock.ExpectQuery(tt.query).WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			var rows []map[string]interface{}
+			query := ApplyKeyset(gormDB.Table("items"), tt.column, "id", tt.order, tt.cursor)
+			assert.NoError(t, query.Find(&rows).Error)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
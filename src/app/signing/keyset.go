@@ -0,0 +1,191 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey         = errors.New("signing: unknown key")
+	ErrUnsupportedKey     = errors.New("signing: unsupported key type")
+	ErrActiveKeyNotSigner = errors.New("signing: active key has no private key")
+	ErrDevKey             = errors.New("signing: development key used outside development")
+)
+
+// DevKeyPrefix marks the kid of keys generated for local development. They
+// are refused in every other environment.
+const DevKeyPrefix = "dev-"
+
+// Key is one signing key. Retired keys may come without a private key; they
+// only verify tokens issued before the rotation.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds the keys access tokens are signed and verified with. New
+// tokens are signed with the active key; every key in the set verifies.
+type KeySet struct {
+	activeID string
+	keys     map[string]*Key
+}
+
+func NewKeySet(activeID string, keys []*Key) (*KeySet, error) {
+	set := &KeySet{activeID: activeID, keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		set.keys[key.ID] = key
+	}
+
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, activeID)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: %s", ErrActiveKeyNotSigner, activeID)
+	}
+	return set, nil
+}
+
+// Active returns the key new tokens are signed with.
+func (s *KeySet) Active() *Key {
+	return s.keys[s.activeID]
+}
+
+// Key returns the key with the given kid.
+func (s *KeySet) Key(id string) (*Key, bool) {
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+// LoadKeyDir reads every <kid>.pem file in dir. A file holds either a private
+// key (PKCS#8, PKCS#1 or SEC 1) or, for retired keys, only the public key.
+//
+// The algorithm follows from the key: RSA keys sign with RS256, P-256 keys
+// with ES256 and Ed25519 keys with EdDSA.
+func LoadKeyDir(dir, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("signing: failed to list key files: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("signing: failed to read key file: %w", err)
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := ParseKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(activeID, keys)
+}
+
+// ParseKey decodes a PEM encoded private or public key.
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing: no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: PEM type %s", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: failed to parse key: %w", err)
+	}
+
+	key := &Key{ID: id}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	} else {
+		key.PublicKey = parsed
+	}
+
+	key.Method, err = methodFor(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func methodFor(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ES256 needs a P-256 key", ErrUnsupportedKey)
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, publicKey)
+}
+
+var defaultKeySet atomic.Pointer[KeySet]
+
+// SetDefault installs the key set used by the JWT service.
+func SetDefault(s *KeySet) {
+	defaultKeySet.Store(s)
+}
+
+// Default returns the installed key set, or nil when access tokens are signed
+// with the HS256 secret.
+func Default() *KeySet {
+	return defaultKeySet.Load()
+}
+
+// Setup loads the keys from Auth.Jwt.KeyDir and installs them as the default.
+// Without a key directory access tokens keep using the HS256 secret. Outside
+// the development environment an active key named dev-* is refused.
+func Setup(cfg *config.JwtConfig, environment string) (*KeySet, error) {
+	if cfg == nil || cfg.KeyDir == "" {
+		SetDefault(nil)
+		return nil, nil
+	}
+	if environment != "development" && strings.HasPrefix(cfg.ActiveKeyID, DevKeyPrefix) {
+		return nil, fmt.Errorf("%w: %s in %s", ErrDevKey, cfg.ActiveKeyID, environment)
+	}
+
+	set, err := LoadKeyDir(cfg.KeyDir, cfg.ActiveKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	SetDefault(set)
+	return set, nil
+}
@@ -0,0 +1,159 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func privatePEM(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestParseKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		method    jwt.SigningMethod
+		canSign   bool
+		wantErr   bool
+		expectErr error
+	}{
+		{name: "rsa pkcs8", data: privatePEM(t, rsaKey), method: jwt.SigningMethodRS256, canSign: true},
+		{name: "rsa pkcs1", data: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), method: jwt.SigningMethodRS256, canSign: true},
+		{name: "ec sec1", data: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}), method: jwt.SigningMethodES256, canSign: true},
+		{name: "ed25519", data: privatePEM(t, edKey), method: jwt.SigningMethodEdDSA, canSign: true},
+		{name: "retired public key", data: publicPEM(t, ecKey.Public()), method: jwt.SigningMethodES256},
+		{name: "p-384 is not ES256", data: privatePEM(t, p384Key), wantErr: true, expectErr: ErrUnsupportedKey},
+		{name: "certificate", data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), wantErr: true, expectErr: ErrUnsupportedKey},
+		{name: "not pem", data: []byte("secret"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey("k1", tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.expectErr != nil {
+					assert.ErrorIs(t, err, tt.expectErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.method, key.Method)
+			assert.Equal(t, tt.canSign, key.PrivateKey != nil)
+			assert.NotNil(t, key.PublicKey)
+		})
+	}
+}
+
+func TestLoadKeyDir(t *testing.T) {
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key-2.pem"), privatePEM(t, newKey), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key-1.pem"), publicPEM(t, oldKey.Public()), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0o600))
+
+	set, err := LoadKeyDir(dir, "key-2")
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", set.Active().ID)
+	retired, ok := set.Key("key-1")
+	assert.True(t, ok)
+	assert.Nil(t, retired.PrivateKey)
+
+	// a retired key cannot sign
+	_, err = LoadKeyDir(dir, "key-1")
+	assert.ErrorIs(t, err, ErrActiveKeyNotSigner)
+
+	_, err = LoadKeyDir(dir, "key-3")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestSetup(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev-1.pem"), privatePEM(t, key), 0o600))
+	defer SetDefault(nil)
+
+	set, err := Setup(&config.JwtConfig{KeyDir: dir, ActiveKeyID: "dev-1"}, "development")
+	require.NoError(t, err)
+	assert.Same(t, set, Default())
+
+	// a development key must never sign production tokens
+	_, err = Setup(&config.JwtConfig{KeyDir: dir, ActiveKeyID: "dev-1"}, "production")
+	assert.ErrorIs(t, err, ErrDevKey)
+
+	set, err = Setup(&config.JwtConfig{}, "production")
+	assert.NoError(t, err)
+	assert.Nil(t, set)
+	assert.Nil(t, Default())
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := make([]*Key, 0, 3)
+	for id, signer := range map[string]crypto.Signer{"a-rsa": rsaKey, "b-ec": ecKey, "c-ed": edKey} {
+		key, err := ParseKey(id, privatePEM(t, signer))
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	set, err := NewKeySet("c-ed", keys)
+	require.NoError(t, err)
+
+	jwks := set.JWKS()
+
+	require.Len(t, jwks.Keys, 3)
+	assert.Equal(t, JWK{Kty: "RSA", Kid: "a-rsa", Use: "sig", Alg: "RS256", N: encode(rsaKey.N.Bytes()), E: "AQAB"}, jwks.Keys[0])
+	assert.Equal(t, "EC", jwks.Keys[1].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+	assert.Equal(t, "ES256", jwks.Keys[1].Alg)
+	assert.Len(t, jwks.Keys[1].X, 43) // 32 bytes, unpadded base64url
+	assert.Len(t, jwks.Keys[1].Y, 43)
+	assert.Equal(t, JWK{Kty: "OKP", Kid: "c-ed", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: encode(edPublic)}, jwks.Keys[2])
+
+	var empty *KeySet
+	assert.Equal(t, JWKS{Keys: []JWK{}}, empty.JWKS())
+}
@@ -0,0 +1,58 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+)
+
+var csvHeader = []string{"date", "entry_id", "type", "description", "amount", "balance", "currency"}
+
+type csvWriter struct {
+	balance
+	w        *csv.Writer
+	currency string
+	to       string
+}
+
+func newCSVWriter(w io.Writer, statement entities.Statement) (*csvWriter, error) {
+	c := &csvWriter{
+		balance:  balance{closing: statement.OpeningBalance},
+		w:        csv.NewWriter(w),
+		currency: statement.Currency,
+		to:       statement.To.Format(time.DateOnly),
+	}
+	if err := c.w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	if err := c.w.Write([]string{
+		statement.From.Format(time.DateOnly), "", "", "Opening balance", "", statement.OpeningBalance.String(), c.currency,
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) WriteEntry(entry entities.StatementEntry) error {
+	c.track(entry)
+	return c.w.Write([]string{
+		entry.Date.UTC().Format(time.DateOnly),
+		strconv.FormatUint(entry.EntryID, 10),
+		entry.Type,
+		entry.Description,
+		entry.Amount.String(),
+		entry.Balance.String(),
+		c.currency,
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.w.Write([]string{c.to, "", "", "Closing balance", "", c.closing.String(), c.currency}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
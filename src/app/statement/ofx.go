@@ -0,0 +1,111 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/mask"
+	"github.com/Testzyler/banking-api/app/money"
+)
+
+const ofxTimeLayout = "20060102150405"
+
+// ofxWriter writes an OFX 2.2 bank statement. OFX has no opening balance
+// element, so it is reported in BALLIST next to the closing LEDGERBAL.
+type ofxWriter struct {
+	balance
+	w         *bufio.Writer
+	statement entities.Statement
+}
+
+func newOFXWriter(w io.Writer, statement entities.Statement) (*ofxWriter, error) {
+	o := &ofxWriter{
+		balance:   balance{closing: statement.OpeningBalance},
+		w:         bufio.NewWriter(w),
+		statement: statement,
+	}
+
+	generatedAt := statement.GeneratedAt.UTC().Format(ofxTimeLayout)
+	o.printf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	o.printf(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	o.printf("<OFX>\n")
+	o.printf("<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+	o.printf("<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", generatedAt)
+	o.printf("<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	o.printf("<STMTRS><CURDEF>%s</CURDEF>\n", escapeXML(statement.Currency))
+	// the statement route has no step-up, so ACCTID only carries the last digits
+	o.printf("<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>\n",
+		escapeXML(statement.Issuer), escapeXML(mask.Number(statement.AccountNumber)), ofxAccountType(statement.AccountType))
+	o.printf("<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n",
+		statement.From.Format(ofxTimeLayout), statement.To.AddDate(0, 0, 1).Format(ofxTimeLayout))
+
+	if err := o.w.Flush(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *ofxWriter) printf(format string, args ...interface{}) {
+	// bufio.Writer keeps the first error and reports it on Flush
+	fmt.Fprintf(o.w, format, args...)
+}
+
+func (o *ofxWriter) WriteEntry(entry entities.StatementEntry) error {
+	o.track(entry)
+
+	trnType := "CREDIT"
+	if entry.Amount < 0 {
+		trnType = "DEBIT"
+	}
+	o.printf("<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%d</FITID><NAME>%s</NAME></STMTTRN>\n",
+		trnType, entry.Date.UTC().Format(ofxTimeLayout), entry.Amount, entry.EntryID, escapeXML(truncate(entry.Description, 32)))
+	return nil
+}
+
+func (o *ofxWriter) Close() error {
+	asOf := o.statement.To.AddDate(0, 0, 1).Format(ofxTimeLayout)
+	o.printf("</BANKTRANLIST>\n")
+	o.printf("<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n", o.closing, asOf)
+	o.printf("<BALLIST>%s%s</BALLIST>\n",
+		ofxBalance("Opening balance", o.statement.OpeningBalance, o.statement.From.Format(ofxTimeLayout)),
+		ofxBalance("Closing balance", o.closing, asOf))
+	o.printf("</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n")
+	o.printf("</OFX>\n")
+	return o.w.Flush()
+}
+
+func ofxBalance(name string, amount money.Amount, asOf string) string {
+	return fmt.Sprintf("<BAL><NAME>%s</NAME><DESC>%s</DESC><BALTYPE>DOLLAR</BALTYPE><VALUE>%s</VALUE><DTASOF>%s</DTASOF></BAL>",
+		name, name, amount, asOf)
+}
+
+func ofxAccountType(accountType string) string {
+	accountType = strings.ToLower(accountType)
+	switch {
+	case strings.Contains(accountType, "saving"):
+		return "SAVINGS"
+	case strings.Contains(accountType, "credit"), strings.Contains(accountType, "loan"):
+		return "CREDITLINE"
+	default:
+		return "CHECKING"
+	}
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, OFX limits NAME to 32 characters.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,195 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/mask"
+)
+
+// A4 in points, set in 9pt Courier so columns line up without font metrics.
+const (
+	pdfPageWidth   = 595
+	pdfPageHeight  = 842
+	pdfMargin      = 50
+	pdfFontSize    = 9
+	pdfLeading     = 12
+	pdfLinesOnPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+
+	pdfCatalogObject = 1
+	pdfPagesObject   = 2
+	pdfFontObject    = 3
+
+	pdfDescriptionWidth = 38
+	pdfRowFormat        = "%-10s  %-38s  %16s  %16s"
+)
+
+// pdfWriter writes a minimal PDF one page at a time. Only the current page
+// and the object offsets are kept in memory; the page tree and
+// cross-reference table are written by Close.
+type pdfWriter struct {
+	balance
+	w         *countingWriter
+	statement entities.Statement
+	offsets   map[int]int64
+	nextID    int
+	pages     []int
+	lines     []string
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newPDFWriter(w io.Writer, statement entities.Statement) (*pdfWriter, error) {
+	p := &pdfWriter{
+		balance:   balance{closing: statement.OpeningBalance},
+		w:         &countingWriter{w: w},
+		statement: statement,
+		offsets:   map[int]int64{},
+		nextID:    pdfFontObject + 1,
+	}
+
+	if _, err := io.WriteString(p.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+	if err := p.writeObject(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject)); err != nil {
+		return nil, err
+	}
+	if err := p.writeObject(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>"); err != nil {
+		return nil, err
+	}
+
+	p.lines = append(p.lines,
+		"Account Statement",
+		"",
+		fmt.Sprintf("Account:  %s (%s)", mask.Number(statement.AccountNumber), statement.AccountType),
+		fmt.Sprintf("Period:   %s to %s", statement.From.Format(time.DateOnly), statement.To.Format(time.DateOnly)),
+		fmt.Sprintf("Currency: %s", statement.Currency),
+		fmt.Sprintf("Opening balance: %s", statement.OpeningBalance),
+		"",
+	)
+	p.addColumnHeader()
+	return p, nil
+}
+
+func (p *pdfWriter) addColumnHeader() {
+	p.lines = append(p.lines,
+		fmt.Sprintf(pdfRowFormat, "Date", "Description", "Amount", "Balance"),
+		strings.Repeat("-", 10+2+pdfDescriptionWidth+2+16+2+16),
+	)
+}
+
+func (p *pdfWriter) WriteEntry(entry entities.StatementEntry) error {
+	p.track(entry)
+	p.lines = append(p.lines, fmt.Sprintf(pdfRowFormat,
+		entry.Date.UTC().Format(time.DateOnly),
+		truncate(entry.Description, pdfDescriptionWidth),
+		entry.Amount,
+		entry.Balance,
+	))
+
+	if len(p.lines) >= pdfLinesOnPage {
+		if err := p.flushPage(); err != nil {
+			return err
+		}
+		p.addColumnHeader()
+	}
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	p.lines = append(p.lines,
+		"",
+		fmt.Sprintf("Closing balance: %s", p.closing),
+		fmt.Sprintf("Generated %s", p.statement.GeneratedAt.UTC().Format(time.RFC3339)),
+	)
+	if err := p.flushPage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, 0, len(p.pages))
+	for _, id := range p.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", id))
+	}
+	if err := p.writeObject(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(p.pages))); err != nil {
+		return err
+	}
+
+	xrefOffset := p.w.n
+	var xref bytes.Buffer
+	fmt.Fprintf(&xref, "xref\n0 %d\n0000000000 65535 f \n", p.nextID)
+	for id := 1; id < p.nextID; id++ {
+		fmt.Fprintf(&xref, "%010d 00000 n \n", p.offsets[id])
+	}
+	fmt.Fprintf(&xref, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextID, pdfCatalogObject, xrefOffset)
+	_, err := p.w.Write(xref.Bytes())
+	return err
+}
+
+// flushPage writes the buffered lines as one page with its content stream.
+func (p *pdfWriter) flushPage() error {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range p.lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDF(line))
+	}
+	content.WriteString("ET\n")
+	p.lines = p.lines[:0]
+
+	contentID := p.allocate()
+	if err := p.writeObject(contentID, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String())); err != nil {
+		return err
+	}
+
+	pageID := p.allocate()
+	p.pages = append(p.pages, pageID)
+	return p.writeObject(pageID, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, contentID,
+	))
+}
+
+func (p *pdfWriter) allocate() int {
+	id := p.nextID
+	p.nextID++
+	return id
+}
+
+func (p *pdfWriter) writeObject(id int, body string) error {
+	p.offsets[id] = p.w.n
+	_, err := fmt.Fprintf(p.w, "%d 0 obj\n%s\nendobj\n", id, body)
+	return err
+}
+
+// escapePDF escapes a string literal. The standard fonts only cover Latin-1,
+// so other characters are replaced with '?'.
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/money"
+)
+
+var ErrUnsupportedFormat = errors.New("statement: unsupported format")
+
+// Writer renders a statement entry by entry so a whole period never has to be
+// held in memory. Close writes the closing balance and any trailer.
+type Writer interface {
+	WriteEntry(entry entities.StatementEntry) error
+	Close() error
+}
+
+// NewWriter writes the statement header to w and returns a Writer for the
+// requested format.
+func NewWriter(format string, w io.Writer, statement entities.Statement) (Writer, error) {
+	switch format {
+	case entities.StatementFormatCSV:
+		return newCSVWriter(w, statement)
+	case entities.StatementFormatOFX:
+		return newOFXWriter(w, statement)
+	case entities.StatementFormatPDF:
+		return newPDFWriter(w, statement)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case entities.StatementFormatOFX:
+		return "application/x-ofx"
+	case entities.StatementFormatPDF:
+		return "application/pdf"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileName is the attachment name offered to the client, e.g.
+// statement-acc1-2025-07-01-2025-07-31.csv.
+func FileName(statement entities.Statement, format string) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		statement.AccountID,
+		statement.From.Format(time.DateOnly),
+		statement.To.Format(time.DateOnly),
+		format,
+	)
+}
+
+// balance tracks the running balance so the closing balance always matches
+// the last entry written, even if new entries arrive while streaming.
+type balance struct {
+	closing money.Amount
+}
+
+func (b *balance) track(entry entities.StatementEntry) {
+	b.closing = entry.Balance
+}
@@ -0,0 +1,128 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() entities.Statement {
+	return entities.Statement{
+		AccountID:      "acc1",
+		AccountNumber:  "123456789012",
+		AccountType:    "saving-account",
+		Issuer:         "TestBank",
+		Currency:       "THB",
+		From:           time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 150000,
+		GeneratedAt:    time.Date(2025, 8, 1, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func testEntries() []entities.StatementEntry {
+	return []entities.StatementEntry{
+		{EntryID: 7, Date: time.Date(2025, 7, 3, 9, 0, 0, 0, time.UTC), Type: "transfer", Description: "Transfer to acc2 - rent, July", Amount: -2550, Balance: 147450},
+		{EntryID: 9, Date: time.Date(2025, 7, 5, 9, 0, 0, 0, time.UTC), Type: "transfer", Description: "Transfer from acc3 <savings>", Amount: 1000, Balance: 148450},
+	}
+}
+
+func render(t *testing.T, format string, stmt entities.Statement, entries []entities.StatementEntry) string {
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf, stmt)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, writer.WriteEntry(entry))
+	}
+	require.NoError(t, writer.Close())
+	return buf.String()
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &bytes.Buffer{}, testStatement())
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := render(t, entities.StatementFormatCSV, testStatement(), testEntries())
+
+	assert.Equal(t, strings.Join([]string{
+		"date,entry_id,type,description,amount,balance,currency",
+		"2025-07-01,,,Opening balance,,1500.00,THB",
+		`2025-07-03,7,transfer,"Transfer to acc2 - rent, July",-25.50,1474.50,THB`,
+		"2025-07-05,9,transfer,Transfer from acc3 <savings>,10.00,1484.50,THB",
+		"2025-07-31,,,Closing balance,,1484.50,THB",
+		"",
+	}, "\n"), out)
+}
+
+func TestCSVWriter_NoEntries(t *testing.T) {
+	out := render(t, entities.StatementFormatCSV, testStatement(), nil)
+
+	assert.Contains(t, out, "2025-07-31,,,Closing balance,,1500.00,THB")
+}
+
+func TestOFXWriter(t *testing.T) {
+	out := render(t, entities.StatementFormatOFX, testStatement(), testEntries())
+
+	assert.True(t, strings.HasPrefix(out, `<?xml version="1.0"`))
+	assert.Contains(t, out, "<CURDEF>THB</CURDEF>")
+	assert.Contains(t, out, "<ACCTID>********9012</ACCTID><ACCTTYPE>SAVINGS</ACCTTYPE>")
+	assert.Contains(t, out, "<DTSTART>20250701000000</DTSTART><DTEND>20250801000000</DTEND>")
+	assert.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250703090000</DTPOSTED><TRNAMT>-25.50</TRNAMT><FITID>7</FITID>")
+	assert.Contains(t, out, "<NAME>Transfer from acc3 &lt;savings&gt;</NAME>")
+	assert.Contains(t, out, "<LEDGERBAL><BALAMT>1484.50</BALAMT>")
+	assert.Contains(t, out, "<NAME>Opening balance</NAME><DESC>Opening balance</DESC><BALTYPE>DOLLAR</BALTYPE><VALUE>1500.00</VALUE>")
+	assert.True(t, strings.HasSuffix(out, "</OFX>\n"))
+}
+
+func TestPDFWriter(t *testing.T) {
+	out := render(t, entities.StatementFormatPDF, testStatement(), testEntries())
+
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "(Account:  ********9012 \\(saving-account\\)) Tj")
+	assert.NotContains(t, out, "123456789012")
+	assert.Contains(t, out, "(Opening balance: 1500.00) Tj")
+	assert.Contains(t, out, "(Closing balance: 1484.50) Tj")
+	assert.Contains(t, out, "/Count 1")
+	assertValidXref(t, out)
+}
+
+func TestPDFWriter_MultiplePages(t *testing.T) {
+	var entries []entities.StatementEntry
+	for i := 1; i <= 150; i++ {
+		entries = append(entries, entities.StatementEntry{EntryID: uint64(i), Description: "Transfer (test)", Amount: 100, Balance: 150000})
+	}
+
+	out := render(t, entities.StatementFormatPDF, testStatement(), entries)
+
+	assert.Contains(t, out, "/Count 3")
+	assert.Contains(t, out, `Transfer \(test\)`)
+	assertValidXref(t, out)
+}
+
+// assertValidXref checks every xref offset points at its object.
+func assertValidXref(t *testing.T, out string) {
+	start := strings.LastIndex(out, "startxref\n")
+	require.NotEqual(t, -1, start)
+	xrefOffset, err := strconv.Atoi(strings.Fields(out[start+len("startxref\n"):])[0])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out[xrefOffset:], "xref\n"))
+
+	entry := regexp.MustCompile(`(\d{10}) 00000 n `)
+	matches := entry.FindAllStringSubmatch(out[xrefOffset:], -1)
+	require.NotEmpty(t, matches)
+	for i, match := range matches {
+		offset, _ := strconv.Atoi(match[1])
+		assert.True(t, strings.HasPrefix(out[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+}
@@ -37,6 +37,12 @@ func ValidateStruct(s interface{}) error {
 				message = fmt.Sprintf("%s must be exactly %s characters", getFieldName(err.Field()), err.Param())
 			case "numeric":
 				message = fmt.Sprintf("%s must be a number", getFieldName(err.Field()))
+			case "gt":
+				message = fmt.Sprintf("%s must be greater than %s", getFieldName(err.Field()), err.Param())
+			case "oneof":
+				message = fmt.Sprintf("%s must be one of: %s", getFieldName(err.Field()), err.Param())
+			case "datetime":
+				message = fmt.Sprintf("%s must be a date in %s format", getFieldName(err.Field()), err.Param())
 			// Custom validation error messages
 			case "account_number":
 				message = fmt.Sprintf("%s must be exactly 12 digits", getFieldName(err.Field()))
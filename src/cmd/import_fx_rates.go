@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	fxRepository "github.com/Testzyler/banking-api/app/features/fx/repository"
+	fxService "github.com/Testzyler/banking-api/app/features/fx/service"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/spf13/cobra"
+)
+
+var fxRatesFile string
+
+// importFxRatesCmd loads exchange rates from a CSV file into fx_rates
+var importFxRatesCmd = &cobra.Command{
+	Use:   "import_fx_rates",
+	Short: "Import FX rates from a CSV file",
+	Long:  "This command upserts exchange rates from a CSV file with the header base,quote,rate,as_of into the fx_rates table.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration
+		config := config.NewConfig(configFile)
+
+		file, err := os.Open(fxRatesFile)
+		if err != nil {
+			return fmt.Errorf("failed to open rates file: %w", err)
+		}
+		defer file.Close()
+
+		// Initialize database connection
+		db, err := database.NewDatabase(config)
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
+		}
+		defer db.Close()
+
+		repo := fxRepository.NewFxRepository(db.GetDB())
+		service := fxService.NewFxService(repo, fxService.NewStoredRateProvider(repo))
+
+		fmt.Printf("Importing FX rates from %s...\n", fxRatesFile)
+		count, err := service.ImportRatesCSV(file)
+		if err != nil {
+			return fmt.Errorf("import failed: %w", err)
+		}
+
+		fmt.Printf("Imported %d FX rates successfully.\n", count)
+		return nil
+	},
+}
+
+func init() {
+	importFxRatesCmd.Flags().StringVar(&fxRatesFile, "file", "fx_rates.csv", "CSV file with base,quote,rate,as_of rows")
+	cmd.AddCommand(importFxRatesCmd)
+}
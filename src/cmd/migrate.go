@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/Testzyler/banking-api/app/encryption"
 	"github.com/Testzyler/banking-api/config"
 	"github.com/Testzyler/banking-api/database"
 	"github.com/spf13/cobra"
@@ -24,6 +25,11 @@ var migrateCmd = &cobra.Command{
 		}
 		defer db.Close()
 
+		// Migrations encrypt card and account numbers with the data key
+		if _, err := encryption.Setup(db.GetDB(), config.Encryption, config.Server.Environment); err != nil {
+			return fmt.Errorf("failed to set up encryption: %w", err)
+		}
+
 		// Run migrations
 		fmt.Println("Starting database migrations...")
 		if err := db.RunMigrations(); err != nil {
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/spf13/cobra"
+)
+
+var skipNewDataKey bool
+
+// rotateDataKeyCmd issues a new data key and re-encrypts every encrypted column with it
+var rotateDataKeyCmd = &cobra.Command{
+	Use:   "rotate_data_key",
+	Short: "Rotate the data key used for field encryption",
+	Long: "This command rewraps stored data keys with the active master key, creates a new active data key " +
+		"and re-encrypts card and account numbers with it in batches. It can be rerun safely if interrupted.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration
+		config := config.NewConfig(configFile)
+
+		// Initialize database connection
+		db, err := database.NewDatabase(config)
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
+		}
+		defer db.Close()
+
+		kms, indexKey, err := encryption.OpenKeyFile(config.Encryption, config.Server.Environment)
+		if err != nil {
+			return err
+		}
+
+		// a master key rotation only takes effect once existing data keys are rewrapped
+		rewrapped, err := encryption.RewrapDataKeys(db.GetDB(), kms)
+		if err != nil {
+			return fmt.Errorf("rewrap failed after %d keys: %w", rewrapped, err)
+		}
+		fmt.Printf("Rewrapped %d data keys with master key %s.\n", rewrapped, kms.ActiveKeyID())
+
+		if !skipNewDataKey {
+			dataKey, err := encryption.RotateDataKey(db.GetDB(), kms)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created data key %s.\n", dataKey.KeyID)
+		}
+
+		keyring, err := encryption.LoadKeyring(db.GetDB(), kms, indexKey)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range models.EncryptedColumns {
+			count, err := encryption.EncryptColumn(db.GetDB(), keyring, column, config.Encryption.BatchSize)
+			if err != nil {
+				return fmt.Errorf("re-encryption failed after %d rows: %w", count, err)
+			}
+			fmt.Printf("Re-encrypted %d rows of %s.%s.\n", count, column.Table, column.Name)
+		}
+
+		fmt.Println("Data key rotation completed successfully. Restart API instances so new writes use the new key.")
+		return nil
+	},
+}
+
+func init() {
+	rotateDataKeyCmd.Flags().BoolVar(&skipNewDataKey, "rewrap-only", false, "Only rewrap data keys and finish re-encryption, without creating a new data key")
+	cmd.AddCommand(rotateDataKeyCmd)
+}
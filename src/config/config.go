@@ -10,11 +10,17 @@ import (
 )
 
 type Config struct {
-	Server   *Server
-	Database *Database
-	Cache    *CacheConfig
-	Logger   *Logger
-	Auth     *AuthConfig
+	Server      *Server
+	Database    *Database
+	Cache       *CacheConfig
+	Logger      *Logger
+	Auth        *AuthConfig
+	Pagination  *PaginationConfig
+	Idempotency *IdempotencyConfig
+	FX          *FxConfig
+	Encryption  *EncryptionConfig
+	Notifier    *NotifierConfig
+	RateLimit   *RateLimitConfig
 }
 
 type Server struct {
@@ -62,21 +68,89 @@ type Logger struct {
 }
 
 type AuthConfig struct {
-	Jwt *JwtConfig
-	Pin *PinConfig
+	Jwt           *JwtConfig
+	Pin           *PinConfig
+	PinReset      *PinResetConfig
+	TwoFactor     *TwoFactorConfig
+	Introspection *IntrospectionConfig
+}
+
+type PaginationConfig struct {
+	CursorSecret string
+}
+
+type IdempotencyConfig struct {
+	LockTimeout time.Duration // how long an in-flight request holds its key
+	ResponseTTL time.Duration // how long a stored response can be replayed
+}
+
+type FxConfig struct {
+	DisplayCurrency string // used when the client does not ask for one
+}
+
+type EncryptionConfig struct {
+	KeyFile   string // JSON file with the master keys and the blind index key
+	BatchSize int    // rows re-encrypted per transaction by migrate and rotate_data_key
 }
 
 type JwtConfig struct {
-	AccessTokenSecret  string
-	RefreshTokenSecret string
-	AccessTokenExpiry  time.Duration
-	RefreshTokenExpiry time.Duration
+	AccessTokenSecret     string
+	RefreshTokenSecret    string
+	AccessTokenExpiry     time.Duration
+	RefreshTokenExpiry    time.Duration
+	RestrictedTokenExpiry time.Duration
+	KeyDir                string // <kid>.pem files for RS256, ES256 or EdDSA access tokens; empty signs with AccessTokenSecret
+	ActiveKeyID           string // kid new access tokens are signed with
 }
 
 type PinConfig struct {
 	BaseDuration    time.Duration
 	LockThreshold   int
 	MaxLockDuration time.Duration
+	HistorySize     int
+	UniformFailure  bool // answer unknown users, wrong and locked PINs on verify-pin with the same error
+}
+
+type PinResetConfig struct {
+	CodeExpiry    time.Duration // how long a reset code stays valid
+	MaxRequests   int           // codes a username can request per RequestWindow
+	RequestWindow time.Duration
+	MaxAttempts   int // wrong codes before the reset is cancelled
+}
+
+type TwoFactorConfig struct {
+	Issuer        string // shown next to the account in authenticator apps
+	MaxAttempts   int    // codes a user can try per AttemptWindow
+	AttemptWindow time.Duration
+}
+
+type IntrospectionConfig struct {
+	Clients map[string]string // client ID to secret, IDs are read lowercase
+}
+
+type NotifierConfig struct {
+	Driver string // log or file
+	File   string
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	Routes  map[string]*RouteRateLimit // keyed by the name routes pass to RateLimitMiddleware, read lowercase
+}
+
+// RouteRateLimit caps requests per client IP, per signed-in user and per
+// presented token. FailedIP only counts responses with status 401 or 404,
+// such as wrong PINs and unknown usernames. A nil limit is not enforced.
+type RouteRateLimit struct {
+	IP       *RateLimit
+	User     *RateLimit
+	Token    *RateLimit
+	FailedIP *RateLimit
+}
+
+type RateLimit struct {
+	Requests int           // requests allowed within Window
+	Window   time.Duration // length of the sliding window
 }
 
 var (
@@ -149,18 +223,67 @@ func loadConfig(envFile string) *Config {
 		},
 		Auth: &AuthConfig{
 			Jwt: &JwtConfig{
-				AccessTokenSecret:  viper.GetString("Auth.Jwt.AccessTokenSecret"),
-				RefreshTokenSecret: viper.GetString("Auth.Jwt.RefreshTokenSecret"),
-				AccessTokenExpiry:  time.Duration(viper.GetInt("Auth.Jwt.AccessTokenExpiry")) * time.Minute,
-				RefreshTokenExpiry: time.Duration(viper.GetInt("Auth.Jwt.RefreshTokenExpiry")) * 24 * time.Hour,
+				AccessTokenSecret:     viper.GetString("Auth.Jwt.AccessTokenSecret"),
+				RefreshTokenSecret:    viper.GetString("Auth.Jwt.RefreshTokenSecret"),
+				AccessTokenExpiry:     time.Duration(viper.GetInt("Auth.Jwt.AccessTokenExpiry")) * time.Minute,
+				RefreshTokenExpiry:    time.Duration(viper.GetInt("Auth.Jwt.RefreshTokenExpiry")) * 24 * time.Hour,
+				RestrictedTokenExpiry: time.Duration(viper.GetInt("Auth.Jwt.RestrictedTokenExpiry")) * time.Minute,
+				KeyDir:                viper.GetString("Auth.Jwt.KeyDir"),
+				ActiveKeyID:           viper.GetString("Auth.Jwt.ActiveKeyID"),
 			},
 			Pin: &PinConfig{
 				BaseDuration:    viper.GetDuration("Auth.Pin.BaseDuration"),
 				LockThreshold:   viper.GetInt("Auth.Pin.LockThreshold"),
 				MaxLockDuration: viper.GetDuration("Auth.Pin.MaxLockDuration"),
+				HistorySize:     viper.GetInt("Auth.Pin.HistorySize"),
+				UniformFailure:  viper.GetBool("Auth.Pin.UniformFailure"),
 			},
+			PinReset: &PinResetConfig{
+				CodeExpiry:    viper.GetDuration("Auth.PinReset.CodeExpiry"),
+				MaxRequests:   viper.GetInt("Auth.PinReset.MaxRequests"),
+				RequestWindow: viper.GetDuration("Auth.PinReset.RequestWindow"),
+				MaxAttempts:   viper.GetInt("Auth.PinReset.MaxAttempts"),
+			},
+			TwoFactor: &TwoFactorConfig{
+				Issuer:        viper.GetString("Auth.TwoFactor.Issuer"),
+				MaxAttempts:   viper.GetInt("Auth.TwoFactor.MaxAttempts"),
+				AttemptWindow: viper.GetDuration("Auth.TwoFactor.AttemptWindow"),
+			},
+			Introspection: &IntrospectionConfig{
+				Clients: viper.GetStringMapString("Auth.Introspection.Clients"),
+			},
+		},
+		Pagination: &PaginationConfig{
+			CursorSecret: viper.GetString("Pagination.CursorSecret"),
 		},
+		Idempotency: &IdempotencyConfig{
+			LockTimeout: viper.GetDuration("Idempotency.LockTimeout"),
+			ResponseTTL: viper.GetDuration("Idempotency.ResponseTTL"),
+		},
+		FX: &FxConfig{
+			DisplayCurrency: viper.GetString("FX.DisplayCurrency"),
+		},
+		Encryption: &EncryptionConfig{
+			KeyFile:   viper.GetString("Encryption.KeyFile"),
+			BatchSize: viper.GetInt("Encryption.BatchSize"),
+		},
+		Notifier: &NotifierConfig{
+			Driver: viper.GetString("Notifier.Driver"),
+			File:   viper.GetString("Notifier.File"),
+		},
+		RateLimit: &RateLimitConfig{
+			Enabled: viper.GetBool("RateLimit.Enabled"),
+			Routes:  loadRateLimitRoutes(),
+		},
+	}
+}
+
+func loadRateLimitRoutes() map[string]*RouteRateLimit {
+	routes := map[string]*RouteRateLimit{}
+	if err := viper.UnmarshalKey("RateLimit.Routes", &routes); err != nil {
+		log.Fatalf("unable to read rate limits: %v\n", err)
 	}
+	return routes
 }
 
 func GetConfig() *Config {
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createLedger = &Migration{
+	Number: 5,
+	Name:   "create transfers and ledger entries",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateLedger(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createLedger)
+}
+
+func Migrate_CreateLedger(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.Transfer{}, &models.LedgerEntry{}); err != nil {
+		return fmt.Errorf("failed to create ledger tables: %w", err)
+	}
+	logger.Info("Created transfers and ledger_entries tables.")
+
+	// Opening entries make the ledger the source of truth for existing balances
+	result := db.Exec(`INSERT INTO ledger_entries
+			(transfer_id, account_id, user_id, entry_type, direction, amount, currency, balance_after, created_at)
+		SELECT NULL, ab.account_id, ab.user_id, ?, ?, COALESCE(ab.amount, 0), a.currency, COALESCE(ab.amount, 0), NOW()
+		FROM account_balances ab
+		JOIN accounts a ON a.account_id = ab.account_id`,
+		models.LedgerEntryTypeOpening, models.LedgerDirectionCredit,
+	)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create opening ledger entries: %w", result.Error)
+	}
+	logger.Infof("Created %d opening ledger entries", result.RowsAffected)
+
+	// The ledger is append-only
+	statements := []string{
+		`CREATE TRIGGER trg_ledger_entries_no_update BEFORE UPDATE ON ledger_entries
+			FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'ledger_entries is append-only';`,
+		`CREATE TRIGGER trg_ledger_entries_no_delete BEFORE DELETE ON ledger_entries
+			FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'ledger_entries is append-only';`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create ledger trigger: %w", err)
+		}
+	}
+	logger.Info("Made ledger_entries append-only.")
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createFxRates = &Migration{
+	Number: 6,
+	Name:   "create fx rates",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateFxRates(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createFxRates)
+}
+
+func Migrate_CreateFxRates(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.FxRate{}); err != nil {
+		return fmt.Errorf("failed to create fx_rates table: %w", err)
+	}
+	logger.Info("Created fx_rates table.")
+	return nil
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createCardLifecycle = &Migration{
+	Number: 7,
+	Name:   "create card lifecycle",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateCardLifecycle(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createCardLifecycle)
+}
+
+func Migrate_CreateCardLifecycle(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.CardAuditLog{}); err != nil {
+		return fmt.Errorf("failed to create card_audit_logs table: %w", err)
+	}
+	logger.Info("Created card_audit_logs table.")
+
+	statements := []string{
+		`ALTER TABLE debit_cards ADD COLUMN replaces_card_id varchar(50) DEFAULT NULL;`,
+		`CREATE INDEX idx_debit_cards_replaces_card_id ON debit_cards (replaces_card_id);`,
+		// Cards without a status row could never change state
+		`INSERT INTO debit_card_status (card_id, user_id, status)
+			SELECT dc.card_id, dc.user_id, 'active' FROM debit_cards dc
+			LEFT JOIN debit_card_status s ON s.card_id = dc.card_id
+			WHERE s.card_id IS NULL;`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to execute statement: %s, error: %w", stmt, err)
+		}
+	}
+
+	// Seed statuses that spell a known state differently are normalized; any
+	// other value says nothing about the card, so it is blocked rather than
+	// guessed to be active
+	knownStatuses := []string{
+		models.CardStatusActive,
+		models.CardStatusFrozen,
+		models.CardStatusLost,
+		models.CardStatusReplaced,
+		models.CardStatusClosed,
+	}
+	if err := db.Exec(`UPDATE debit_card_status SET status = LOWER(TRIM(status))
+		WHERE LOWER(TRIM(status)) IN ?;`, knownStatuses).Error; err != nil {
+		return fmt.Errorf("failed to normalize debit card statuses: %w", err)
+	}
+	result := db.Exec(`UPDATE debit_card_status SET status = ?
+		WHERE status IS NULL OR status NOT IN ?;`, models.CardStatusBlocked, knownStatuses)
+	if result.Error != nil {
+		return fmt.Errorf("failed to block debit cards with unknown statuses: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Warnf("Blocked %d debit cards with an unknown status.", result.RowsAffected)
+	}
+
+	logger.Info("Normalized debit card statuses.")
+	return nil
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var encryptAccountNumbers = &Migration{
+	Number: 8,
+	Name:   "encrypt card and account numbers",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_EncryptAccountNumbers(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, encryptAccountNumbers)
+}
+
+func Migrate_EncryptAccountNumbers(db *gorm.DB) error {
+	keyring := encryption.Default()
+	if keyring == nil {
+		return errors.New("encryption keyring is not set up, check Encryption.KeyFile")
+	}
+
+	statements := []string{
+		// ciphertext is longer than the numbers it replaces
+		`ALTER TABLE debit_card_details MODIFY COLUMN number varchar(255) DEFAULT NULL;`,
+		`ALTER TABLE debit_card_details ADD COLUMN number_index char(64) DEFAULT NULL;`,
+		`CREATE INDEX idx_debit_card_details_number_index ON debit_card_details (number_index);`,
+		`ALTER TABLE accounts MODIFY COLUMN account_number varchar(255) DEFAULT NULL;`,
+		`ALTER TABLE accounts ADD COLUMN account_number_index char(64) DEFAULT NULL;`,
+		`CREATE INDEX idx_accounts_account_number_index ON accounts (account_number_index);`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to execute statement: %s, error: %w", stmt, err)
+		}
+	}
+
+	// only the tables that exist at this point, later migrations encrypt their own
+	return encryptColumns(db, keyring, models.CardNumberColumn, models.AccountNumberColumn)
+}
+
+// encryptColumns encrypts the existing values of each column in batches of
+// Encryption.BatchSize rows.
+func encryptColumns(db *gorm.DB, keyring *encryption.Keyring, columns ...encryption.Column) error {
+	batchSize := encryption.DefaultBatchSize
+	if cfg := config.GetConfig(); cfg != nil && cfg.Encryption != nil && cfg.Encryption.BatchSize > 0 {
+		batchSize = cfg.Encryption.BatchSize
+	}
+
+	for _, column := range columns {
+		count, err := encryption.EncryptColumn(db, keyring, column, batchSize)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Encrypted %d rows of %s.%s.", count, column.Table, column.Name)
+	}
+	return nil
+}
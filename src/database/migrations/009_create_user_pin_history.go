@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createUserPinHistory = &Migration{
+	Number: 9,
+	Name:   "create user pin history",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateUserPinHistory(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createUserPinHistory)
+}
+
+func Migrate_CreateUserPinHistory(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.UserPinHistory{}); err != nil {
+		return fmt.Errorf("failed to create user_pin_history table: %w", err)
+	}
+	logger.Info("Created user_pin_history table.")
+
+	// Used by the weak PIN check; NULL for users who never entered it
+	stmt := `ALTER TABLE users ADD COLUMN birth_date date DEFAULT NULL;`
+	if err := db.Exec(stmt).Error; err != nil {
+		return fmt.Errorf("failed to execute statement: %s, error: %w", stmt, err)
+	}
+	logger.Info("Added users.birth_date column.")
+	return nil
+}
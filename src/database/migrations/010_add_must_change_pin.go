@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var addMustChangePin = &Migration{
+	Number: 10,
+	Name:   "add must change pin",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_AddMustChangePin(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, addMustChangePin)
+}
+
+func Migrate_AddMustChangePin(db *gorm.DB) error {
+	stmt := `ALTER TABLE user_pins ADD COLUMN must_change_pin tinyint(1) NOT NULL DEFAULT 0;`
+	if err := db.Exec(stmt).Error; err != nil {
+		return fmt.Errorf("failed to execute statement: %s, error: %w", stmt, err)
+	}
+	logger.Info("Added user_pins.must_change_pin column.")
+
+	// Migration 002 gave every user the same hash, so only a few distinct
+	// hashes need the slow bcrypt compare
+	const defaultPIN = "123456"
+	var hashes []string
+	if err := db.Model(&models.UserPin{}).Distinct().Pluck("hashed_pin", &hashes).Error; err != nil {
+		return fmt.Errorf("failed to query pin hashes: %w", err)
+	}
+
+	var defaultHashes []string
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(defaultPIN)) == nil {
+			defaultHashes = append(defaultHashes, hash)
+		}
+	}
+	if len(defaultHashes) == 0 {
+		logger.Info("No users on the default PIN.")
+		return nil
+	}
+
+	result := db.Model(&models.UserPin{}).
+		Where("hashed_pin IN ?", defaultHashes).
+		Update("must_change_pin", true)
+	if result.Error != nil {
+		return fmt.Errorf("failed to flag default PINs: %w", result.Error)
+	}
+	logger.Infof("Flagged %d users on the default PIN.", result.RowsAffected)
+	return nil
+}
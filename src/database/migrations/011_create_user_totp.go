@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/encryption"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createUserTotp = &Migration{
+	Number: 11,
+	Name:   "create user totp",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateUserTotp(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createUserTotp)
+}
+
+func Migrate_CreateUserTotp(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.UserTotp{}); err != nil {
+		return fmt.Errorf("failed to create user_totp table: %w", err)
+	}
+	logger.Info("Created user_totp table.")
+
+	if err := db.Migrator().CreateTable(&models.UserRecoveryCode{}); err != nil {
+		return fmt.Errorf("failed to create user_recovery_codes table: %w", err)
+	}
+	logger.Info("Created user_recovery_codes table.")
+
+	keyring := encryption.Default()
+	if keyring == nil {
+		return errors.New("encryption keyring is not set up, check Encryption.KeyFile")
+	}
+	return encryptColumns(db, keyring, models.TotpSecretColumn)
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createUserDevices = &Migration{
+	Number: 12,
+	Name:   "create user devices",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateUserDevices(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createUserDevices)
+}
+
+func Migrate_CreateUserDevices(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.UserDevice{}); err != nil {
+		return fmt.Errorf("failed to create user_devices table: %w", err)
+	}
+	logger.Info("Created user_devices table.")
+	return nil
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createRoles = &Migration{
+	Number: 13,
+	Name:   "create roles",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateRoles(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createRoles)
+}
+
+// defaultRoles are seeded with the tables. Users are granted a role by
+// inserting a row into user_roles.
+var defaultRoles = []models.Role{
+	{
+		Name:        entities.RoleAdmin,
+		Description: "Full access to the admin API",
+		Scopes: []models.RoleScope{
+			{Scope: entities.ScopeTokensBan},
+			{Scope: entities.ScopeUsersRead},
+			{Scope: entities.ScopeUsersUnlock},
+			{Scope: entities.ScopeSessionsRead},
+			{Scope: entities.ScopeSessionsRevoke},
+			{Scope: entities.ScopePinReset},
+			{Scope: entities.ScopeAuditRead},
+		},
+	},
+	{
+		Name:        entities.RoleSupport,
+		Description: "Support desk, may look up users and unlock PINs",
+		Scopes: []models.RoleScope{
+			{Scope: entities.ScopeUsersRead},
+			{Scope: entities.ScopeUsersUnlock},
+			{Scope: entities.ScopeSessionsRead},
+			{Scope: entities.ScopeAuditRead},
+		},
+	},
+}
+
+func Migrate_CreateRoles(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.Role{}, &models.RoleScope{}, &models.UserRole{}); err != nil {
+		return fmt.Errorf("failed to create roles tables: %w", err)
+	}
+	logger.Info("Created roles, role_scopes and user_roles tables.")
+
+	// Creating the roles also inserts their scopes
+	roles := defaultRoles
+	if err := db.Create(&roles).Error; err != nil {
+		return fmt.Errorf("failed to seed roles: %w", err)
+	}
+	logger.Infof("Seeded %d roles", len(roles))
+	return nil
+}
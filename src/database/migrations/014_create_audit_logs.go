@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/logger"
+	"gorm.io/gorm"
+)
+
+var createAuditLogs = &Migration{
+	Number: 14,
+	Name:   "create audit logs",
+
+	Forwards: func(db *gorm.DB) error {
+		return Migrate_CreateAuditLogs(db)
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createAuditLogs)
+}
+
+// auditLogTriggers make the table append-only for every account, including
+// the one the API runs as.
+var auditLogTriggers = []string{
+	"CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs FOR EACH ROW " +
+		"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only'",
+	"CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs FOR EACH ROW " +
+		"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only'",
+}
+
+func Migrate_CreateAuditLogs(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&models.AuditLog{}); err != nil {
+		return fmt.Errorf("failed to create audit_logs table: %w", err)
+	}
+	for _, trigger := range auditLogTriggers {
+		if err := db.Exec(trigger).Error; err != nil {
+			return fmt.Errorf("failed to create audit_logs trigger: %w", err)
+		}
+	}
+	logger.Info("Created append-only audit_logs table.")
+	return nil
+}
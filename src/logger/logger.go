@@ -64,7 +64,7 @@ func InitLogger(config *LoggerConfig) error {
 	}
 
 	core := zapcore.NewCore(
-		logEncoder,
+		newRedactingEncoder(logEncoder),
 		os.Stdout,
 		zap.NewAtomicLevelAt(logLevel),
 	)
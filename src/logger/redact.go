@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Testzyler/banking-api/app/mask"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactingEncoder masks card and account numbers in the message and string
+// fields of every entry before the wrapped encoder writes it, so a number
+// passed to any log call only ever shows its last 4 digits.
+type redactingEncoder struct {
+	zapcore.Encoder
+}
+
+func newRedactingEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return &redactingEncoder{Encoder: enc}
+}
+
+func (e *redactingEncoder) Clone() zapcore.Encoder {
+	return &redactingEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// AddString and AddByteString cover context fields added through With.
+func (e *redactingEncoder) AddString(key, value string) {
+	e.Encoder.AddString(key, mask.Redact(value))
+}
+
+func (e *redactingEncoder) AddByteString(key string, value []byte) {
+	e.Encoder.AddString(key, mask.Redact(string(value)))
+}
+
+func (e *redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	entry.Message = mask.Redact(entry.Message)
+	return e.Encoder.EncodeEntry(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = redactField(field)
+	}
+	return redacted
+}
+
+func redactField(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = mask.Redact(field.String)
+	case zapcore.ByteStringType:
+		if value, ok := field.Interface.([]byte); ok {
+			return zap.String(field.Key, mask.Redact(string(value)))
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if message := err.Error(); mask.Redact(message) != message {
+				return zap.String(field.Key, mask.Redact(message))
+			}
+		}
+	case zapcore.StringerType:
+		if value, ok := field.Interface.(fmt.Stringer); ok {
+			if text, ok := stringerText(value); ok {
+				return zap.String(field.Key, mask.Redact(text))
+			}
+		}
+	case zapcore.ReflectType:
+		// structs and maps keep their shape unless they carry a number
+		if data, err := json.Marshal(field.Interface); err == nil {
+			if redacted := mask.Redact(string(data)); redacted != string(data) {
+				return zap.String(field.Key, redacted)
+			}
+		}
+	}
+	return field
+}
+
+// stringerText calls String the way zap does, treating a panic (usually a nil
+// pointer receiver) as "leave the field to zap".
+func stringerText(value fmt.Stringer) (text string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return value.String(), true
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testStringer string
+
+func (s testStringer) String() string { return string(s) }
+
+func TestRedactingEncoder(t *testing.T) {
+	enc := newRedactingEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		MessageKey: "msg",
+	}))
+	enc.AddString("card", "4000 1234 5678 9010")
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "reveal account 123456789012"}, []zapcore.Field{
+		zap.String("number", "4000123456789010"),
+		zap.ByteString("raw", []byte("123-4-56789-0")),
+		zap.Error(errors.New("card 4000 1234 5678 9010 not found")),
+		zap.Stringer("stringer", testStringer("acc 123456789012")),
+		zap.Any("payload", map[string]string{"accountNumber": "123456789012"}),
+		zap.Int("attempts", 3),
+		zap.String("cardID", "card-1"),
+	})
+	assert.NoError(t, err)
+
+	line := buf.String()
+	assert.JSONEq(t, `{
+		"msg": "reveal account ********9012",
+		"card": "**** **** **** 9010",
+		"number": "************9010",
+		"raw": "***-*-**789-0",
+		"error": "card **** **** **** 9010 not found",
+		"stringer": "acc ********9012",
+		"payload": "{\"accountNumber\":\"********9012\"}",
+		"attempts": 3,
+		"cardID": "card-1"
+	}`, line)
+}
+
+func TestRedactingEncoder_KeepsUnrelatedFields(t *testing.T) {
+	field := redactField(zap.Any("payload", map[string]int{"attempts": 3}))
+	assert.Equal(t, zapcore.ReflectType, field.Type)
+
+	err := errors.New("invalid pin")
+	field = redactField(zap.Error(err))
+	assert.Equal(t, zapcore.ErrorType, field.Type)
+	assert.Equal(t, err, field.Interface)
+}
+
+func TestRedactingEncoder_Clone(t *testing.T) {
+	enc := newRedactingEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}))
+
+	clone := enc.Clone()
+	clone.AddString("account", "123456789012")
+	buf, err := clone.EncodeEntry(zapcore.Entry{Message: "context"}, nil)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"msg":"context","account":"********9012"}`, buf.String())
+}
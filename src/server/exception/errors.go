@@ -31,6 +31,117 @@ var (
 		Details:        "The PIN is incorrect. Please try again",
 	}
 
+	ErrInvalidCredentials = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnauthorized,
+		Code:           response.ErrCodeUnauthorized,
+		Message:        "Invalid credentials",
+		Details:        "The username or PIN is incorrect, or the PIN is locked. Please try again later",
+	}
+
+	ErrPinReused = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnprocessableEntity,
+		Code:           response.ErrCodePinReused,
+		Message:        "PIN reused",
+		Details:        "The new PIN matches the current PIN or one of your recent PINs",
+	}
+
+	ErrPinChangeRequired = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusForbidden,
+		Code:           response.ErrCodePinChangeRequired,
+		Message:        "PIN change required",
+		Details:        "This token can only be used to change the PIN",
+	}
+
+	ErrInvalidResetCode = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeInvalidResetCode,
+		Message:        "Invalid reset code",
+		Details:        "The reset code is wrong, expired or was already used",
+	}
+
+	// Two-factor errors
+	ErrTwoFactorRequired = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusForbidden,
+		Code:           response.ErrCodeTwoFactorRequired,
+		Message:        "Two-factor verification required",
+		Details:        "Verify an authenticator code before this operation",
+	}
+
+	ErrInvalidTwoFactorCode = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnauthorized,
+		Code:           response.ErrCodeInvalidTwoFactorCode,
+		Message:        "Invalid two-factor code",
+		Details:        "The code is wrong, expired or was already used",
+	}
+
+	ErrTwoFactorAlreadyEnabled = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusConflict,
+		Code:           response.ErrCodeTwoFactorAlreadyEnabled,
+		Message:        "Two-factor already enabled",
+		Details:        "An authenticator app is already enrolled for this user",
+	}
+
+	ErrTwoFactorNotEnrolled = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeTwoFactorNotEnrolled,
+		Message:        "Two-factor not enrolled",
+		Details:        "Enroll an authenticator app first",
+	}
+
+	ErrTooManyTwoFactorAttempts = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusTooManyRequests,
+		Code:           response.ErrCodeTooManyTwoFactorAttempts,
+		Message:        "Too many two-factor attempts",
+		Details:        "Too many codes were tried. Please try again later",
+	}
+
+	// Step-up errors
+	ErrReauthRequired = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnauthorized,
+		Code:           response.ErrCodeReauthRequired,
+		Message:        "Reauthentication required",
+		Details:        "The PIN was entered too long ago. Please enter it again",
+	}
+
+	// Session errors
+	ErrRefreshTokenReused = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnauthorized,
+		Code:           response.ErrCodeRefreshTokenReused,
+		Message:        "Refresh token already used",
+		Details:        "The session was ended because its refresh token was used twice. Please sign in again",
+	}
+
+	ErrInvalidClient = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnauthorized,
+		Code:           response.ErrCodeInvalidClient,
+		Message:        "Invalid client credentials",
+		Details:        "The service client ID or secret is missing or wrong",
+	}
+
+	// Device errors
+	ErrDeviceKeyMismatch = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusConflict,
+		Code:           response.ErrCodeDeviceKeyMismatch,
+		Message:        "Device key mismatch",
+		Details:        "The device is registered with a different public key. Revoke it before registering it again",
+	}
+
+	// Authorization errors
+	ErrInsufficientPermissions = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusForbidden,
+		Code:           response.ErrCodeInsufficientPermissions,
+		Message:        "Insufficient permissions",
+		Details:        "Your roles do not grant access to this resource",
+	}
+
+	// Rate limit errors
+	ErrRateLimitExceeded = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusTooManyRequests,
+		Code:           response.ErrCodeRateLimitExceeded,
+		Message:        "Rate limit exceeded",
+		Details:        "Too many requests. Please try again after the time in the Retry-After header",
+	}
+
 	// 4xx Client Errors
 	ErrUserNotFound = &response.ErrorResponse{
 		HttpStatusCode: fiber.StatusNotFound,
@@ -39,6 +150,20 @@ var (
 		Details:        "The user with the specified ID does not exist",
 	}
 
+	ErrSessionNotFound = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusNotFound,
+		Code:           response.ErrCodeNotFound,
+		Message:        "Session not found",
+		Details:        "The session with the specified token ID does not exist",
+	}
+
+	ErrDeviceNotFound = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusNotFound,
+		Code:           response.ErrCodeNotFound,
+		Message:        "Device not found",
+		Details:        "No registered device has the specified device ID",
+	}
+
 	ErrInvalidUserID = &response.ErrorResponse{
 		HttpStatusCode: fiber.StatusBadRequest,
 		Code:           response.ErrCodeBadRequest,
@@ -53,6 +178,107 @@ var (
 		Details:        "Page number must be positive and perPage must be between 1 and 100",
 	}
 
+	ErrInvalidSortField = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid sort field",
+		Details:        "The requested sort field is not supported for this resource",
+	}
+
+	ErrInvalidCursor = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid cursor",
+		Details:        "The cursor is malformed, tampered with or was issued for a different sort order",
+	}
+
+	ErrAccountNotFound = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusNotFound,
+		Code:           response.ErrCodeNotFound,
+		Message:        "Account not found",
+		Details:        "The account does not exist or does not belong to the current user",
+	}
+
+	ErrInvalidStatementParams = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid statement parameters",
+		Details:        "from and to must be YYYY-MM-DD dates and format one of csv, ofx or pdf",
+	}
+
+	ErrInvalidStatementPeriod = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid statement period",
+		Details:        "The from date must not be after the to date",
+	}
+
+	// Transfer errors
+	ErrInsufficientFunds = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnprocessableEntity,
+		Code:           response.ErrCodeInsufficientFunds,
+		Message:        "Insufficient funds",
+		Details:        "The source account balance is lower than the transfer amount",
+	}
+
+	ErrCurrencyMismatch = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnprocessableEntity,
+		Code:           response.ErrCodeCurrencyMismatch,
+		Message:        "Currency mismatch",
+		Details:        "The source and destination accounts must use the same currency",
+	}
+
+	ErrSameAccountTransfer = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeSameAccountTransfer,
+		Message:        "Same account transfer",
+		Details:        "The source and destination accounts must be different",
+	}
+
+	ErrInvalidCurrency = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid currency",
+		Details:        "Currency must be a 3-letter ISO 4217 code",
+	}
+
+	// Card errors
+	ErrCardNotFound = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusNotFound,
+		Code:           response.ErrCodeNotFound,
+		Message:        "Card not found",
+		Details:        "The card does not exist or does not belong to the current user",
+	}
+
+	ErrCardStatusChanged = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusConflict,
+		Code:           response.ErrCodeConflict,
+		Message:        "Card status changed",
+		Details:        "The card status was changed by another request, reload the card and try again",
+	}
+
+	// Idempotency errors
+	ErrIdempotencyInProgress = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusConflict,
+		Code:           response.ErrCodeConflict,
+		Message:        "Request in progress",
+		Details:        "A request with this Idempotency-Key is still being processed",
+	}
+
+	ErrIdempotencyKeyReused = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnprocessableEntity,
+		Code:           response.ErrCodeIdempotencyKeyReused,
+		Message:        "Idempotency key reused",
+		Details:        "This Idempotency-Key was already used with a different request body",
+	}
+
+	ErrInvalidIdempotencyKey = &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusBadRequest,
+		Code:           response.ErrCodeBadRequest,
+		Message:        "Invalid idempotency key",
+		Details:        "The Idempotency-Key header must be between 1 and 255 characters",
+	}
+
 	ErrValidationFailed = &response.ErrorResponse{
 		HttpStatusCode: fiber.StatusUnprocessableEntity,
 		Code:           response.ErrCodeValidationFailed,
@@ -185,3 +411,21 @@ func NewTokenBanFailedError() *response.ErrorResponse {
 		Details:        "An error occurred while trying to ban user tokens",
 	}
 }
+
+func NewInvalidCardTransitionError(action, status string) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusConflict,
+		Code:           response.ErrCodeInvalidCardTransition,
+		Message:        "Invalid card status change",
+		Details:        fmt.Sprintf("Cannot %s a card that is %s", action, status),
+	}
+}
+
+func NewWeakPinError(reason string) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		HttpStatusCode: fiber.StatusUnprocessableEntity,
+		Code:           response.ErrCodeWeakPin,
+		Message:        "Weak PIN",
+		Details:        fmt.Sprintf("The new PIN is too easy to guess: %s", reason),
+	}
+}
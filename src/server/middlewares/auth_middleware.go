@@ -3,6 +3,7 @@ package middlewares
 import (
 	"strings"
 
+	"github.com/Testzyler/banking-api/app/entities"
 	authRepository "github.com/Testzyler/banking-api/app/features/auth/repository"
 	authService "github.com/Testzyler/banking-api/app/features/auth/service"
 	"github.com/Testzyler/banking-api/config"
@@ -12,7 +13,32 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthMiddleware() fiber.Handler {
+type authOptions struct {
+	allowedPurposes map[string]bool
+}
+
+// AuthOption customizes AuthMiddleware for a single route.
+type AuthOption func(*authOptions)
+
+// AllowTokenPurpose lets restricted tokens issued for purpose reach the
+// route. Restricted tokens are rejected everywhere else.
+func AllowTokenPurpose(purpose string) AuthOption {
+	return func(o *authOptions) {
+		o.allowedPurposes[purpose] = true
+	}
+}
+
+func newAuthOptions(options []AuthOption) *authOptions {
+	o := &authOptions{allowedPurposes: map[string]bool{}}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+func AuthMiddleware(options ...AuthOption) fiber.Handler {
+	opts := newAuthOptions(options)
+
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -46,7 +72,24 @@ func AuthMiddleware() fiber.Handler {
 			return exception.ErrUnauthorized
 		}
 
+		if err := opts.checkPurpose(validationResult.Claims); err != nil {
+			logger.Infof("Blocked restricted token %s on %s", validationResult.Claims.TokenID, c.Path())
+			c.Locals("status", fiber.StatusForbidden)
+			return err
+		}
+
 		c.Locals("user", validationResult.Claims)
 		return c.Next()
 	}
 }
+
+// checkPurpose rejects restricted tokens on routes that did not opt in.
+func (o *authOptions) checkPurpose(claims entities.Claims) error {
+	if claims.Purpose == "" || o.allowedPurposes[claims.Purpose] {
+		return nil
+	}
+	if claims.Purpose == entities.TokenPurposeChangePin {
+		return exception.ErrPinChangeRequired
+	}
+	return exception.ErrForbidden
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthOptions_CheckPurpose(t *testing.T) {
+	restricted := entities.Claims{UserID: "user123", Purpose: entities.TokenPurposeChangePin}
+
+	tests := []struct {
+		name        string
+		options     []AuthOption
+		claims      entities.Claims
+		expectError error
+	}{
+		{
+			name:   "regular token",
+			claims: entities.Claims{UserID: "user123"},
+		},
+		{
+			name:        "restricted token on a regular route",
+			claims:      restricted,
+			expectError: exception.ErrPinChangeRequired,
+		},
+		{
+			name:    "restricted token on the change pin route",
+			options: []AuthOption{AllowTokenPurpose(entities.TokenPurposeChangePin)},
+			claims:  restricted,
+		},
+		{
+			name:    "regular token on the change pin route",
+			options: []AuthOption{AllowTokenPurpose(entities.TokenPurposeChangePin)},
+			claims:  entities.Claims{UserID: "user123"},
+		},
+		{
+			name:        "unknown purpose",
+			options:     []AuthOption{AllowTokenPurpose(entities.TokenPurposeChangePin)},
+			claims:      entities.Claims{UserID: "user123", Purpose: "other"},
+			expectError: exception.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAuthOptions(tt.options).checkPurpose(tt.claims)
+			assert.Equal(t, tt.expectError, err)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyLock    = 30 * time.Second
+	defaultIdempotencyTTL     = 24 * time.Hour
+	idempotencyStateRunning   = "processing"
+	idempotencyStateCompleted = "completed"
+)
+
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type idempotency struct {
+	client      redis.Cmdable
+	lockTimeout time.Duration
+	responseTTL time.Duration
+}
+
+// IdempotencyMiddleware stores the first response for an Idempotency-Key and
+// replays it for retries of the same request. Requests without the header
+// pass through untouched. Place it after AuthMiddleware on protected routes
+// so keys are scoped per user.
+func IdempotencyMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(IdempotencyKeyHeader) == "" {
+			return c.Next()
+		}
+		return newIdempotency(database.GetCache(), config.GetConfig().Idempotency).handle(c)
+	}
+}
+
+func newIdempotency(cache *database.RedisDatabase, cfg *config.IdempotencyConfig) *idempotency {
+	i := &idempotency{
+		client:      cache.GetClient(),
+		lockTimeout: defaultIdempotencyLock,
+		responseTTL: defaultIdempotencyTTL,
+	}
+	if cfg != nil && cfg.LockTimeout > 0 {
+		i.lockTimeout = cfg.LockTimeout
+	}
+	if cfg != nil && cfg.ResponseTTL > 0 {
+		i.responseTTL = cfg.ResponseTTL
+	}
+	return i
+}
+
+func (i *idempotency) handle(c *fiber.Ctx) error {
+	idempotencyKey := c.Get(IdempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return exception.ErrInvalidIdempotencyKey
+	}
+
+	ctx := c.Context()
+	key := i.cacheKey(c, idempotencyKey)
+	fingerprint := fingerprintBody(c.Body())
+
+	running, err := json.Marshal(idempotencyRecord{State: idempotencyStateRunning, Fingerprint: fingerprint})
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	acquired, err := i.client.SetNX(ctx, key, running, i.lockTimeout).Result()
+	if err != nil {
+		logger.Errorf("Failed to acquire idempotency key %s: %v", key, err)
+		return exception.ErrServiceUnavailable
+	}
+	if !acquired {
+		return i.replay(ctx, c, key, fingerprint)
+	}
+
+	if err := c.Next(); err != nil {
+		// render the error now so the response can be stored
+		if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+			i.release(ctx, key)
+			return handlerErr
+		}
+	}
+
+	statusCode := c.Response().StatusCode()
+	if statusCode >= fiber.StatusInternalServerError {
+		// let the client retry server failures
+		i.release(ctx, key)
+		return nil
+	}
+
+	completed, err := json.Marshal(idempotencyRecord{
+		State:       idempotencyStateCompleted,
+		Fingerprint: fingerprint,
+		StatusCode:  statusCode,
+		ContentType: string(c.Response().Header.ContentType()),
+		Body:        append([]byte(nil), c.Response().Body()...),
+	})
+	if err != nil {
+		i.release(ctx, key)
+		return nil
+	}
+	if err := i.client.Set(ctx, key, completed, i.responseTTL).Err(); err != nil {
+		logger.Errorf("Failed to store idempotent response for key %s: %v", key, err)
+	}
+
+	return nil
+}
+
+func (i *idempotency) replay(ctx context.Context, c *fiber.Ctx, key, fingerprint string) error {
+	data, err := i.client.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// the first request failed and released the key between our calls
+			return exception.ErrIdempotencyInProgress
+		}
+		logger.Errorf("Failed to read idempotency key %s: %v", key, err)
+		return exception.ErrServiceUnavailable
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	if record.Fingerprint != fingerprint {
+		return exception.ErrIdempotencyKeyReused
+	}
+	if record.State != idempotencyStateCompleted {
+		return exception.ErrIdempotencyInProgress
+	}
+
+	c.Set(IdempotentReplayedHeader, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	return c.Status(record.StatusCode).Send(record.Body)
+}
+
+func (i *idempotency) release(ctx context.Context, key string) {
+	if err := i.client.Del(ctx, key).Err(); err != nil {
+		logger.Warnf("Failed to release idempotency key %s: %v", key, err)
+	}
+}
+
+// cacheKey scopes the client key to the caller and route so two users or two
+// endpoints can never collide on the same value.
+func (i *idempotency) cacheKey(c *fiber.Ctx, idempotencyKey string) string {
+	scope := "anonymous"
+	if user, ok := c.Locals("user").(entities.Claims); ok && user.UserID != "" {
+		scope = user.UserID
+	}
+	return fmt.Sprintf("idempotency:%s:%s:%s:%s", scope, c.Method(), c.Path(), idempotencyKey)
+}
+
+func fingerprintBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,207 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	testIdempotencyKey = "idempotency:anonymous:POST:/transfers:key-1"
+	testRequestBody    = `{"amount":10}`
+)
+
+func mustRecord(t *testing.T, record idempotencyRecord) []byte {
+	data, err := json.Marshal(record)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	fingerprint := fingerprintBody([]byte(testRequestBody))
+	cfg := &config.IdempotencyConfig{LockTimeout: 10 * time.Second, ResponseTTL: time.Hour}
+	running := idempotencyRecord{State: idempotencyStateRunning, Fingerprint: fingerprint}
+
+	tests := []struct {
+		name           string
+		key            string
+		handler        fiber.Handler
+		setupMock      func(*testing.T, redismock.ClientMock)
+		expectedStatus int
+		expectedBody   string
+		expectCalled   bool
+		expectReplayed bool
+	}{
+		{
+			name: "first request stores the response",
+			key:  "key-1",
+			handler: func(c *fiber.Ctx) error {
+				return c.Status(fiber.StatusCreated).JSON(fiber.Map{"transferID": "tr-1"})
+			},
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(true)
+				mock.ExpectSet(testIdempotencyKey, mustRecord(t, idempotencyRecord{
+					State:       idempotencyStateCompleted,
+					Fingerprint: fingerprint,
+					StatusCode:  fiber.StatusCreated,
+					ContentType: fiber.MIMEApplicationJSON,
+					Body:        []byte(`{"transferID":"tr-1"}`),
+				}), time.Hour).SetVal("OK")
+			},
+			expectedStatus: fiber.StatusCreated,
+			expectedBody:   `{"transferID":"tr-1"}`,
+			expectCalled:   true,
+		},
+		{
+			name: "client errors are stored too",
+			key:  "key-1",
+			handler: func(c *fiber.Ctx) error {
+				return exception.ErrInsufficientFunds
+			},
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				body, _ := json.Marshal(exception.ErrInsufficientFunds)
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(true)
+				mock.ExpectSet(testIdempotencyKey, mustRecord(t, idempotencyRecord{
+					State:       idempotencyStateCompleted,
+					Fingerprint: fingerprint,
+					StatusCode:  fiber.StatusUnprocessableEntity,
+					ContentType: fiber.MIMEApplicationJSON,
+					Body:        body,
+				}), time.Hour).SetVal("OK")
+			},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+			expectCalled:   true,
+		},
+		{
+			name: "server errors release the key",
+			key:  "key-1",
+			handler: func(c *fiber.Ctx) error {
+				return errors.New("database down")
+			},
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(true)
+				mock.ExpectDel(testIdempotencyKey).SetVal(1)
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+			expectCalled:   true,
+		},
+		{
+			name: "duplicate replays the stored response",
+			key:  "key-1",
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(false)
+				mock.ExpectGet(testIdempotencyKey).SetVal(string(mustRecord(t, idempotencyRecord{
+					State:       idempotencyStateCompleted,
+					Fingerprint: fingerprint,
+					StatusCode:  fiber.StatusCreated,
+					ContentType: fiber.MIMEApplicationJSON,
+					Body:        []byte(`{"transferID":"tr-1"}`),
+				})))
+			},
+			expectedStatus: fiber.StatusCreated,
+			expectedBody:   `{"transferID":"tr-1"}`,
+			expectReplayed: true,
+		},
+		{
+			name: "duplicate while first request is running",
+			key:  "key-1",
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(false)
+				mock.ExpectGet(testIdempotencyKey).SetVal(string(mustRecord(t, running)))
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "key reused with a different body",
+			key:  "key-1",
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetVal(false)
+				mock.ExpectGet(testIdempotencyKey).SetVal(string(mustRecord(t, idempotencyRecord{
+					State:       idempotencyStateCompleted,
+					Fingerprint: fingerprintBody([]byte(`{"amount":99}`)),
+					StatusCode:  fiber.StatusCreated,
+				})))
+			},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name: "redis unavailable",
+			key:  "key-1",
+			setupMock: func(t *testing.T, mock redismock.ClientMock) {
+				mock.ExpectSetNX(testIdempotencyKey, mustRecord(t, running), 10*time.Second).SetErr(errors.New("connection refused"))
+			},
+			expectedStatus: fiber.StatusServiceUnavailable,
+		},
+		{
+			name:           "key too long",
+			key:            strings.Repeat("k", maxIdempotencyKeyLength+1),
+			setupMock:      func(t *testing.T, mock redismock.ClientMock) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.setupMock(t, mock)
+
+			called := false
+			handler := tt.handler
+			if handler == nil {
+				handler = func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+			}
+
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+			app.Post("/transfers",
+				newIdempotency(&database.RedisDatabase{Client: client}, cfg).handle,
+				func(c *fiber.Ctx) error {
+					called = true
+					return handler(c)
+				},
+			)
+
+			req := httptest.NewRequest("POST", "/transfers", strings.NewReader(testRequestBody))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set(IdempotencyKeyHeader, tt.key)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectCalled, called)
+			if tt.expectedBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+			if tt.expectReplayed {
+				assert.Equal(t, "true", resp.Header.Get(IdempotentReplayedHeader))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_WithoutHeader(t *testing.T) {
+	app := fiber.New()
+	app.Post("/transfers", IdempotencyMiddleware(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/transfers", strings.NewReader(testRequestBody)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
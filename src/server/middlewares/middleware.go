@@ -3,6 +3,7 @@ package middlewares
 import (
 	"time"
 
+	"github.com/Testzyler/banking-api/app/audit"
 	"github.com/Testzyler/banking-api/logger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -38,6 +39,19 @@ func GetRequestID(c *fiber.Ctx) string {
 	return ""
 }
 
+// AuditRequestMiddleware keeps the client IP, user agent and request ID in the
+// request context, the audit log adds them to every event of the request.
+func AuditRequestMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(audit.RequestKey, audit.Request{
+			IPAddress: c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			RequestID: GetRequestID(c),
+		})
+		return c.Next()
+	}
+}
+
 func GetStatus(c *fiber.Ctx) int {
 	if status, ok := c.Locals("status").(int); ok {
 		return status
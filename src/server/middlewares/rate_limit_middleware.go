@@ -0,0 +1,226 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// slidingWindowScript keeps one sorted set of request timestamps per key and
+// only records the request when every key is below its limit, so a rejected
+// request never counts. It returns whether the request is allowed and the
+// limit, remaining requests and milliseconds until a slot frees up of the
+// tightest key: the one with the fewest requests left, or when rejected the
+// one that stays full the longest.
+//
+// KEYS: one per limit. ARGV: member, then requests, window in milliseconds
+// and whether to record the request for every key. Keys that are not recorded
+// here, like failure counters, are only checked.
+var slidingWindowScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local allowed = 1
+local limit, remaining, reset = 0, -1, 0
+
+for i, key in ipairs(KEYS) do
+	local max = tonumber(ARGV[i * 3 - 1])
+	local window = tonumber(ARGV[i * 3])
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	local count = redis.call('ZCARD', key)
+	local keyReset = window
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if oldest[2] then
+		keyReset = tonumber(oldest[2]) + window - now
+	end
+
+	if count >= max then
+		if allowed == 1 or keyReset > reset then
+			limit, remaining, reset = max, 0, keyReset
+		end
+		allowed = 0
+	elseif allowed == 1 and (remaining < 0 or max - count - 1 < remaining) then
+		limit, remaining, reset = max, max - count - 1, keyReset
+	end
+end
+
+if allowed == 1 then
+	for i, key in ipairs(KEYS) do
+		if ARGV[i * 3 + 1] == '1' then
+			redis.call('ZADD', key, now, ARGV[1])
+			redis.call('PEXPIRE', key, ARGV[i * 3])
+		end
+	end
+end
+
+return {allowed, limit, remaining, reset}
+`)
+
+// recordScript adds a request to a sliding window after the fact.
+//
+// KEYS: the window. ARGV: member, window in milliseconds.
+var recordScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+redis.call('ZADD', KEYS[1], now, ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+type rateLimiter struct {
+	client redis.Cmdable
+	route  string
+	limits *config.RouteRateLimit
+	member func() string
+}
+
+// RateLimitMiddleware caps the requests to a route per client IP, user and
+// token, and the failed requests per client IP, with the limits configured
+// under RateLimit.Routes.<route>. Place it
+// after AuthMiddleware on protected routes so user limits apply. Routes
+// without limits, or a disabled limiter, pass through untouched.
+func RateLimitMiddleware(route string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := config.GetConfig()
+		if cfg == nil || cfg.RateLimit == nil || !cfg.RateLimit.Enabled {
+			return c.Next()
+		}
+		limits, ok := cfg.RateLimit.Routes[strings.ToLower(route)]
+		if !ok || limits == nil {
+			return c.Next()
+		}
+		return newRateLimiter(database.GetCache(), route, limits).handle(c)
+	}
+}
+
+func newRateLimiter(cache *database.RedisDatabase, route string, limits *config.RouteRateLimit) *rateLimiter {
+	return &rateLimiter{
+		client: cache.GetClient(),
+		route:  route,
+		limits: limits,
+		member: uuid.NewString,
+	}
+}
+
+func (r *rateLimiter) handle(c *fiber.Ctx) error {
+	member := r.member()
+	keys, args := r.buckets(c, member)
+	if len(keys) == 0 {
+		return c.Next()
+	}
+
+	result, err := slidingWindowScript.Run(c.Context(), r.client, keys, args...).Int64Slice()
+	if err != nil || len(result) != 4 {
+		// a Redis outage must not lock every client out, PIN lockout still applies
+		logger.Errorf("Failed to check rate limit for route %s: %v", r.route, err)
+		return c.Next()
+	}
+
+	allowed, limit, remaining, reset := result[0] == 1, result[1], result[2], result[3]
+	resetSeconds := strconv.FormatInt((reset+999)/1000, 10)
+	c.Set(RateLimitLimitHeader, strconv.FormatInt(limit, 10))
+	c.Set(RateLimitRemainingHeader, strconv.FormatInt(remaining, 10))
+	c.Set(RateLimitResetHeader, resetSeconds)
+
+	if !allowed {
+		logger.Warnf("Rate limit exceeded on route %s by %s", r.route, c.IP())
+		c.Set(fiber.HeaderRetryAfter, resetSeconds)
+		return exception.ErrRateLimitExceeded
+	}
+
+	err = c.Next()
+	if r.limits.FailedIP != nil && isFailedAttempt(c, err) {
+		r.recordFailure(c, member)
+	}
+	return err
+}
+
+// buckets returns the Redis key and script arguments of every limit that
+// applies to the request.
+func (r *rateLimiter) buckets(c *fiber.Ctx, member string) ([]string, []interface{}) {
+	var keys []string
+	args := []interface{}{member}
+
+	add := func(limit *config.RateLimit, kind, id string, record bool) {
+		if !validLimit(limit) || id == "" {
+			return
+		}
+		keys = append(keys, r.key(kind, id))
+		args = append(args, limit.Requests, limit.Window.Milliseconds(), record)
+	}
+
+	add(r.limits.IP, "ip", c.IP(), true)
+	if user, ok := c.Locals("user").(entities.Claims); ok {
+		add(r.limits.User, "user", user.UserID, true)
+	}
+	add(r.limits.Token, "token", presentedToken(c), true)
+	// failures are recorded once the response is known
+	add(r.limits.FailedIP, "failed-ip", c.IP(), false)
+
+	return keys, args
+}
+
+func (r *rateLimiter) recordFailure(c *fiber.Ctx, member string) {
+	if !validLimit(r.limits.FailedIP) {
+		return
+	}
+	key := r.key("failed-ip", c.IP())
+	if err := recordScript.Run(c.Context(), r.client, []string{key}, member, r.limits.FailedIP.Window.Milliseconds()).Err(); err != nil {
+		logger.Errorf("Failed to record failed attempt for route %s: %v", r.route, err)
+	}
+}
+
+func (r *rateLimiter) key(kind, id string) string {
+	return fmt.Sprintf("ratelimit:%s:%s:%s", r.route, kind, id)
+}
+
+func validLimit(limit *config.RateLimit) bool {
+	return limit != nil && limit.Requests > 0 && limit.Window > 0
+}
+
+// isFailedAttempt reports whether the request was rejected for its
+// credentials: 401 for a wrong PIN or token, 404 for an unknown user.
+func isFailedAttempt(c *fiber.Ctx, err error) bool {
+	status := c.Response().StatusCode()
+	var errResp *response.ErrorResponse
+	if errors.As(err, &errResp) {
+		status = errResp.HttpStatusCode
+	}
+	return status == fiber.StatusUnauthorized || status == fiber.StatusNotFound
+}
+
+// presentedToken fingerprints the bearer token of the request, or the refresh
+// token in the body of a refresh call, so the raw token never ends up in Redis.
+func presentedToken(c *fiber.Ctx) string {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		var body entities.RefreshTokenRequest
+		if json.Unmarshal(c.Body(), &body) == nil {
+			token = body.RefreshToken
+		}
+	}
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
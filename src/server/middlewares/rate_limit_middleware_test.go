@@ -0,0 +1,191 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/Testzyler/banking-api/server/response"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const testRateLimitMember = "member-1"
+
+func TestRateLimitMiddleware(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	perMinute := &config.RateLimit{Requests: 5, Window: time.Minute}
+	tokenSum := sha256.Sum256([]byte("refresh-token-1"))
+	tokenKey := "ratelimit:refresh:token:" + hex.EncodeToString(tokenSum[:16])
+
+	tests := []struct {
+		name            string
+		limits          *config.RouteRateLimit
+		user            *entities.Claims
+		body            string
+		handlerErr      error
+		setupMock       func(redismock.ClientMock)
+		expectedStatus  int
+		expectedHeaders map[string]string
+		expectCalled    bool
+	}{
+		{
+			name:   "allowed request carries the remaining quota",
+			limits: &config.RouteRateLimit{IP: perMinute},
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:ip:0.0.0.0"},
+					testRateLimitMember, 5, int64(60000), true).
+					SetVal([]interface{}{int64(1), int64(5), int64(3), int64(42100)})
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedHeaders: map[string]string{
+				RateLimitLimitHeader:     "5",
+				RateLimitRemainingHeader: "3",
+				RateLimitResetHeader:     "43",
+			},
+			expectCalled: true,
+		},
+		{
+			name:   "rejected request gets Retry-After",
+			limits: &config.RouteRateLimit{IP: perMinute},
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:ip:0.0.0.0"},
+					testRateLimitMember, 5, int64(60000), true).
+					SetVal([]interface{}{int64(0), int64(5), int64(0), int64(12000)})
+			},
+			expectedStatus: fiber.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				RateLimitRemainingHeader: "0",
+				fiber.HeaderRetryAfter:   "12",
+			},
+		},
+		{
+			name:   "user and token limits apply to signed-in requests",
+			limits: &config.RouteRateLimit{User: perMinute, Token: &config.RateLimit{Requests: 2, Window: 10 * time.Second}},
+			user:   &entities.Claims{UserID: "user123"},
+			body:   `{"refreshToken":"refresh-token-1"}`,
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:user:user123", tokenKey},
+					testRateLimitMember, 5, int64(60000), true, 2, int64(10000), true).
+					SetVal([]interface{}{int64(1), int64(2), int64(1), int64(10000)})
+			},
+			expectedStatus: fiber.StatusOK,
+			expectCalled:   true,
+		},
+		{
+			name:       "failed attempts are counted per IP",
+			limits:     &config.RouteRateLimit{FailedIP: perMinute},
+			handlerErr: exception.ErrInvalidPin,
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:failed-ip:0.0.0.0"},
+					testRateLimitMember, 5, int64(60000), false).
+					SetVal([]interface{}{int64(1), int64(5), int64(4), int64(60000)})
+				mock.ExpectEvalSha(recordScript.Hash(), []string{"ratelimit:refresh:failed-ip:0.0.0.0"},
+					testRateLimitMember, int64(60000)).
+					SetVal(int64(1))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+			expectCalled:   true,
+		},
+		{
+			name:   "successful attempts are not counted as failures",
+			limits: &config.RouteRateLimit{FailedIP: perMinute},
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:failed-ip:0.0.0.0"},
+					testRateLimitMember, 5, int64(60000), false).
+					SetVal([]interface{}{int64(1), int64(5), int64(4), int64(60000)})
+			},
+			expectedStatus: fiber.StatusOK,
+			expectCalled:   true,
+		},
+		{
+			name:           "no applicable limit skips Redis",
+			limits:         &config.RouteRateLimit{User: perMinute},
+			setupMock:      func(mock redismock.ClientMock) {},
+			expectedStatus: fiber.StatusOK,
+			expectCalled:   true,
+		},
+		{
+			name:   "Redis errors let the request through",
+			limits: &config.RouteRateLimit{IP: perMinute},
+			setupMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{"ratelimit:refresh:ip:0.0.0.0"},
+					testRateLimitMember, 5, int64(60000), true).
+					SetErr(errors.New("connection refused"))
+			},
+			expectedStatus: fiber.StatusOK,
+			expectCalled:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.setupMock(mock)
+
+			limiter := newRateLimiter(&database.RedisDatabase{Client: client}, "refresh", tt.limits)
+			limiter.member = func() string { return testRateLimitMember }
+
+			called := false
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+			app.Post("/auth/refresh",
+				func(c *fiber.Ctx) error {
+					if tt.user != nil {
+						c.Locals("user", *tt.user)
+					}
+					return c.Next()
+				},
+				limiter.handle,
+				func(c *fiber.Ctx) error {
+					called = true
+					if tt.handlerErr != nil {
+						return tt.handlerErr
+					}
+					return c.SendStatus(fiber.StatusOK)
+				},
+			)
+
+			req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectCalled, called)
+			for header, value := range tt.expectedHeaders {
+				assert.Equal(t, value, resp.Header.Get(header), header)
+			}
+			if tt.expectedStatus == fiber.StatusTooManyRequests {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), fmt.Sprintf(`"code":%d`, response.ErrCodeRateLimitExceeded))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	app := fiber.New()
+	app.Post("/auth/verify-pin", RateLimitMiddleware("verify-pin"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/auth/verify-pin", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(RateLimitLimitHeader))
+}
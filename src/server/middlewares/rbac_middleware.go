@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole only lets users holding at least one of roles reach the route.
+// Place it after AuthMiddleware.
+func RequireRole(roles ...string) fiber.Handler {
+	return requireClaims(func(claims entities.Claims) bool {
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RequireScope only lets users whose roles grant every one of scopes reach
+// the route. Place it after AuthMiddleware.
+func RequireScope(scopes ...string) fiber.Handler {
+	return requireClaims(func(claims entities.Claims) bool {
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func requireClaims(allowed func(entities.Claims) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(entities.Claims)
+		if !ok {
+			c.Locals("status", fiber.StatusUnauthorized)
+			return exception.ErrUnauthorized
+		}
+
+		if !allowed(claims) {
+			logger.Warnf("Blocked user %s without the required role on %s %s", claims.UserID, c.Method(), c.Path())
+			c.Locals("status", fiber.StatusForbidden)
+			return exception.ErrInsufficientPermissions
+		}
+
+		return c.Next()
+	}
+}
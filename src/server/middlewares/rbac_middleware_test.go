@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRequireRoleAndScope(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	support := &entities.Claims{
+		UserID: "user123",
+		Roles:  []string{entities.RoleSupport},
+		Scopes: []string{entities.ScopeUsersRead, entities.ScopeUsersUnlock},
+	}
+
+	tests := []struct {
+		name           string
+		claims         *entities.Claims
+		guard          fiber.Handler
+		expectedStatus int
+	}{
+		{
+			name:           "one of the roles",
+			claims:         support,
+			guard:          RequireRole(entities.RoleAdmin, entities.RoleSupport),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "missing role",
+			claims:         support,
+			guard:          RequireRole(entities.RoleAdmin),
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "all scopes granted",
+			claims:         support,
+			guard:          RequireScope(entities.ScopeUsersRead, entities.ScopeUsersUnlock),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "one scope missing",
+			claims:         support,
+			guard:          RequireScope(entities.ScopeUsersRead, entities.ScopeTokensBan),
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "user without roles",
+			claims:         &entities.Claims{UserID: "user456"},
+			guard:          RequireScope(entities.ScopeUsersRead),
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "not authenticated",
+			guard:          RequireRole(entities.RoleAdmin),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+			app.Get("/admin",
+				func(c *fiber.Ctx) error {
+					if tt.claims != nil {
+						c.Locals("user", *tt.claims)
+					}
+					return c.Next()
+				},
+				tt.guard,
+				func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) },
+			)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
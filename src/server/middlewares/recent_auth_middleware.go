@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRecentAuth only lets sessions whose PIN was entered within maxAge
+// reach the route. Older sessions get ErrReauthRequired so the client can
+// prompt for the PIN and call POST /auth/reauth. Place it after
+// AuthMiddleware.
+func RequireRecentAuth(maxAge time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(entities.Claims)
+		if !ok {
+			c.Locals("status", fiber.StatusUnauthorized)
+			return exception.ErrUnauthorized
+		}
+
+		if !isRecentAuth(claims, maxAge, time.Now()) {
+			logger.Infof("Blocked token %s with stale auth time on %s", claims.TokenID, c.Path())
+			c.Locals("status", fiber.StatusUnauthorized)
+			return exception.ErrReauthRequired
+		}
+
+		return c.Next()
+	}
+}
+
+// isRecentAuth treats tokens without an auth time as stale.
+func isRecentAuth(claims entities.Claims, maxAge time.Duration, now time.Time) bool {
+	if claims.AuthTime == 0 {
+		return false
+	}
+	return now.Sub(time.Unix(claims.AuthTime, 0)) <= maxAge
+}
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRequireRecentAuth(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+
+	tests := []struct {
+		name           string
+		claims         *entities.Claims
+		expectedStatus int
+	}{
+		{
+			name:           "pin entered recently",
+			claims:         &entities.Claims{UserID: "user123", AuthTime: time.Now().Add(-time.Minute).Unix()},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "pin entered too long ago",
+			claims:         &entities.Claims{UserID: "user123", AuthTime: time.Now().Add(-10 * time.Minute).Unix()},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "token without auth time",
+			claims:         &entities.Claims{UserID: "user123"},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "not authenticated",
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+			app.Post("/transfers",
+				func(c *fiber.Ctx) error {
+					if tt.claims != nil {
+						c.Locals("user", *tt.claims)
+					}
+					return c.Next()
+				},
+				RequireRecentAuth(5*time.Minute),
+				func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) },
+			)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/transfers", nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ServiceAuthMiddleware lets internal services through that send a client ID
+// and secret from Auth.Introspection.Clients with HTTP Basic auth. The client
+// ID is stored in c.Locals("client").
+func ServiceAuthMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return serviceAuth(config.GetConfig().Auth.Introspection)(c)
+	}
+}
+
+func serviceAuth(cfg *config.IntrospectionConfig) fiber.Handler {
+	var clients map[string]string
+	if cfg != nil {
+		clients = cfg.Clients
+	}
+
+	return func(c *fiber.Ctx) error {
+		clientID, ok := checkClientCredentials(c.Get(fiber.HeaderAuthorization), clients)
+		if !ok {
+			logger.Warnf("Rejected service credentials on %s from %s", c.Path(), c.IP())
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="banking-api"`)
+			c.Locals("status", fiber.StatusUnauthorized)
+			return exception.ErrInvalidClient
+		}
+
+		c.Locals("client", clientID)
+		return c.Next()
+	}
+}
+
+// checkClientCredentials returns the client ID of a valid Basic authorization
+// header.
+func checkClientCredentials(header string, clients map[string]string) (string, bool) {
+	encoded, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	clientID, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", false
+	}
+
+	clientID = strings.ToLower(clientID)
+	expected, known := clients[clientID]
+	if !known || expected == "" {
+		return "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(expected)) != 1 {
+		return "", false
+	}
+	return clientID, true
+}
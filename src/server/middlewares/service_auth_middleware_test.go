@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Testzyler/banking-api/config"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func basicAuth(clientID, secret string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(clientID+":"+secret))
+}
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	logger.Logger = zap.NewNop().Sugar()
+	cfg := &config.IntrospectionConfig{Clients: map[string]string{"ledger": "s3cret"}}
+
+	tests := []struct {
+		name           string
+		cfg            *config.IntrospectionConfig
+		authorization  string
+		expectedStatus int
+		expectedClient string
+	}{
+		{
+			name:           "valid credentials",
+			cfg:            cfg,
+			authorization:  basicAuth("ledger", "s3cret"),
+			expectedStatus: fiber.StatusOK,
+			expectedClient: "ledger",
+		},
+		{
+			name:           "client id is case insensitive",
+			cfg:            cfg,
+			authorization:  basicAuth("Ledger", "s3cret"),
+			expectedStatus: fiber.StatusOK,
+			expectedClient: "ledger",
+		},
+		{
+			name:           "wrong secret",
+			cfg:            cfg,
+			authorization:  basicAuth("ledger", "guess"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "unknown client",
+			cfg:            cfg,
+			authorization:  basicAuth("other", "s3cret"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "bearer token instead of credentials",
+			cfg:            cfg,
+			authorization:  "Bearer token",
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "no clients configured",
+			authorization:  basicAuth("ledger", "s3cret"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+			app.Post("/auth/introspect", serviceAuth(tt.cfg), func(c *fiber.Ctx) error {
+				return c.SendString(c.Locals("client").(string))
+			})
+
+			req := httptest.NewRequest("POST", "/auth/introspect", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedClient != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.expectedClient, string(body))
+			} else {
+				assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
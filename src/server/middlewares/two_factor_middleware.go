@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"errors"
+
+	"github.com/Testzyler/banking-api/app/entities"
+	authRepository "github.com/Testzyler/banking-api/app/features/auth/repository"
+	"github.com/Testzyler/banking-api/app/models"
+	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
+	"github.com/Testzyler/banking-api/server/exception"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TotpEnrollments looks up the TOTP enrollment of a user. The auth
+// repository implements it.
+type TotpEnrollments interface {
+	GetUserTotp(userID string) (*models.UserTotp, error)
+}
+
+// RequireTwoFactor only lets sessions that passed the TOTP second factor
+// reach the route when the user has a confirmed enrollment. Users who never
+// enrolled, or whose enrollment is still pending, pass with their PIN
+// session. Place it after AuthMiddleware.
+func RequireTwoFactor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authRepo := authRepository.NewAuthRepository(database.GetDatabase().GetDB(), database.GetCache())
+		return RequireTwoFactorFor(authRepo)(c)
+	}
+}
+
+// RequireTwoFactorFor is RequireTwoFactor with the enrollments looked up in
+// totps.
+func RequireTwoFactorFor(totps TotpEnrollments) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(entities.Claims)
+		if !ok {
+			c.Locals("status", fiber.StatusUnauthorized)
+			return exception.ErrUnauthorized
+		}
+
+		if claims.TwoFactor {
+			return c.Next()
+		}
+
+		enrolled, err := hasConfirmedTotp(totps, claims.UserID)
+		if err != nil {
+			logger.Errorf("Failed to get TOTP enrollment for user %s: %v", claims.UserID, err)
+			c.Locals("status", fiber.StatusServiceUnavailable)
+			return exception.ErrServiceUnavailable
+		}
+		if enrolled {
+			logger.Infof("Blocked token %s without second factor on %s", claims.TokenID, c.Path())
+			c.Locals("status", fiber.StatusForbidden)
+			return exception.ErrTwoFactorRequired
+		}
+
+		return c.Next()
+	}
+}
+
+func hasConfirmedTotp(totps TotpEnrollments, userID string) (bool, error) {
+	totp, err := totps.GetUserTotp(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return totp.ConfirmedAt != nil, nil
+}
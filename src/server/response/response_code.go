@@ -17,8 +17,53 @@ var (
 	ErrCodeBadRequest       = newResponseCode(400)
 	ErrCodeUnauthorized     = newResponseCode(401)
 	ErrCodeForbidden        = newResponseCode(403)
+	ErrCodeConflict         = newResponseCode(409)
 	ErrCodeValidationFailed = newResponseCode(422)
 
+	// Transfer error codes
+	ErrCodeInsufficientFunds   = newResponseCode(801)
+	ErrCodeCurrencyMismatch    = newResponseCode(802)
+	ErrCodeSameAccountTransfer = newResponseCode(803)
+
+	// Idempotency error codes
+	ErrCodeIdempotencyKeyReused = newResponseCode(810)
+
+	// Card error codes
+	ErrCodeInvalidCardTransition = newResponseCode(820)
+
+	// PIN change error codes
+	ErrCodeWeakPin   = newResponseCode(830)
+	ErrCodePinReused = newResponseCode(831)
+
+	// Restricted token error codes
+	ErrCodePinChangeRequired = newResponseCode(832)
+
+	// PIN reset error codes
+	ErrCodeInvalidResetCode = newResponseCode(833)
+
+	// Two-factor error codes
+	ErrCodeTwoFactorRequired        = newResponseCode(835)
+	ErrCodeInvalidTwoFactorCode     = newResponseCode(836)
+	ErrCodeTwoFactorAlreadyEnabled  = newResponseCode(837)
+	ErrCodeTwoFactorNotEnrolled     = newResponseCode(838)
+	ErrCodeTooManyTwoFactorAttempts = newResponseCode(839)
+
+	// Step-up error codes
+	ErrCodeReauthRequired = newResponseCode(840)
+
+	// Session error codes
+	ErrCodeRefreshTokenReused = newResponseCode(841)
+	ErrCodeInvalidClient      = newResponseCode(842)
+
+	// Device error codes
+	ErrCodeDeviceKeyMismatch = newResponseCode(843)
+
+	// Authorization error codes
+	ErrCodeInsufficientPermissions = newResponseCode(844)
+
+	// Rate limit error codes
+	ErrCodeRateLimitExceeded = newResponseCode(845)
+
 	// >5xx Server Error codes
 	ErrCodeInternalServer     = newResponseCode(500)
 	ErrCodeServiceUnavailable = newResponseCode(503)
@@ -35,8 +80,49 @@ var CustomResponseMessages = map[ResponseCode]string{
 	ErrCodeBadRequest:       "Bad Request",
 	ErrCodeUnauthorized:     "Unauthorized",
 	ErrCodeForbidden:        "Forbidden",
+	ErrCodeConflict:         "Conflict",
 	ErrCodeValidationFailed: "Validation Failed",
 
+	// Transfer error codes
+	ErrCodeInsufficientFunds:   "Insufficient Funds",
+	ErrCodeCurrencyMismatch:    "Currency Mismatch",
+	ErrCodeSameAccountTransfer: "Same Account Transfer",
+
+	// Idempotency error codes
+	ErrCodeIdempotencyKeyReused: "Idempotency Key Reused",
+
+	// Card error codes
+	ErrCodeInvalidCardTransition: "Invalid Card Transition",
+
+	// PIN change error codes
+	ErrCodeWeakPin:   "Weak PIN",
+	ErrCodePinReused: "PIN Reused",
+
+	// Restricted token error codes
+	ErrCodePinChangeRequired: "PIN Change Required",
+
+	// PIN reset error codes
+	ErrCodeInvalidResetCode: "Invalid Reset Code",
+
+	// Two-factor error codes
+	ErrCodeTwoFactorRequired:        "Two-Factor Required",
+	ErrCodeInvalidTwoFactorCode:     "Invalid Two-Factor Code",
+	ErrCodeTwoFactorAlreadyEnabled:  "Two-Factor Already Enabled",
+	ErrCodeTwoFactorNotEnrolled:     "Two-Factor Not Enrolled",
+	ErrCodeTooManyTwoFactorAttempts: "Too Many Two-Factor Attempts",
+
+	// Step-up error codes
+	ErrCodeReauthRequired: "Reauthentication Required",
+
+	// Session error codes
+	ErrCodeRefreshTokenReused:      "Refresh Token Reused",
+	ErrCodeInvalidClient:           "Invalid Client Credentials",
+	ErrCodeDeviceKeyMismatch:       "Device Key Mismatch",
+	ErrCodeInsufficientPermissions: "Insufficient Permissions",
+
+	// Rate limit error codes
+	ErrCodeRateLimitExceeded: "Rate Limit Exceeded",
+
 	// Server Error codes
 	ErrCodeInternalServer:     "Internal Server Error",
 	ErrCodeServiceUnavailable: "Service Unavailable",
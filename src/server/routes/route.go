@@ -1,37 +1,120 @@
 package routes
 
 import (
+	accountHandler "github.com/Testzyler/banking-api/app/features/account/handler"
+	accountRepository "github.com/Testzyler/banking-api/app/features/account/repository"
+	accountService "github.com/Testzyler/banking-api/app/features/account/service"
+
+	adminHandler "github.com/Testzyler/banking-api/app/features/admin/handler"
+	adminRepository "github.com/Testzyler/banking-api/app/features/admin/repository"
+	adminService "github.com/Testzyler/banking-api/app/features/admin/service"
+
 	authHandler "github.com/Testzyler/banking-api/app/features/auth/handler"
 	authRepository "github.com/Testzyler/banking-api/app/features/auth/repository"
 	authService "github.com/Testzyler/banking-api/app/features/auth/service"
 
+	cardHandler "github.com/Testzyler/banking-api/app/features/card/handler"
+	cardRepository "github.com/Testzyler/banking-api/app/features/card/repository"
+	cardService "github.com/Testzyler/banking-api/app/features/card/service"
+
+	fxRepository "github.com/Testzyler/banking-api/app/features/fx/repository"
+	fxService "github.com/Testzyler/banking-api/app/features/fx/service"
+
 	homeHandler "github.com/Testzyler/banking-api/app/features/home/handler"
 	homeRepository "github.com/Testzyler/banking-api/app/features/home/repository"
 	homeService "github.com/Testzyler/banking-api/app/features/home/service"
+
+	transactionHandler "github.com/Testzyler/banking-api/app/features/transaction/handler"
+	transactionRepository "github.com/Testzyler/banking-api/app/features/transaction/repository"
+	transactionService "github.com/Testzyler/banking-api/app/features/transaction/service"
+
+	"github.com/Testzyler/banking-api/app/audit"
+	transferHandler "github.com/Testzyler/banking-api/app/features/transfer/handler"
+	transferRepository "github.com/Testzyler/banking-api/app/features/transfer/repository"
+	transferService "github.com/Testzyler/banking-api/app/features/transfer/service"
+	"github.com/Testzyler/banking-api/app/notifier"
 	"github.com/Testzyler/banking-api/config"
 
 	"github.com/Testzyler/banking-api/database"
+	"github.com/Testzyler/banking-api/logger"
 	"github.com/gofiber/fiber/v2"
 )
 
 func InitHandlers(api fiber.Router, db database.DatabaseInterface, redisDB *database.RedisDatabase) {
+	// Register Auth handler
+	authRepo := authRepository.NewAuthRepository(database.GetDatabase().GetDB(), database.GetCache())
+	jwtService := authService.NewJwtService(config.GetConfig(), authRepo)
+	userNotifier, err := notifier.New(config.GetConfig().Notifier)
+	if err != nil {
+		logger.Fatal("Failed to set up notifier", "error", err)
+	}
+	authSvc := authService.NewAuthService(
+		authRepo,
+		jwtService,
+		userNotifier,
+		config.GetConfig(),
+	)
+	authHandler.NewAuthHandler(
+		api,
+		authSvc,
+		authService.NewPinResetService(authRepo, userNotifier, config.GetConfig()),
+		authService.NewTwoFactorService(authRepo, jwtService, authSvc, config.GetConfig()),
+	)
+
+	// Register Admin handler, operators act on users through the auth repository
+	adminHandler.NewAdminHandler(
+		api,
+		adminService.NewAdminService(
+			adminRepository.NewAdminRepository(database.GetDatabase().GetDB()),
+			authRepo,
+			audit.Default(),
+		),
+	)
+
 	// Register Home handler with AuthMiddleware protection
+	fxRepo := fxRepository.NewFxRepository(database.GetDatabase().GetDB())
 	homeHandler.NewHomeHandler(
 		api,
 		homeService.NewHomeService(
 			homeRepository.NewHomeRepository(database.GetDatabase().GetDB()),
+			fxService.NewFxService(fxRepo, fxService.NewStoredRateProvider(fxRepo)),
+			config.GetConfig(),
 		),
 	)
 
-	// Register Auth handler
-	authRepo := authRepository.NewAuthRepository(database.GetDatabase().GetDB(), database.GetCache())
-	jwtService := authService.NewJwtService(config.GetConfig(), authRepo)
-	authHandler.NewAuthHandler(
+	// Register Transaction handler
+	transactionHandler.NewTransactionHandler(
 		api,
-		authService.NewAuthService(
-			authRepo,
-			jwtService,
+		transactionService.NewTransactionService(
+			transactionRepository.NewTransactionRepository(database.GetDatabase().GetDB()),
 			config.GetConfig(),
 		),
 	)
+
+	// Register Account handler, reveal re-checks the PIN through the auth service
+	accountHandler.NewAccountHandler(
+		api,
+		accountService.NewAccountService(
+			accountRepository.NewAccountRepository(database.GetDatabase().GetDB()),
+			authSvc,
+			config.GetConfig(),
+		),
+	)
+
+	// Register Transfer handler
+	transferHandler.NewTransferHandler(
+		api,
+		transferService.NewTransferService(
+			transferRepository.NewTransferRepository(database.GetDatabase().GetDB()),
+		),
+	)
+
+	// Register Card handler, status changes re-check the PIN through the auth service
+	cardHandler.NewCardHandler(
+		api,
+		cardService.NewCardService(
+			cardRepository.NewCardRepository(database.GetDatabase().GetDB()),
+			authSvc,
+		),
+	)
 }
@@ -4,6 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/Testzyler/banking-api/app/audit"
+	"github.com/Testzyler/banking-api/app/encryption"
+	authHandler "github.com/Testzyler/banking-api/app/features/auth/handler"
+	"github.com/Testzyler/banking-api/app/signing"
 	"github.com/Testzyler/banking-api/config"
 	"github.com/Testzyler/banking-api/database"
 	"github.com/Testzyler/banking-api/logger"
@@ -46,6 +50,17 @@ func NewServer(ctx context.Context, config *config.Config) *Server {
 
 	db := database.GetDatabase()
 	cache := database.GetCache()
+
+	if _, err := encryption.Setup(db.GetDB(), config.Encryption, config.Server.Environment); err != nil {
+		logger.Fatal("Failed to set up encryption", "error", err)
+	}
+
+	if _, err := signing.Setup(config.Auth.Jwt, config.Server.Environment); err != nil {
+		logger.Fatal("Failed to load JWT signing keys", "error", err)
+	}
+
+	audit.Setup(db.GetDB())
+
 	server := &Server{
 		App:            app,
 		Config:         config,
@@ -68,6 +83,9 @@ func (s *Server) setupMiddleware() {
 	// Logger middleware
 	s.App.Use(middlewares.LoggerMiddleware())
 
+	// Request details for the audit log
+	s.App.Use(middlewares.AuditRequestMiddleware())
+
 	// Recovery middleware
 	s.App.Use(recover.New())
 
@@ -97,6 +115,9 @@ func (s *Server) setupRoutes() {
 			Data:    healthData,
 		})
 	})
+	// Public keys for verifying access tokens
+	authHandler.NewJwksHandler(s.App, signing.Default())
+
 	// API routes
 	api := s.App.Group("/api/v1")
 